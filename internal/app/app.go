@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/morgarakt/aurify/internal/config"
+	"github.com/morgarakt/aurify/internal/models"
 	"github.com/morgarakt/aurify/internal/repository"
 	"github.com/morgarakt/aurify/internal/router"
 	"github.com/morgarakt/aurify/internal/services"
@@ -23,6 +24,7 @@ type Application struct {
 	db             *gorm.DB
 	repository     *repository.Repository
 	rabbitmqClient *services.RabbitMQClient
+	services       *services.Services
 	router         *router.Router
 	server         *http.Server
 }
@@ -47,6 +49,11 @@ func NewApplication() (*Application, error) {
 	}
 
 	app.initializeRepositories()
+	if err = app.initializeServices(); err != nil {
+		app.rabbitmqClient.Close()
+		_ = utils.CloseDB(app.db)
+		return nil, fmt.Errorf("services error: %w", err)
+	}
 	app.initializeRouter()
 
 	return app, nil
@@ -99,8 +106,34 @@ func (a *Application) initializeRepositories() {
 	log.Println("Repositories initialized.")
 }
 
+func (a *Application) initializeServices() error {
+	svc, err := services.NewServices(a.repository, a.cfg)
+	if err != nil {
+		return err
+	}
+	a.services = svc
+	log.Println("Services initialized.")
+	a.bootstrapAdmins()
+	return nil
+}
+
+// bootstrapAdmins ADMIN_EMAILS listesindeki mevcut hesapları admin rolüne yükseltir.
+func (a *Application) bootstrapAdmins() {
+	if len(a.cfg.AdminEmails) == 0 {
+		return
+	}
+	promoted, err := a.repository.User.PromoteByEmails(a.cfg.AdminEmails, models.RoleAdmin)
+	if err != nil {
+		log.Printf("Warning: could not promote ADMIN_EMAILS accounts: %v", err)
+		return
+	}
+	if promoted > 0 {
+		log.Printf("Promoted %d account(s) from ADMIN_EMAILS to admin.", promoted)
+	}
+}
+
 func (a *Application) initializeRouter() {
-	a.router = router.NewRouter(a.repository, a.cfg, a.rabbitmqClient)
+	a.router = router.NewRouter(a.repository, a.cfg, a.rabbitmqClient, a.services)
 	a.server = &http.Server{
 		Addr:    fmt.Sprintf(":%s", a.cfg.Port),
 		Handler: a.router.Engine(),
@@ -113,6 +146,8 @@ func (a *Application) Run() error {
 		os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 	defer stop()
 
+	a.services.StartBackgroundJobs(ctx, a.cfg)
+
 	go func() {
 		log.Printf("Server starting on port %s", a.cfg.Port)
 		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv" // String'den int'e çevrim için eklendi
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -20,6 +23,94 @@ type Config struct {
 	JWTSecret   string `mapstructure:"JWT_SECRET"`
 	RabbitMQURL string `mapstructure:"RABBITMQ_URL"`
 
+	// Worker'ın ürettiği dosyaların (mp3, midi, wav, kapak) bulunduğu dizin
+	GeneratedDir string `mapstructure:"GENERATED_DIR"`
+
+	// Sahipsiz dosya temizliği (artifact GC)
+	ArtifactGCInterval    time.Duration `mapstructure:"ARTIFACT_GC_INTERVAL"`
+	ArtifactGCGracePeriod time.Duration `mapstructure:"ARTIFACT_GC_GRACE_PERIOD"`
+	ArtifactGCDryRun      bool          `mapstructure:"ARTIFACT_GC_DRY_RUN"`
+
+	// Silinen müziklerin geri alınabileceği süre ve kalıcı silme kontrol aralığı
+	MusicDeleteRetention time.Duration `mapstructure:"MUSIC_DELETE_RETENTION"`
+	MusicPurgeInterval   time.Duration `mapstructure:"MUSIC_PURGE_INTERVAL"`
+
+	// Giriş yapmadan üretilen müziklerin, kayıt/giriş sonrası sahiplenilebileceği süre
+	AnonSessionTTL time.Duration `mapstructure:"ANON_SESSION_TTL"`
+
+	// Oturum doğrulama önbelleği (iptaller en geç bu süre içinde diğer instance'lara yansır) ve süresi dolmuş oturumların temizlenme aralığı
+	SessionCacheTTL      time.Duration `mapstructure:"SESSION_CACHE_TTL"`
+	SessionPruneInterval time.Duration `mapstructure:"SESSION_PRUNE_INTERVAL"`
+
+	// Kısa ömürlü access token ve döndürülen (rotating) refresh token süreleri
+	AccessTokenTTL  time.Duration `mapstructure:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `mapstructure:"REFRESH_TOKEN_TTL"`
+
+	// Giriş yapmış kullanıcının 24 saatte yapabileceği başarılı üretim sayısı (0 = sınırsız)
+	DailyGenerationQuota int `mapstructure:"DAILY_GENERATION_QUOTA"`
+
+	// Bu e-posta adreslerine sahip hesaplar, adres doğrulanmışsa açılışta ya da doğrulandığı anda admin yapılır
+	AdminEmails []string `mapstructure:"ADMIN_EMAILS"`
+
+	// E-postalardaki linkler için uygulamanın dışarıdan erişilen adresi (örn. https://aurify.app)
+	AppBaseURL string `mapstructure:"APP_BASE_URL"`
+
+	// X-Forwarded-For başlığına güvenilecek reverse proxy adresleri/CIDR'ları; boşsa başlık yok sayılır ve bağlantı adresi kullanılır
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
+
+	// E-posta gönderimi: "smtp", "file" (MailDir'e .eml yazar) veya "log" (sadece loglar)
+	MailDriver   string `mapstructure:"MAIL_DRIVER"`
+	MailFrom     string `mapstructure:"MAIL_FROM"`
+	MailDir      string `mapstructure:"MAIL_DIR"`
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     string `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+
+	// Şifre sıfırlama linkinin geçerlilik süresi ve e-posta başına saatlik istek sınırı
+	PasswordResetTTL       time.Duration `mapstructure:"PASSWORD_RESET_TTL"`
+	PasswordResetRateLimit int           `mapstructure:"PASSWORD_RESET_RATE_LIMIT"`
+
+	// E-posta doğrulama linkinin geçerlilik süresi, kullanıcı başına saatlik yeniden gönderim sınırı
+	// ve doğrulanmamış hesapların 24 saatlik üretim kotası
+	EmailVerificationTTL         time.Duration `mapstructure:"EMAIL_VERIFICATION_TTL"`
+	EmailVerificationResendLimit int           `mapstructure:"EMAIL_VERIFICATION_RESEND_LIMIT"`
+	UnverifiedGenerationQuota    int           `mapstructure:"UNVERIFIED_GENERATION_QUOTA"`
+
+	// İki adımlı doğrulama: TOTP secret'larını şifreleyen anahtar (boşsa JWT_SECRET'tan türetilir),
+	// authenticator uygulamasında görünen isim ve şifre sonrası kod girmek için tanınan süre
+	TOTPEncryptionKey     string        `mapstructure:"TOTP_ENCRYPTION_KEY"`
+	TOTPIssuer            string        `mapstructure:"TOTP_ISSUER"`
+	TwoFactorChallengeTTL time.Duration `mapstructure:"TWO_FACTOR_CHALLENGE_TTL"`
+
+	// Kaba kuvvet koruması: pencere içinde bu kadar hatalı şifreden sonra hesap LoginLockoutDuration
+	// boyunca kilitlenir; aynı IP'den gelen hatalı denemeler de ayrıca sınırlanır
+	LoginMaxFailedAttempts   int           `mapstructure:"LOGIN_MAX_FAILED_ATTEMPTS"`
+	LoginFailureWindow       time.Duration `mapstructure:"LOGIN_FAILURE_WINDOW"`
+	LoginLockoutDuration     time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	LoginIPMaxFailedAttempts int           `mapstructure:"LOGIN_IP_MAX_FAILED_ATTEMPTS"`
+
+	// Veri dışa aktarma arşivleri herkese açık GENERATED_DIR dışında tutulur; DataExportTTL sonunda silinir.
+	// Kullanıcı DataExportCooldown içinde yalnızca bir arşiv isteyebilir.
+	DataExportDir      string        `mapstructure:"DATA_EXPORT_DIR"`
+	DataExportTTL      time.Duration `mapstructure:"DATA_EXPORT_TTL"`
+	DataExportCooldown time.Duration `mapstructure:"DATA_EXPORT_COOLDOWN"`
+
+	// Yüklenebilecek profil fotoğrafının en büyük boyutu (bayt); fotoğraf kırpılıp küçültülerek saklanır
+	AvatarMaxBytes int `mapstructure:"AVATAR_MAX_BYTES"`
+
+	// Kullanıcı başına 10 dakikada yazılabilecek yorum sayısı (0 sınırı kapatır)
+	CommentRateLimit int `mapstructure:"COMMENT_RATE_LIMIT"`
+
+	// OpenID Connect ile giriş (OIDCIssuer boşsa kapalıdır). Issuer'ın discovery dokümanı
+	// (/.well-known/openid-configuration) açılışta değil ilk girişte okunur.
+	OIDCIssuer       string   `mapstructure:"OIDC_ISSUER"`
+	OIDCClientID     string   `mapstructure:"OIDC_CLIENT_ID"`
+	OIDCClientSecret string   `mapstructure:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL  string   `mapstructure:"OIDC_REDIRECT_URL"`
+	OIDCScopes       []string `mapstructure:"OIDC_SCOPES"`
+	OIDCProviderName string   `mapstructure:"OIDC_PROVIDER_NAME"`
+
 	// Sayfalama için yeni config alanları
 	DefaultPerPage int `mapstructure:"DEFAULT_PER_PAGE"`
 	MinPerPage     int `mapstructure:"MIN_PER_PAGE"`
@@ -49,6 +140,45 @@ func getEnvAsInt(key string, fallback int) int {
 	return value
 }
 
+// Helper function to get environment variable as duration (örn. "6h", "30m") or default
+func getEnvAsDuration(key string, fallback time.Duration) time.Duration {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return fallback
+	}
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		log.Printf("Warning: Invalid duration value for %s: %s. Using fallback: %s", key, valueStr, fallback)
+		return fallback
+	}
+	return value
+}
+
+// Helper function to get environment variable as bool or default
+func getEnvAsBool(key string, fallback bool) bool {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return fallback
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		log.Printf("Warning: Invalid boolean value for %s: %s. Using fallback: %t", key, valueStr, fallback)
+		return fallback
+	}
+	return value
+}
+
+// Helper function to get a comma separated environment variable as a list (boş öğeler atlanır)
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
 func LoadConfig() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		// .env dosyası bulunamazsa hata vermek yerine uyarı verip devam edebilir.
@@ -72,6 +202,70 @@ func LoadConfig() (*Config, error) {
 		JWTSecret:   getEnv("JWT_SECRET", ""),
 		RabbitMQURL: getEnv("RABBITMQ_URL", ""),
 
+		GeneratedDir: getEnv("GENERATED_DIR", "./generated"),
+
+		ArtifactGCInterval:    getEnvAsDuration("ARTIFACT_GC_INTERVAL", 6*time.Hour),
+		ArtifactGCGracePeriod: getEnvAsDuration("ARTIFACT_GC_GRACE_PERIOD", 24*time.Hour),
+		ArtifactGCDryRun:      getEnvAsBool("ARTIFACT_GC_DRY_RUN", false),
+
+		MusicDeleteRetention: getEnvAsDuration("MUSIC_DELETE_RETENTION", 30*24*time.Hour),
+		MusicPurgeInterval:   getEnvAsDuration("MUSIC_PURGE_INTERVAL", time.Hour),
+
+		AnonSessionTTL: getEnvAsDuration("ANON_SESSION_TTL", 30*24*time.Hour),
+
+		SessionCacheTTL:      getEnvAsDuration("SESSION_CACHE_TTL", 30*time.Second),
+		SessionPruneInterval: getEnvAsDuration("SESSION_PRUNE_INTERVAL", time.Hour),
+
+		AccessTokenTTL:  getEnvAsDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getEnvAsDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+
+		AdminEmails: getEnvAsList("ADMIN_EMAILS"),
+
+		DailyGenerationQuota: getEnvAsInt("DAILY_GENERATION_QUOTA", 50),
+
+		AppBaseURL: strings.TrimRight(getEnv("APP_BASE_URL", "http://localhost:8080"), "/"),
+
+		TrustedProxies: getEnvAsList("TRUSTED_PROXIES"),
+
+		MailDriver:   strings.ToLower(getEnv("MAIL_DRIVER", "log")),
+		MailFrom:     getEnv("MAIL_FROM", "Aurify <no-reply@localhost>"),
+		MailDir:      getEnv("MAIL_DIR", "./mail"),
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+
+		PasswordResetTTL:       getEnvAsDuration("PASSWORD_RESET_TTL", time.Hour),
+		PasswordResetRateLimit: getEnvAsInt("PASSWORD_RESET_RATE_LIMIT", 3),
+
+		EmailVerificationTTL:         getEnvAsDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		EmailVerificationResendLimit: getEnvAsInt("EMAIL_VERIFICATION_RESEND_LIMIT", 3),
+		UnverifiedGenerationQuota:    getEnvAsInt("UNVERIFIED_GENERATION_QUOTA", 3),
+
+		TOTPEncryptionKey:     getEnv("TOTP_ENCRYPTION_KEY", ""),
+		TOTPIssuer:            getEnv("TOTP_ISSUER", "Aurify"),
+		TwoFactorChallengeTTL: getEnvAsDuration("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute),
+
+		LoginMaxFailedAttempts:   getEnvAsInt("LOGIN_MAX_FAILED_ATTEMPTS", 5),
+		LoginFailureWindow:       getEnvAsDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		LoginLockoutDuration:     getEnvAsDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		LoginIPMaxFailedAttempts: getEnvAsInt("LOGIN_IP_MAX_FAILED_ATTEMPTS", 50),
+
+		AvatarMaxBytes: getEnvAsInt("AVATAR_MAX_BYTES", 2<<20),
+
+		CommentRateLimit: getEnvAsInt("COMMENT_RATE_LIMIT", 20),
+
+		DataExportDir:      getEnv("DATA_EXPORT_DIR", "./exports"),
+		DataExportTTL:      getEnvAsDuration("DATA_EXPORT_TTL", 7*24*time.Hour),
+		DataExportCooldown: getEnvAsDuration("DATA_EXPORT_COOLDOWN", 24*time.Hour),
+
+		OIDCIssuer:       strings.TrimRight(getEnv("OIDC_ISSUER", ""), "/"),
+		OIDCClientID:     getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:  getEnv("OIDC_REDIRECT_URL", ""),
+		OIDCScopes:       getEnvAsList("OIDC_SCOPES"),
+		OIDCProviderName: getEnv("OIDC_PROVIDER_NAME", "SSO"),
+
 		DefaultPerPage: getEnvAsInt("DEFAULT_PER_PAGE", defaultPerPageFallback),
 		MinPerPage:     getEnvAsInt("MIN_PER_PAGE", minPerPageFallback),
 		MaxPerPage:     getEnvAsInt("MAX_PER_PAGE", maxPerPageFallback),
@@ -97,6 +291,15 @@ func LoadConfig() (*Config, error) {
 	if config.RabbitMQURL == "" {
 		return nil, fmt.Errorf("missing required configuration variable: RABBITMQ_URL")
 	}
+	switch config.MailDriver {
+	case "smtp":
+		if config.SMTPHost == "" {
+			return nil, fmt.Errorf("missing required configuration variable: SMTP_HOST (MAIL_DRIVER=smtp)")
+		}
+	case "file", "log":
+	default:
+		return nil, fmt.Errorf("invalid MAIL_DRIVER %q: expected smtp, file or log", config.MailDriver)
+	}
 
 	// Sayfalama değerleri için ek validasyonlar
 	if config.MinPerPage <= 0 {
@@ -116,6 +319,63 @@ func LoadConfig() (*Config, error) {
 		config.MaxPerPage = maxPerPageFallback
 	}
 
+	// Yeni üretilen dosyalar henüz kaydedilmemiş olabilir; çok kısa bir bekleme süresi onları silebilir.
+	if config.ArtifactGCGracePeriod < time.Hour {
+		log.Printf("Warning: ARTIFACT_GC_GRACE_PERIOD (%s) is too short, setting to 1h.", config.ArtifactGCGracePeriod)
+		config.ArtifactGCGracePeriod = time.Hour
+	}
+
+	// Ayrı bir anahtar verilmezse JWT_SECRET kullanılır; bu durumda JWT_SECRET değişirse kayıtlı TOTP secret'ları açılamaz.
+	if config.TOTPEncryptionKey == "" {
+		log.Println("Warning: TOTP_ENCRYPTION_KEY is not set, deriving it from JWT_SECRET.")
+		config.TOTPEncryptionKey = config.JWTSecret + ":totp"
+	}
+
+	if config.LoginMaxFailedAttempts < 3 {
+		log.Printf("Warning: LOGIN_MAX_FAILED_ATTEMPTS (%d) is too low, setting to 3.", config.LoginMaxFailedAttempts)
+		config.LoginMaxFailedAttempts = 3
+	}
+	if config.LoginLockoutDuration < time.Minute {
+		log.Printf("Warning: LOGIN_LOCKOUT_DURATION (%s) is too short, setting to 1m.", config.LoginLockoutDuration)
+		config.LoginLockoutDuration = time.Minute
+	}
+	if isSubdir(config.GeneratedDir, config.DataExportDir) {
+		return nil, fmt.Errorf("DATA_EXPORT_DIR must not be inside GENERATED_DIR: export archives must not be publicly served")
+	}
+	if config.DataExportTTL < time.Hour {
+		log.Printf("Warning: DATA_EXPORT_TTL (%s) is too short, setting to 1h.", config.DataExportTTL)
+		config.DataExportTTL = time.Hour
+	}
+	if config.AvatarMaxBytes <= 0 {
+		log.Printf("Warning: AVATAR_MAX_BYTES (%d) is invalid, setting to 2MiB.", config.AvatarMaxBytes)
+		config.AvatarMaxBytes = 2 << 20
+	}
+
+	if config.OIDCEnabled() {
+		if config.OIDCClientID == "" {
+			return nil, fmt.Errorf("missing required configuration variable: OIDC_CLIENT_ID (OIDC_ISSUER is set)")
+		}
+		if config.OIDCRedirectURL == "" {
+			config.OIDCRedirectURL = config.AppBaseURL + "/auth/oidc/callback"
+		}
+		if len(config.OIDCScopes) == 0 {
+			config.OIDCScopes = []string{"openid", "email", "profile"}
+		}
+		// openid scope'u olmadan sağlayıcı ID token vermez
+		hasOpenID := false
+		for _, scope := range config.OIDCScopes {
+			hasOpenID = hasOpenID || scope == "openid"
+		}
+		if !hasOpenID {
+			config.OIDCScopes = append([]string{"openid"}, config.OIDCScopes...)
+		}
+	}
+
+	if config.RefreshTokenTTL < config.AccessTokenTTL {
+		log.Printf("Warning: REFRESH_TOKEN_TTL (%s) is shorter than ACCESS_TOKEN_TTL (%s). Setting REFRESH_TOKEN_TTL to ACCESS_TOKEN_TTL.", config.RefreshTokenTTL, config.AccessTokenTTL)
+		config.RefreshTokenTTL = config.AccessTokenTTL
+	}
+
 	log.Println("Configuration loaded successfully.")
 	return config, nil
 }
@@ -129,3 +389,41 @@ func (cfg *Config) DBConnectionStringWOName() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s sslmode=%s",
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPass, cfg.DBSSLMode)
 }
+
+// GenerationQuota kullanıcının 24 saatlik başarılı üretim kotasını döner (0 = sınırsız).
+// Doğrulanmamış hesaplar, genel kota sınırsız olsa bile UNVERIFIED_GENERATION_QUOTA ile sınırlanır.
+func (cfg *Config) GenerationQuota(emailVerified bool) int {
+	if emailVerified || cfg.UnverifiedGenerationQuota <= 0 {
+		return cfg.DailyGenerationQuota
+	}
+	if cfg.DailyGenerationQuota > 0 && cfg.DailyGenerationQuota < cfg.UnverifiedGenerationQuota {
+		return cfg.DailyGenerationQuota
+	}
+	return cfg.UnverifiedGenerationQuota
+}
+
+// OIDCEnabled OpenID Connect ile girişin yapılandırılıp yapılandırılmadığını bildirir.
+func (cfg *Config) OIDCEnabled() bool {
+	return cfg.OIDCIssuer != ""
+}
+
+// IsAdminEmail e-posta adresinin ADMIN_EMAILS listesinde olup olmadığını bildirir (büyük/küçük harf duyarsız).
+func (cfg *Config) IsAdminEmail(email string) bool {
+	for _, adminEmail := range cfg.AdminEmails {
+		if strings.EqualFold(adminEmail, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSubdir dir'in parent ile aynı dizin ya da onun altında olup olmadığını bildirir.
+func isSubdir(parent, dir string) bool {
+	parentAbs, err1 := filepath.Abs(parent)
+	dirAbs, err2 := filepath.Abs(dir)
+	if err1 != nil || err2 != nil {
+		return filepath.Clean(parent) == filepath.Clean(dir)
+	}
+	rel, err := filepath.Rel(parentAbs, dirAbs)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	middleware "github.com/morgarakt/aurify/internal/middlewares"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/services"
+)
+
+type CreateAccessTokenRequest struct {
+	Name   string   `json:"name" form:"name" binding:"required,max=64"`
+	Scopes []string `json:"scopes" form:"scopes"`
+	// Gün cinsinden geçerlilik süresi; 0 süresiz token demektir
+	ExpiresInDays int `json:"expires_in_days" form:"expires_in_days"`
+}
+
+// AccessTokens kişisel erişim token'larının listelendiği ve oluşturulduğu ayarlar sayfasıdır.
+func (h *SettingsHandler) AccessTokens(c *gin.Context) {
+	userID, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		c.Redirect(http.StatusSeeOther, "/login?redirect=/settings/tokens")
+		return
+	}
+	tokens, err := h.accessTokenRows(userID)
+	if err != nil {
+		log.Printf("Error listing access tokens for user %s: %v", userID, err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load your access tokens."})
+		return
+	}
+
+	expiryOptions := make([]gin.H, 0, len(services.AccessTokenExpiryOptions))
+	for _, ttl := range services.AccessTokenExpiryOptions {
+		days := int(ttl / (24 * time.Hour))
+		label := "No expiration"
+		if days > 0 {
+			label = fmt.Sprintf("%d days", days)
+		}
+		expiryOptions = append(expiryOptions, gin.H{"Days": days, "Label": label, "Default": days == 30})
+	}
+
+	c.HTML(http.StatusOK, "settings/tokens.html", gin.H{
+		"title":         "Access Tokens - Aurify",
+		"auth":          isAuthenticated,
+		"username":      username,
+		"Tokens":        tokens,
+		"Scopes":        models.AccessTokenScopes,
+		"ExpiryOptions": expiryOptions,
+	})
+}
+
+// ListAccessTokens kullanıcının token'larını JSON olarak döner (gizli değerler hiçbir zaman dönmez).
+func (h *SettingsHandler) ListAccessTokens(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+	tokens, err := h.accessTokenRows(userID)
+	if err != nil {
+		log.Printf("Error listing access tokens for user %s: %v", userID, err)
+		respondError(c, http.StatusInternalServerError, "Could not load your access tokens.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+// CreateAccessToken yeni bir token oluşturur ve gizli değerini bir kez gösterir.
+func (h *SettingsHandler) CreateAccessToken(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+	var req CreateAccessTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Please give the token a name (up to 64 characters).")
+		return
+	}
+	if !validTokenExpiry(req.ExpiresInDays) {
+		respondError(c, http.StatusBadRequest, "Please choose one of the offered expiration periods.")
+		return
+	}
+
+	secret, token, err := h.services.AccessTokens.Create(userID, req.Name, req.Scopes, time.Duration(req.ExpiresInDays)*24*time.Hour)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAccessTokenName):
+			respondError(c, http.StatusBadRequest, "Please give the token a name.")
+		case errors.Is(err, services.ErrAccessTokenScopes):
+			respondError(c, http.StatusBadRequest, "Select at least one scope.")
+		case errors.Is(err, services.ErrAccessTokenLimit):
+			respondError(c, http.StatusConflict, "You have too many active tokens. Revoke one you no longer use first.")
+		default:
+			log.Printf("Error creating access token for user %s: %v", userID, err)
+			respondError(c, http.StatusInternalServerError, "Could not create the token.")
+		}
+		return
+	}
+	h.services.Audit.Record(auditEvent(c, models.AuditAccessTokenCreate, "access_token", token.ID.String(),
+		gin.H{"name": token.Name, "scopes": token.ScopeList(), "expires_at": token.ExpiresAt}))
+
+	row := accessTokenRow(token, time.Now())
+	if !isHTMXRequest(c) {
+		row["token"] = secret
+		c.JSON(http.StatusCreated, row)
+		return
+	}
+	tokens, err := h.accessTokenRows(userID)
+	if err != nil {
+		log.Printf("Error listing access tokens for user %s: %v", userID, err)
+	}
+	notifySuccess(c, "Access token created.")
+	c.HTML(http.StatusOK, "partials/_access_token_created.html", gin.H{"Token": secret, "Name": token.Name, "Tokens": tokens, "OOB": true})
+}
+
+// RevokeAccessToken token'ı hemen geçersiz kılar.
+func (h *SettingsHandler) RevokeAccessToken(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+	tokenID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid token ID.")
+		return
+	}
+	if err := h.services.AccessTokens.Revoke(userID, tokenID); err != nil {
+		if errors.Is(err, services.ErrAccessTokenMissing) {
+			respondError(c, http.StatusNotFound, "Token not found.")
+			return
+		}
+		log.Printf("Error revoking access token %s for user %s: %v", tokenID, userID, err)
+		respondError(c, http.StatusInternalServerError, "Could not revoke the token.")
+		return
+	}
+	h.services.Audit.Record(auditEvent(c, models.AuditAccessTokenRevoke, "access_token", tokenID.String(), nil))
+
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"id": tokenID.String(), "revoked": true})
+		return
+	}
+	// Satır outerHTML ile boş içerikle değiştirilerek listeden kaldırılır.
+	notifySuccess(c, "Access token revoked.")
+	c.String(http.StatusOK, "")
+}
+
+func (h *SettingsHandler) accessTokenRows(userID uuid.UUID) ([]gin.H, error) {
+	tokens, err := h.services.AccessTokens.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	rows := make([]gin.H, 0, len(tokens))
+	for i := range tokens {
+		rows = append(rows, accessTokenRow(&tokens[i], now))
+	}
+	return rows, nil
+}
+
+func accessTokenRow(token *models.PersonalAccessToken, now time.Time) gin.H {
+	row := gin.H{
+		"id":         token.ID.String(),
+		"name":       token.Name,
+		"prefix":     token.Prefix,
+		"scopes":     token.ScopeList(),
+		"scope_text": strings.Join(token.ScopeList(), ", "),
+		"created_at": token.CreatedAt.Format("02 Jan 2006"),
+		"expires_at": "Never",
+		"last_used":  "Never used",
+		"expired":    token.IsExpired(now),
+	}
+	if token.ExpiresAt != nil {
+		row["expires_at"] = token.ExpiresAt.Format("02 Jan 2006")
+	}
+	if token.LastUsedAt != nil {
+		row["last_used"] = token.LastUsedAt.Format("02 Jan 2006 15:04")
+	}
+	return row
+}
+
+func validTokenExpiry(days int) bool {
+	for _, ttl := range services.AccessTokenExpiryOptions {
+		if int(ttl/(24*time.Hour)) == days {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	middleware "github.com/morgarakt/aurify/internal/middlewares"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/services"
+	"github.com/morgarakt/aurify/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type ChangeUsernameRequest struct {
+	Username string `json:"username" form:"username" binding:"required"`
+}
+
+type ChangeEmailRequest struct {
+	Email           string `json:"email" form:"email" binding:"required,email"`
+	CurrentPassword string `json:"current_password" form:"current_password" binding:"required"`
+}
+
+type UpdateProfileRequest struct {
+	Bio       string `json:"bio" form:"bio"`
+	ShowLikes bool   `json:"show_likes" form:"show_likes"`
+}
+
+// Account, profil, kullanıcı adı, e-posta ve şifre ayarlarının bulunduğu sayfadır.
+func (h *SettingsHandler) Account(c *gin.Context) {
+	userID, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		c.Redirect(http.StatusSeeOther, "/login?redirect=/settings")
+		return
+	}
+	var user models.User
+	if err := h.repo.User.GetByID(userID, &user); err != nil {
+		log.Printf("Error loading user %s for account settings: %v", userID, err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load your account."})
+		return
+	}
+
+	c.HTML(http.StatusOK, "settings/account.html", gin.H{
+		"title":          "Account Settings - Aurify",
+		"auth":           isAuthenticated,
+		"username":       username,
+		"Username":       user.Username,
+		"ProfileURL":     profileURL(user.Username),
+		"Initial":        strings.ToUpper(string([]rune(user.Username)[:1])),
+		"Email":          user.Email,
+		"EmailVerified":  user.IsEmailVerified(),
+		"Bio":            user.Bio,
+		"ShowLikes":      user.ShowLikes,
+		"AvatarURL":      h.services.Account.AvatarURL(&user),
+		"AvatarMaxBytes": h.services.Account.AvatarMaxBytes(),
+	})
+}
+
+// ChangeUsername kullanıcı adını değiştirir. Ad access token'da taşındığı için mevcut oturumun token'ı yenilenir;
+// diğer oturumlar yeni adı bir sonraki yenilemede görür.
+func (h *SettingsHandler) ChangeUsername(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	var req ChangeUsernameRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Please enter a username.")
+		return
+	}
+	username := strings.TrimSpace(req.Username)
+	if username == user.Username {
+		respondError(c, http.StatusBadRequest, "That is already your username.")
+		return
+	}
+
+	oldUsername := user.Username
+	if err := h.services.Account.ChangeUsername(user, username); err != nil {
+		status, message := usernameErrorResponse(err)
+		if status == http.StatusInternalServerError {
+			log.Printf("Error changing username of user %s: %v", user.ID, err)
+		}
+		respondError(c, status, message)
+		return
+	}
+	log.Printf("User %s changed username: %s -> %s", user.ID, oldUsername, user.Username)
+	event := auditEvent(c, models.AuditUsernameChange, "user", user.ID.String(),
+		services.AuditDiff(gin.H{"username": oldUsername}, gin.H{"username": user.Username}))
+	event.ActorName = user.Username
+	h.services.Audit.Record(event)
+
+	if _, err := utils.RefreshToken(c, h.cfg.JWTSecret); err != nil {
+		log.Printf("Refreshing token after username change for user %s failed: %v", user.ID, err)
+	}
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"username": user.Username, "message": "Username changed."})
+		return
+	}
+	c.Header("HX-Redirect", "/settings?success=username_changed")
+	c.Status(http.StatusOK)
+}
+
+// ChangeEmail mevcut şifreyi doğrulayıp e-posta adresini değiştirir. Yeni adres tekrar doğrulanana kadar
+// hesap doğrulanmamış hesap kısıtlarına tabidir; eski adrese bilgilendirme gönderilir.
+func (h *SettingsHandler) ChangeEmail(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	var req ChangeEmailRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Please enter a valid email address and your current password.")
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)); err != nil {
+		respondError(c, http.StatusForbidden, "Current password is incorrect.")
+		return
+	}
+
+	oldEmail := user.Email
+	if err := h.services.Account.ChangeEmail(user, strings.TrimSpace(req.Email)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmailUnchanged):
+			respondError(c, http.StatusBadRequest, "That is already your email address.")
+		case errors.Is(err, services.ErrEmailTaken):
+			respondError(c, http.StatusConflict, "This email address is already in use.")
+		default:
+			log.Printf("Error changing email of user %s: %v", user.ID, err)
+			respondError(c, http.StatusInternalServerError, "Could not change your email address.")
+		}
+		return
+	}
+	log.Printf("User %s changed email address", user.ID)
+	h.services.Audit.Record(auditEvent(c, models.AuditEmailChange, "user", user.ID.String(),
+		services.AuditDiff(gin.H{"email": oldEmail}, gin.H{"email": user.Email})))
+
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"email": user.Email, "message": "Email changed. Check your inbox to verify the new address."})
+		return
+	}
+	c.Header("HX-Redirect", "/settings?success=email_changed")
+	c.Status(http.StatusOK)
+}
+
+// UpdateProfile profil açıklamasını (bio) ve beğenilerin profilde gösterilip gösterilmeyeceğini kaydeder.
+func (h *SettingsHandler) UpdateProfile(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	var req UpdateProfileRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid profile data.")
+		return
+	}
+	oldBio, oldShowLikes := user.Bio, user.ShowLikes
+	if err := h.services.Account.UpdateProfile(user, req.Bio, req.ShowLikes); err != nil {
+		if errors.Is(err, services.ErrBioTooLong) {
+			respondError(c, http.StatusBadRequest, "Your bio can be at most 500 characters.")
+			return
+		}
+		log.Printf("Error updating profile of user %s: %v", user.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not save your profile.")
+		return
+	}
+	if user.Bio != oldBio || user.ShowLikes != oldShowLikes {
+		h.services.Audit.Record(auditEvent(c, models.AuditProfileUpdate, "user", user.ID.String(),
+			services.AuditDiff(gin.H{"bio": oldBio, "show_likes": oldShowLikes}, gin.H{"bio": user.Bio, "show_likes": user.ShowLikes})))
+	}
+
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"bio": user.Bio, "show_likes": user.ShowLikes, "message": "Profile saved."})
+		return
+	}
+	notifySuccess(c, "Profile saved.")
+	c.Status(http.StatusOK)
+}
+
+// UploadAvatar "avatar" alanındaki görüntüyü profil fotoğrafı yapar.
+func (h *SettingsHandler) UploadAvatar(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	maxBytes := h.services.Account.AvatarMaxBytes()
+	data, ok := readUploadedImage(c, "avatar", maxBytes, avatarTooLargeMessage(maxBytes))
+	if !ok {
+		return
+	}
+
+	if err := h.services.Account.SetAvatar(user, data); err != nil {
+		switch {
+		case errors.Is(err, services.ErrAvatarTooLarge):
+			respondError(c, http.StatusRequestEntityTooLarge, avatarTooLargeMessage(maxBytes))
+		case errors.Is(err, services.ErrAvatarInvalid):
+			respondError(c, http.StatusUnsupportedMediaType, "Avatars must be PNG, JPEG or GIF images up to 4096x4096 pixels.")
+		default:
+			log.Printf("Error saving avatar of user %s: %v", user.ID, err)
+			respondError(c, http.StatusInternalServerError, "Could not save your avatar.")
+		}
+		return
+	}
+	h.services.Audit.Record(auditEvent(c, models.AuditProfileUpdate, "user", user.ID.String(), gin.H{"avatar": "updated"}))
+
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"avatar_url": h.services.Account.AvatarURL(user)})
+		return
+	}
+	c.Header("HX-Redirect", "/settings?success=avatar_updated")
+	c.Status(http.StatusOK)
+}
+
+// DeleteAvatar profil fotoğrafını kaldırır.
+func (h *SettingsHandler) DeleteAvatar(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	hadAvatar := user.AvatarKey != ""
+	if err := h.services.Account.RemoveAvatar(user); err != nil {
+		log.Printf("Error removing avatar of user %s: %v", user.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not remove your avatar.")
+		return
+	}
+	if hadAvatar {
+		h.services.Audit.Record(auditEvent(c, models.AuditProfileUpdate, "user", user.ID.String(), gin.H{"avatar": "removed"}))
+	}
+
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"avatar_url": ""})
+		return
+	}
+	c.Header("HX-Redirect", "/settings?success=avatar_removed")
+	c.Status(http.StatusOK)
+}
+
+// usernameErrorResponse kullanıcı adı doğrulama hatalarını HTTP durumuna ve kullanıcı mesajına çevirir.
+func usernameErrorResponse(err error) (int, string) {
+	switch {
+	case errors.Is(err, services.ErrUsernameInvalid):
+		return http.StatusBadRequest, "Usernames must be 3-32 characters long and may only contain letters, digits, '.', '-' and '_'."
+	case errors.Is(err, services.ErrUsernameReserved):
+		return http.StatusBadRequest, "This username is reserved. Please choose another one."
+	case errors.Is(err, services.ErrUsernameTaken):
+		return http.StatusConflict, "This username is already taken."
+	default:
+		return http.StatusInternalServerError, "Could not check the username."
+	}
+}
+
+func avatarTooLargeMessage(maxBytes int) string {
+	if maxBytes >= 1<<20 {
+		return fmt.Sprintf("Avatar images can be at most %.1f MB.", float64(maxBytes)/(1<<20))
+	}
+	return fmt.Sprintf("Avatar images can be at most %d KB.", maxBytes>>10)
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	middleware "github.com/morgarakt/aurify/internal/middlewares"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+)
+
+const (
+	adminAuditPerPage     = 50
+	adminAuditExportLimit = 10000
+)
+
+// AuditLog denetim kayıtlarının admin sayfasıdır; eylem, aktör, hedef ve tarih aralığına göre filtrelenir.
+func (h *AdminHandler) AuditLog(c *gin.Context) {
+	_, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error/unauthorized.html", gin.H{"title": "Error", "message": err.Error()})
+		return
+	}
+	page := parsePage(c)
+
+	entries, total, err := h.repo.AuditLog.Query(filter, page, adminAuditPerPage)
+	if err != nil {
+		log.Printf("Admin audit: query failed: %v", err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load the audit log."})
+		return
+	}
+	totalPages := int((total + adminAuditPerPage - 1) / adminAuditPerPage)
+
+	c.HTML(http.StatusOK, "admin/audit.html", gin.H{
+		"title":       "Audit Log - Admin - Aurify",
+		"auth":        isAuthenticated,
+		"username":    username,
+		"Entries":     auditRows(entries),
+		"Actions":     models.AuditActions,
+		"Filter":      c.Request.URL.Query(),
+		"FilterQuery": auditFilterQuery(c),
+		"TotalItems":  total,
+		"CurrentPage": page,
+		"TotalPages":  totalPages,
+		"HasPrev":     page > 1,
+		"HasNext":     page < totalPages,
+		"PrevPage":    page - 1,
+		"NextPage":    page + 1,
+	})
+}
+
+// ListAuditLog denetim kayıtlarının sayfalı JSON halidir; sayfa ile aynı filtreleri kabul eder.
+func (h *AdminHandler) ListAuditLog(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page := parsePage(c)
+	entries, total, err := h.repo.AuditLog.Query(filter, page, adminAuditPerPage)
+	if err != nil {
+		log.Printf("Admin audit: query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not load the audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": auditRows(entries), "total": total, "page": page, "per_page": adminAuditPerPage})
+}
+
+// ExportAuditLog filtreye uyan kayıtları ?format=csv (varsayılan) ya da ?format=json olarak indirir.
+// En fazla adminAuditExportLimit kayıt yazılır; daha eskiler için tarih aralığı daraltılmalıdır.
+func (h *AdminHandler) ExportAuditLog(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or json"})
+		return
+	}
+	entries, _, err := h.repo.AuditLog.Query(filter, 1, adminAuditExportLimit)
+	if err != nil {
+		log.Printf("Admin audit: export query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not export the audit log"})
+		return
+	}
+	log.Printf("Admin %s exported %d audit log entries as %s", c.GetString("userID"), len(entries), format)
+
+	filename := fmt.Sprintf("audit-log-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	if format == "json" {
+		c.JSON(http.StatusOK, auditRows(entries))
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"created_at", "action", "actor_id", "actor_name", "target_type", "target_id", "ip_address", "user_agent", "diff"})
+	for _, e := range entries {
+		actorID := ""
+		if e.ActorID != nil {
+			actorID = e.ActorID.String()
+		}
+		_ = w.Write(csvSafeRow(
+			e.CreatedAt.UTC().Format(time.RFC3339), e.Action, actorID, e.ActorName,
+			e.TargetType, e.TargetID, e.IPAddress, e.UserAgent, string(e.Diff),
+		))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Printf("Admin audit: writing CSV export failed: %v", err)
+	}
+}
+
+// csvSafeRow hücreleri CSV'ye yazılmadan önce etkisizleştirir. Kullanıcı adı, başarısız girişte denenen e-posta
+// ve user agent saldırganın kontrolündedir; "=", "+", "-", "@", sekme veya CR ile başlayan bir değer
+// dışa aktarım tablo programında açıldığında formül olarak çalışır. Bu hücrelerin başına ' eklenir.
+func csvSafeRow(cells ...string) []string {
+	for i, cell := range cells {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			cells[i] = "'" + cell
+		}
+	}
+	return cells
+}
+
+// parseAuditFilter action, actor, target, from ve to (YYYY-MM-DD, dahil) parametrelerini okur.
+// Tarih verilmezse aralık sınırlanmaz.
+func parseAuditFilter(c *gin.Context) (repository.AuditLogFilter, error) {
+	filter := repository.AuditLogFilter{
+		Action:   c.Query("action"),
+		Actor:    c.Query("actor"),
+		TargetID: c.Query("target"),
+	}
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return filter, errors.New("invalid 'from' date, expected YYYY-MM-DD")
+		}
+		filter.From = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return filter, errors.New("invalid 'to' date, expected YYYY-MM-DD")
+		}
+		filter.To = t.AddDate(0, 0, 1)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, errors.New("'from' must not be after 'to'")
+	}
+	return filter, nil
+}
+
+// auditFilterQuery sayfalama ve dışa aktarma linklerinde korunacak filtre parametrelerini döner.
+// Değer zaten kodlanmış olduğundan template.URL olarak döner; aksi halde & ve = tekrar kaçırılır.
+func auditFilterQuery(c *gin.Context) template.URL {
+	values := url.Values{}
+	for _, key := range []string{"action", "actor", "target", "from", "to"} {
+		if v := c.Query(key); v != "" {
+			values.Set(key, v)
+		}
+	}
+	return template.URL(values.Encode())
+}
+
+func auditRows(entries []models.AuditLog) []gin.H {
+	rows := make([]gin.H, 0, len(entries))
+	for _, e := range entries {
+		actorID := ""
+		if e.ActorID != nil {
+			actorID = e.ActorID.String()
+		}
+		rows = append(rows, gin.H{
+			"CreatedAt":  e.CreatedAt.Format("2006-01-02 15:04:05"),
+			"Action":     e.Action,
+			"ActorID":    actorID,
+			"ActorName":  e.ActorName,
+			"TargetType": e.TargetType,
+			"TargetID":   e.TargetID,
+			"IPAddress":  e.IPAddress,
+			"UserAgent":  e.UserAgent,
+			"Diff":       e.Diff,
+		})
+	}
+	return rows
+}
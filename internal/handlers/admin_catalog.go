@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	middleware "github.com/morgarakt/aurify/internal/middlewares"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/services"
+	"gorm.io/gorm"
+)
+
+// Katalog route'larındaki :kind değerleri
+const (
+	catalogMusicTypes = "music-types"
+	catalogModelTypes = "model-types"
+)
+
+var catalogNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _-]{0,63}$`)
+
+// catalogModel, Entry() ile ortak katalog alanlarına erişilebilen *MusicType / *ModelType tipleridir.
+type catalogModel[T any] interface {
+	*T
+	Entry() *models.CatalogEntry
+}
+
+// CatalogEntryRequest hem oluşturma hem güncelleme için kullanılır; gönderilmeyen alanlar değişmez.
+// default_params bir JSON nesnesini metin olarak taşır.
+type CatalogEntryRequest struct {
+	Name          *string `json:"name" form:"name"`
+	DisplayName   *string `json:"display_name" form:"display_name"`
+	Description   *string `json:"description" form:"description"`
+	Enabled       *bool   `json:"enabled" form:"enabled"`
+	SortOrder     *int    `json:"sort_order" form:"sort_order"`
+	Icon          *string `json:"icon" form:"icon"`
+	DefaultParams *string `json:"default_params" form:"default_params"`
+}
+
+// Catalog müzik türü ve model kataloglarının yönetim sayfasıdır.
+func (h *AdminHandler) Catalog(c *gin.Context) {
+	_, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	musicTypes, err := h.repo.MusicType.GetAll()
+	if err != nil {
+		log.Printf("Admin catalog: loading music types failed: %v", err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load the catalog."})
+		return
+	}
+	modelTypes, err := h.repo.ModelType.GetAll()
+	if err != nil {
+		log.Printf("Admin catalog: loading model types failed: %v", err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load the catalog."})
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin/catalog.html", gin.H{
+		"title":    "Catalog - Admin - Aurify",
+		"auth":     isAuthenticated,
+		"username": username,
+		"Catalogs": []gin.H{
+			{"Kind": catalogMusicTypes, "Title": "Music types", "Entries": catalogRows[models.MusicType](musicTypes)},
+			{"Kind": catalogModelTypes, "Title": "AI models", "Entries": catalogRows[models.ModelType](modelTypes)},
+		},
+	})
+}
+
+// ListCatalog bir kataloğun (devre dışı olanlar dahil) JSON listesidir.
+func (h *AdminHandler) ListCatalog(c *gin.Context) {
+	switch c.Param("kind") {
+	case catalogMusicTypes:
+		listCatalog[models.MusicType](c, h.repo.MusicType)
+	case catalogModelTypes:
+		listCatalog[models.ModelType](c, h.repo.ModelType)
+	default:
+		respondError(c, http.StatusNotFound, "Unknown catalog.")
+	}
+}
+
+func (h *AdminHandler) CreateCatalogEntry(c *gin.Context) {
+	switch c.Param("kind") {
+	case catalogMusicTypes:
+		createCatalogEntry[models.MusicType](c, h.repo.MusicType, h.services.Audit)
+	case catalogModelTypes:
+		createCatalogEntry[models.ModelType](c, h.repo.ModelType, h.services.Audit)
+	default:
+		respondError(c, http.StatusNotFound, "Unknown catalog.")
+	}
+}
+
+func (h *AdminHandler) UpdateCatalogEntry(c *gin.Context) {
+	switch c.Param("kind") {
+	case catalogMusicTypes:
+		updateCatalogEntry[models.MusicType](c, h.repo.MusicType, h.services.Audit)
+	case catalogModelTypes:
+		updateCatalogEntry[models.ModelType](c, h.repo.ModelType, h.services.Audit)
+	default:
+		respondError(c, http.StatusNotFound, "Unknown catalog.")
+	}
+}
+
+// DisableCatalogEntry kaydı silmek yerine devre dışı bırakır; mevcut müzikler etkilenmez.
+func (h *AdminHandler) DisableCatalogEntry(c *gin.Context) {
+	h.setCatalogEnabled(c, false)
+}
+
+func (h *AdminHandler) EnableCatalogEntry(c *gin.Context) {
+	h.setCatalogEnabled(c, true)
+}
+
+func (h *AdminHandler) setCatalogEnabled(c *gin.Context, enabled bool) {
+	switch c.Param("kind") {
+	case catalogMusicTypes:
+		setCatalogEnabled[models.MusicType](c, h.repo.MusicType, enabled, h.services.Audit)
+	case catalogModelTypes:
+		setCatalogEnabled[models.ModelType](c, h.repo.ModelType, enabled, h.services.Audit)
+	default:
+		respondError(c, http.StatusNotFound, "Unknown catalog.")
+	}
+}
+
+func listCatalog[T any, PT catalogModel[T]](c *gin.Context, repo repository.CatalogRepository[T]) {
+	entries, err := repo.GetAll()
+	if err != nil {
+		log.Printf("Admin catalog (%s): listing failed: %v", c.Param("kind"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not load the catalog"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": catalogRows[T, PT](entries)})
+}
+
+func createCatalogEntry[T any, PT catalogModel[T]](c *gin.Context, repo repository.CatalogRepository[T], audit *services.AuditService) {
+	var req CatalogEntryRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid catalog entry.")
+		return
+	}
+	if req.Name == nil || !catalogNamePattern.MatchString(strings.TrimSpace(*req.Name)) {
+		respondError(c, http.StatusBadRequest, "Name is required and may only contain letters, digits, spaces, '-' and '_' (max 64).")
+		return
+	}
+	name := strings.TrimSpace(*req.Name)
+	if _, err := repo.GetByName(name); err == nil {
+		respondError(c, http.StatusConflict, "An entry with this name already exists.")
+		return
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Admin catalog (%s): checking name %q failed: %v", c.Param("kind"), name, err)
+		respondError(c, http.StatusInternalServerError, "Could not create the entry.")
+		return
+	}
+
+	fields, err := req.fields()
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	var entity T
+	entry := PT(&entity).Entry()
+	entry.ID = uuid.New()
+	entry.Name = name
+	entry.Enabled = req.Enabled == nil || *req.Enabled
+	applyCatalogFields(entry, fields)
+
+	if err := repo.Create(&entity); err != nil {
+		log.Printf("Admin catalog (%s): creating %q failed: %v", c.Param("kind"), name, err)
+		respondError(c, http.StatusInternalServerError, "Could not create the entry.")
+		return
+	}
+	log.Printf("Admin %s created %s entry %q", c.GetString("userID"), c.Param("kind"), name)
+	row := catalogRow(entry)
+	audit.Record(auditEvent(c, models.AuditCatalogCreate, c.Param("kind"), entry.ID.String(), services.AuditDiff(nil, row)))
+	respondCatalogSaved(c, http.StatusCreated, row)
+}
+
+func updateCatalogEntry[T any, PT catalogModel[T]](c *gin.Context, repo repository.CatalogRepository[T], audit *services.AuditService) {
+	entity, ok := getCatalogEntry[T](c, repo)
+	if !ok {
+		return
+	}
+	var req CatalogEntryRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid catalog entry.")
+		return
+	}
+	entry := PT(entity).Entry()
+	if req.Name != nil && strings.TrimSpace(*req.Name) != entry.Name {
+		respondError(c, http.StatusBadRequest, "The name of an entry cannot be changed; edit its display name instead.")
+		return
+	}
+	fields, err := req.fields()
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Enabled != nil {
+		fields["enabled"] = *req.Enabled
+	}
+	if err := repo.Update(entry.ID, fields); err != nil {
+		log.Printf("Admin catalog (%s): updating %s failed: %v", c.Param("kind"), entry.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not update the entry.")
+		return
+	}
+	before := catalogRow(entry)
+	applyCatalogFields(entry, fields)
+	log.Printf("Admin %s updated %s entry %q", c.GetString("userID"), c.Param("kind"), entry.Name)
+	row := catalogRow(entry)
+	audit.Record(auditEvent(c, models.AuditCatalogUpdate, c.Param("kind"), entry.ID.String(), services.AuditDiff(before, row)))
+	respondCatalogSaved(c, http.StatusOK, row)
+}
+
+func setCatalogEnabled[T any, PT catalogModel[T]](c *gin.Context, repo repository.CatalogRepository[T], enabled bool, audit *services.AuditService) {
+	entity, ok := getCatalogEntry[T](c, repo)
+	if !ok {
+		return
+	}
+	entry := PT(entity).Entry()
+	if err := repo.SetEnabled(entry.ID, enabled); err != nil {
+		log.Printf("Admin catalog (%s): setting enabled=%t on %s failed: %v", c.Param("kind"), enabled, entry.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not update the entry.")
+		return
+	}
+	action := models.AuditCatalogDisable
+	if enabled {
+		action = models.AuditCatalogEnable
+	}
+	audit.Record(auditEvent(c, action, c.Param("kind"), entry.ID.String(),
+		services.AuditDiff(gin.H{"Enabled": entry.Enabled}, gin.H{"Enabled": enabled})))
+	entry.Enabled = enabled
+	log.Printf("Admin %s set %s entry %q enabled=%t", c.GetString("userID"), c.Param("kind"), entry.Name, enabled)
+	respondCatalogSaved(c, http.StatusOK, catalogRow(entry))
+}
+
+func getCatalogEntry[T any](c *gin.Context, repo repository.CatalogRepository[T]) (*T, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid entry ID.")
+		return nil, false
+	}
+	var entity T
+	if err := repo.GetByID(id, &entity); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Entry not found.")
+			return nil, false
+		}
+		log.Printf("Admin catalog (%s): loading %s failed: %v", c.Param("kind"), id, err)
+		respondError(c, http.StatusInternalServerError, "Could not load the entry.")
+		return nil, false
+	}
+	return &entity, true
+}
+
+// fields isteği doğrulayıp güncellenecek sütunlara çevirir (enabled hariç).
+func (req *CatalogEntryRequest) fields() (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if req.DisplayName != nil {
+		v := strings.TrimSpace(*req.DisplayName)
+		if len(v) > 100 {
+			return nil, errors.New("Display name must be at most 100 characters.")
+		}
+		fields["display_name"] = v
+	}
+	if req.Description != nil {
+		v := strings.TrimSpace(*req.Description)
+		if len(v) > models.MaxDescriptionLen {
+			return nil, errors.New("Description is too long.")
+		}
+		fields["description"] = v
+	}
+	if req.SortOrder != nil {
+		fields["sort_order"] = *req.SortOrder
+	}
+	if req.Icon != nil {
+		v := strings.TrimSpace(*req.Icon)
+		if len(v) > 64 {
+			return nil, errors.New("Icon must be at most 64 characters.")
+		}
+		fields["icon"] = v
+	}
+	if req.DefaultParams != nil {
+		v := strings.TrimSpace(*req.DefaultParams)
+		if v == "" {
+			fields["default_params"] = nil
+		} else {
+			var params map[string]interface{}
+			if err := json.Unmarshal([]byte(v), &params); err != nil {
+				return nil, errors.New("Default params must be a JSON object.")
+			}
+			var compact bytes.Buffer
+			_ = json.Compact(&compact, []byte(v))
+			fields["default_params"] = json.RawMessage(compact.Bytes())
+		}
+	}
+	return fields, nil
+}
+
+func applyCatalogFields(entry *models.CatalogEntry, fields map[string]interface{}) {
+	for key, value := range fields {
+		switch key {
+		case "display_name":
+			entry.DisplayName = value.(string)
+		case "description":
+			entry.Description = value.(string)
+		case "sort_order":
+			entry.SortOrder = value.(int)
+		case "icon":
+			entry.Icon = value.(string)
+		case "enabled":
+			entry.Enabled = value.(bool)
+		case "default_params":
+			if raw, ok := value.(json.RawMessage); ok {
+				entry.DefaultParams = raw
+			} else {
+				entry.DefaultParams = nil
+			}
+		}
+	}
+}
+
+func respondCatalogSaved(c *gin.Context, status int, row gin.H) {
+	if !isHTMXRequest(c) {
+		c.JSON(status, row)
+		return
+	}
+	c.Header("HX-Redirect", "/admin/catalog?success=catalog_saved")
+	c.Status(http.StatusOK)
+}
+
+func catalogRow(entry *models.CatalogEntry) gin.H {
+	return gin.H{
+		"ID":            entry.ID.String(),
+		"Name":          entry.Name,
+		"DisplayName":   entry.DisplayName,
+		"Label":         entry.Label(),
+		"Description":   entry.Description,
+		"Enabled":       entry.Enabled,
+		"SortOrder":     entry.SortOrder,
+		"Icon":          entry.Icon,
+		"DefaultParams": string(entry.DefaultParams),
+	}
+}
+
+func catalogRows[T any, PT catalogModel[T]](entries []T) []gin.H {
+	rows := make([]gin.H, 0, len(entries))
+	for i := range entries {
+		rows = append(rows, catalogRow(PT(&entries[i]).Entry()))
+	}
+	return rows
+}
@@ -0,0 +1,464 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/config"
+	middleware "github.com/morgarakt/aurify/internal/middlewares"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/services"
+	"gorm.io/gorm"
+)
+
+// AdminHandler serves the /admin area. Every route is gated by middleware.RequireRole.
+type AdminHandler struct {
+	repo     *repository.Repository
+	cfg      *config.Config
+	services *services.Services
+}
+
+func NewAdminHandler(repo *repository.Repository, cfg *config.Config, svc *services.Services) *AdminHandler {
+	return &AdminHandler{repo: repo, cfg: cfg, services: svc}
+}
+
+// analyticsMaxRange tek raporda izin verilen en uzun tarih aralığıdır.
+const analyticsMaxRange = 366 * 24 * time.Hour
+
+// Dashboard admin alanının giriş sayfasıdır: kullanıcı sayıları ve seçilen tarih aralığı için platform analizleri.
+func (h *AdminHandler) Dashboard(c *gin.Context) {
+	_, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+
+	from, to, err := parseAnalyticsRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	report, err := h.services.Analytics.Report(from, to)
+	if err != nil {
+		log.Printf("Admin dashboard: building analytics report failed: %v", err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load the admin dashboard."})
+		return
+	}
+
+	roleCounts, err := h.repo.User.CountByRole()
+	if err != nil {
+		log.Printf("Admin dashboard: counting users failed: %v", err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load the admin dashboard."})
+		return
+	}
+	var totalUsers int64
+	roles := make([]gin.H, 0, len(models.Roles))
+	for _, role := range models.Roles {
+		totalUsers += roleCounts[role]
+		roles = append(roles, gin.H{"Name": role, "Count": roleCounts[role]})
+	}
+
+	c.HTML(http.StatusOK, "admin/admin.html", gin.H{
+		"title":      "Admin - Aurify",
+		"auth":       isAuthenticated,
+		"username":   username,
+		"Role":       middleware.GetUserRoleFromContext(c),
+		"TotalUsers": totalUsers,
+		"Roles":      roles,
+		"Report":     report,
+		"From":       from.Format(dateLayout),
+		"To":         to.AddDate(0, 0, -1).Format(dateLayout),
+		"DailyBars":  dailyBars(report.DailyTotals),
+		"Rates": gin.H{
+			"Success": fmt.Sprintf("%.1f%%", report.Generations.SuccessRate*100),
+			"Failure": fmt.Sprintf("%.1f%%", report.Generations.FailureRate*100),
+			"Timeout": fmt.Sprintf("%.1f%%", report.Generations.TimeoutRate*100),
+		},
+		"MedianLatency": fmt.Sprintf("%.1fs", report.Generations.MedianLatencyMs/1000),
+	})
+}
+
+// Analytics, dashboard raporunun JSON halidir: GET /admin/api/analytics?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *AdminHandler) Analytics(c *gin.Context) {
+	from, to, err := parseAnalyticsRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	report, err := h.services.Analytics.Report(from, to)
+	if err != nil {
+		log.Printf("Admin analytics: building report failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not build analytics report"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+const dateLayout = "2006-01-02"
+
+// parseAnalyticsRange from/to (dahil) tarihlerini [from, to) aralığına çevirir. Varsayılan son 30 gündür.
+func parseAnalyticsRange(c *gin.Context) (time.Time, time.Time, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to := today.AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -30)
+
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid 'to' date, expected YYYY-MM-DD")
+		}
+		to = t.AddDate(0, 0, 1)
+		from = to.AddDate(0, 0, -30)
+	}
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid 'from' date, expected YYYY-MM-DD")
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("'from' must not be after 'to'")
+	}
+	if to.Sub(from) > analyticsMaxRange {
+		return time.Time{}, time.Time{}, errors.New("date range is limited to one year")
+	}
+	return from, to, nil
+}
+
+// dailyBars günlük toplamları, en yüksek güne göre yüzde genişlikli çubuklar olarak hazırlar.
+func dailyBars(totals []repository.DailyCount) []gin.H {
+	var max int64
+	for _, d := range totals {
+		if d.Count > max {
+			max = d.Count
+		}
+	}
+	bars := make([]gin.H, 0, len(totals))
+	for _, d := range totals {
+		percent := 0
+		if max > 0 {
+			percent = int(d.Count * 100 / max)
+		}
+		bars = append(bars, gin.H{"Day": d.Day.Format(dateLayout), "Count": d.Count, "Percent": percent})
+	}
+	return bars
+}
+
+const adminUsersPerPage = 25
+
+// Users admin kullanıcı listesidir; ?q= ile kullanıcı adı/e-posta araması yapılır.
+func (h *AdminHandler) Users(c *gin.Context) {
+	_, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	query := c.Query("q")
+	page := parsePage(c)
+
+	users, total, err := h.repo.User.Search(query, page, adminUsersPerPage)
+	if err != nil {
+		log.Printf("Admin users: search failed: %v", err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load users."})
+		return
+	}
+	totalPages := int((total + adminUsersPerPage - 1) / adminUsersPerPage)
+
+	c.HTML(http.StatusOK, "admin/users.html", gin.H{
+		"title":       "Users - Admin - Aurify",
+		"auth":        isAuthenticated,
+		"username":    username,
+		"Users":       adminUserRows(users),
+		"Query":       query,
+		"QueryParam":  url.QueryEscape(query),
+		"TotalItems":  total,
+		"CurrentPage": page,
+		"TotalPages":  totalPages,
+		"HasPrev":     page > 1,
+		"HasNext":     page < totalPages,
+		"PrevPage":    page - 1,
+		"NextPage":    page + 1,
+	})
+}
+
+// SearchUsers kullanıcı aramasının JSON halidir.
+func (h *AdminHandler) SearchUsers(c *gin.Context) {
+	page := parsePage(c)
+	users, total, err := h.repo.User.Search(c.Query("q"), page, adminUsersPerPage)
+	if err != nil {
+		log.Printf("Admin users: search failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not search users"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"users": adminUserRows(users), "total": total, "page": page, "per_page": adminUsersPerPage})
+}
+
+// UserDetail bir kullanıcının bilgilerini, istatistiklerini ve parçalarını gösterir.
+func (h *AdminHandler) UserDetail(c *gin.Context) {
+	_, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	detail, ok := h.loadUserDetail(c)
+	if !ok {
+		return
+	}
+	detail["title"] = "User - Admin - Aurify"
+	detail["auth"] = isAuthenticated
+	detail["username"] = username
+	detail["Roles"] = models.Roles
+	c.HTML(http.StatusOK, "admin/user_detail.html", detail)
+}
+
+// GetUser kullanıcı detayının JSON halidir.
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	detail, ok := h.loadUserDetail(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"user": detail["User"], "stats": detail["Stats"], "tracks": detail["Tracks"]})
+}
+
+func (h *AdminHandler) loadUserDetail(c *gin.Context) (gin.H, bool) {
+	user, ok := h.getTargetUser(c)
+	if !ok {
+		return nil, false
+	}
+	stats, err := h.repo.User.GetStats(user.ID, user.QuotaWindowStart(time.Now()))
+	if err != nil {
+		log.Printf("Admin user detail: stats for %s failed: %v", user.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not load user statistics.")
+		return nil, false
+	}
+	tracks, _, err := h.repo.Music.QueryUserMusic(user.ID, repository.MusicQueryParams{SortBy: "added_desc", Page: 1, PerPage: 50})
+	if err != nil {
+		log.Printf("Admin user detail: tracks for %s failed: %v", user.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not load user tracks.")
+		return nil, false
+	}
+	trackRows := make([]gin.H, 0, len(tracks))
+	for _, m := range tracks {
+		trackRows = append(trackRows, gin.H{
+			"ID":        m.ID.String(),
+			"Title":     m.Title,
+			"IsPublic":  m.IsPublic,
+			"Likes":     m.LikesCount,
+			"MusicType": m.MusicType.Name,
+			"CreatedAt": m.CreatedAt.Format("02 Jan 2006"),
+		})
+	}
+	row := adminUserRow(*user)
+	if stats.LastSeenAt != nil {
+		row["LastSeen"] = stats.LastSeenAt.Format("02 Jan 2006 15:04")
+	}
+	return gin.H{
+		"User":       row,
+		"Stats":      stats,
+		"Tracks":     trackRows,
+		"QuotaLimit": h.cfg.GenerationQuota(user.IsEmailVerified()),
+	}, true
+}
+
+// SuspendUser hesabı askıya alır ve tüm oturumlarını kapatır.
+func (h *AdminHandler) SuspendUser(c *gin.Context) {
+	user, ok := h.getManageableUser(c)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	if err := h.repo.User.SetSuspended(user.ID, &now); err != nil {
+		log.Printf("Admin: suspending user %s failed: %v", user.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not suspend the user.")
+		return
+	}
+	revoked, err := h.services.Sessions.RevokeAllForUser(user.ID, uuid.Nil)
+	if err != nil {
+		log.Printf("Admin: user %s suspended but revoking sessions failed: %v", user.ID, err)
+	}
+	log.Printf("Admin %s suspended user %s (%d session(s) revoked)", c.GetString("userID"), user.ID, revoked)
+	h.services.Audit.Record(auditEvent(c, models.AuditUserSuspend, "user", user.ID.String(), gin.H{"sessions_revoked": revoked}))
+	h.respondUserUpdated(c, user.ID, "User suspended.")
+}
+
+func (h *AdminHandler) UnsuspendUser(c *gin.Context) {
+	user, ok := h.getManageableUser(c)
+	if !ok {
+		return
+	}
+	if err := h.repo.User.SetSuspended(user.ID, nil); err != nil {
+		log.Printf("Admin: unsuspending user %s failed: %v", user.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not unsuspend the user.")
+		return
+	}
+	log.Printf("Admin %s unsuspended user %s", c.GetString("userID"), user.ID)
+	h.services.Audit.Record(auditEvent(c, models.AuditUserUnsuspend, "user", user.ID.String(), nil))
+	h.respondUserUpdated(c, user.ID, "User unsuspended.")
+}
+
+// UnlockUser başarısız giriş denemeleri nedeniyle kilitlenen hesabı açar ve sayacı sıfırlar.
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	user, ok := h.getTargetUser(c)
+	if !ok {
+		return
+	}
+	if err := h.services.LoginGuard.Unlock(user); err != nil {
+		log.Printf("Admin: unlocking user %s failed: %v", user.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not unlock the user.")
+		return
+	}
+	log.Printf("Admin %s unlocked user %s", c.GetString("userID"), user.ID)
+	h.services.Audit.Record(auditEvent(c, models.AuditUserUnlock, "user", user.ID.String(), nil))
+	h.respondUserUpdated(c, user.ID, "User unlocked.")
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" form:"role" binding:"required"`
+}
+
+// UpdateUserRole kullanıcının rolünü değiştirir. Rol access token'da taşındığı için kullanıcının tüm oturumları
+// kapatılır; böylece yetkisi düşürülen biri eski token'ıyla süresi dolana kadar işlem yapamaz.
+func (h *AdminHandler) UpdateUserRole(c *gin.Context) {
+	user, ok := h.getManageableUser(c)
+	if !ok {
+		return
+	}
+	var req UpdateRoleRequest
+	if err := c.ShouldBind(&req); err != nil || !models.IsValidRole(req.Role) {
+		respondError(c, http.StatusBadRequest, "Invalid role.")
+		return
+	}
+	if err := h.repo.User.UpdateRole(user.ID, req.Role); err != nil {
+		log.Printf("Admin: changing role of %s failed: %v", user.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not change the role.")
+		return
+	}
+	revoked, err := h.services.Sessions.RevokeAllForUser(user.ID, uuid.Nil)
+	if err != nil {
+		log.Printf("Admin: role of %s changed but revoking sessions failed: %v", user.ID, err)
+	}
+	log.Printf("Admin %s changed role of user %s: %s -> %s (%d session(s) revoked)", c.GetString("userID"), user.ID, user.Role, req.Role, revoked)
+	h.services.Audit.Record(auditEvent(c, models.AuditUserRoleChange, "user", user.ID.String(),
+		services.AuditDiff(gin.H{"role": user.Role}, gin.H{"role": req.Role})))
+	h.respondUserUpdated(c, user.ID, "Role updated.")
+}
+
+// ResetUserQuota kullanıcının günlük üretim kotasını sıfırlar.
+func (h *AdminHandler) ResetUserQuota(c *gin.Context) {
+	user, ok := h.getTargetUser(c)
+	if !ok {
+		return
+	}
+	if err := h.repo.User.ResetQuota(user.ID, time.Now()); err != nil {
+		log.Printf("Admin: resetting quota of %s failed: %v", user.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not reset the quota.")
+		return
+	}
+	log.Printf("Admin %s reset generation quota of user %s", c.GetString("userID"), user.ID)
+	h.services.Audit.Record(auditEvent(c, models.AuditUserQuotaReset, "user", user.ID.String(), nil))
+	h.respondUserUpdated(c, user.ID, "Quota reset.")
+}
+
+// DeleteUser hesabı tüm içeriğiyle birlikte kalıcı olarak siler.
+func (h *AdminHandler) DeleteUser(c *gin.Context) {
+	user, ok := h.getManageableUser(c)
+	if !ok {
+		return
+	}
+	if _, err := h.services.Sessions.RevokeAllForUser(user.ID, uuid.Nil); err != nil {
+		log.Printf("Admin: revoking sessions before deleting %s failed: %v", user.ID, err)
+	}
+	if err := h.repo.User.HardDelete(user.ID); err != nil {
+		log.Printf("Admin: deleting user %s failed: %v", user.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not delete the user.")
+		return
+	}
+	log.Printf("Admin %s permanently deleted user %s (%s)", c.GetString("userID"), user.ID, user.Email)
+	h.services.Audit.Record(auditEvent(c, models.AuditUserDelete, "user", user.ID.String(),
+		gin.H{"username": user.Username, "email": user.Email, "role": user.Role}))
+
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"id": user.ID.String(), "deleted": true})
+		return
+	}
+	c.Header("HX-Redirect", "/admin/users?success=user_deleted")
+	c.Status(http.StatusOK)
+}
+
+func (h *AdminHandler) getTargetUser(c *gin.Context) (*models.User, bool) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid user ID.")
+		return nil, false
+	}
+	var user models.User
+	if err := h.repo.User.GetByID(userID, &user); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "User not found.")
+			return nil, false
+		}
+		log.Printf("Admin: loading user %s failed: %v", userID, err)
+		respondError(c, http.StatusInternalServerError, "Could not load the user.")
+		return nil, false
+	}
+	return &user, true
+}
+
+// getManageableUser hedef kullanıcıyı yükler; adminin kendi hesabını askıya almasını,
+// rolünü düşürmesini veya silmesini engeller.
+func (h *AdminHandler) getManageableUser(c *gin.Context) (*models.User, bool) {
+	user, ok := h.getTargetUser(c)
+	if !ok {
+		return nil, false
+	}
+	actorID, _, _ := middleware.GetUserInfoFromContext(c)
+	if user.ID == actorID {
+		respondError(c, http.StatusBadRequest, "You cannot perform this action on your own account.")
+		return nil, false
+	}
+	return user, true
+}
+
+func (h *AdminHandler) respondUserUpdated(c *gin.Context, userID uuid.UUID, message string) {
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"id": userID.String(), "message": message})
+		return
+	}
+	c.Header("HX-Redirect", "/admin/users/"+userID.String()+"?success=user_updated")
+	c.Status(http.StatusOK)
+}
+
+func adminUserRow(u models.User) gin.H {
+	row := gin.H{
+		"ID":           u.ID.String(),
+		"Username":     u.Username,
+		"Email":        u.Email,
+		"Role":         u.Role,
+		"Suspended":    u.IsSuspended(),
+		"Verified":     u.IsEmailVerified(),
+		"TwoFactor":    u.TwoFactorEnabled(),
+		"Locked":       u.IsLocked(time.Now()),
+		"FailedLogins": u.FailedLoginCount,
+		"CreatedAt":    u.CreatedAt.Format("02 Jan 2006"),
+	}
+	if u.SuspendedAt != nil {
+		row["SuspendedAt"] = u.SuspendedAt.Format("02 Jan 2006 15:04")
+	}
+	if u.LockedUntil != nil {
+		row["LockedUntil"] = u.LockedUntil.Format("02 Jan 2006 15:04")
+	}
+	return row
+}
+
+func adminUserRows(users []models.User) []gin.H {
+	rows := make([]gin.H, 0, len(users))
+	for _, u := range users {
+		rows = append(rows, adminUserRow(u))
+	}
+	return rows
+}
+
+func parsePage(c *gin.Context) int {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	return page
+}
@@ -2,30 +2,39 @@
 package handlers
 
 import (
-	// "fmt" // fmt loglama veya debug için kullanılmıyorsa kaldırılabilir
+	"errors"
+	"fmt"
 	"log" // Hata loglama için eklendi
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/morgarakt/aurify/internal/config"
+	middleware "github.com/morgarakt/aurify/internal/middlewares"
 	"github.com/morgarakt/aurify/internal/models"
 	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/services"
 	"github.com/morgarakt/aurify/internal/utils"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthHandler struct {
-	repo *repository.Repository
-	cfg  *config.Config
+	repo     *repository.Repository
+	cfg      *config.Config
+	services *services.Services
 }
 
-func NewAuthHandler(repo *repository.Repository, cfg *config.Config) *AuthHandler {
+func NewAuthHandler(repo *repository.Repository, cfg *config.Config, svc *services.Services) *AuthHandler {
 	return &AuthHandler{
-		repo: repo,
-		cfg:  cfg,
+		repo:     repo,
+		cfg:      cfg,
+		services: svc,
 	}
 }
 
@@ -42,6 +51,16 @@ type RegisterRequest struct {
 	// ConfirmPassword string `json:"confirm_password" form:"confirm_password"`
 }
 
+type ForgotPasswordRequest struct {
+	Email string `json:"email" form:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token           string `json:"token" form:"token" binding:"required"`
+	NewPassword     string `json:"new_password" form:"new_password" binding:"required,min=8"`
+	ConfirmPassword string `json:"confirm_password" form:"confirm_password" binding:"required"`
+}
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
@@ -72,16 +91,72 @@ func (h *AuthHandler) LoginHandler(c *gin.Context) {
 
 	user, err := h.repo.User.GetByEmail(req.Email)
 	if err != nil {
+		user = nil
+	}
+
+	// Deneme şifre kontrolünden önce hatalı sayılarak ayrılır; kilitli hesaplarda şifre hiç kontrol edilmez.
+	// Bilinmeyen e-postalar da aynı kurallarla sınırlanır.
+	attempt, err := h.services.LoginGuard.Begin(req.Email, user, c.ClientIP())
+	if err != nil {
+		var blocked *services.LoginBlockedError
+		if !errors.As(err, &blocked) {
+			log.Printf("Reserving login attempt for %s failed: %v", req.Email, err)
+			renderAuthError(c, "#login-inner-box", "Giriş şu anda yapılamıyor. Lütfen daha sonra tekrar deneyin.")
+			return
+		}
+		h.recordLoginFailure(c, req.Email, user, "throttled")
+		respondLoginBlocked(c, err)
+		return
+	}
+
+	if user == nil {
+		// Yanıt süresi kayıtlı hesaplardan ayırt edilemesin diye şifre yine de bir bcrypt özetiyle karşılaştırılır
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(req.Password))
+		h.recordLoginFailure(c, req.Email, nil, "unknown_email")
+		h.registerLoginFailure(c, attempt, nil)
 		renderAuthError(c, "#login-inner-box", "Geçersiz e-posta veya şifre.")
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		h.recordLoginFailure(c, req.Email, user, "wrong_password")
+		h.registerLoginFailure(c, attempt, user)
 		renderAuthError(c, "#login-inner-box", "Geçersiz e-posta veya şifre.")
 		return
 	}
+	attempt.Succeed()
 
-	err = utils.GenerateToken(c, user.ID.String(), user.Username, h.cfg.JWTSecret, time.Hour*24)
+	if user.IsSuspended() {
+		log.Printf("Login rejected for suspended user %s", user.ID)
+		h.recordLoginFailure(c, req.Email, user, "suspended")
+		renderAuthError(c, "#login-inner-box", "Hesabınız askıya alınmış. Lütfen destek ile iletişime geçin.")
+		return
+	}
+
+	// İki adımlı doğrulama açıksa oturum henüz açılmaz; kısa ömürlü ara token ile kod sayfasına gidilir.
+	if user.TwoFactorEnabled() {
+		challenge, err := utils.IssueTwoFactorChallenge(c, user.ID, h.cfg.JWTSecret, h.cfg.TwoFactorChallengeTTL)
+		if err != nil {
+			log.Printf("Issuing 2FA challenge for user %s failed: %v", user.ID, err)
+			renderAuthError(c, "#login-inner-box", "Oturum başlatılamadı.")
+			return
+		}
+		if !isHTMXRequest(c) {
+			c.JSON(http.StatusOK, gin.H{"two_factor_required": true, "challenge": challenge})
+			return
+		}
+		c.Header("HX-Redirect", "/login/2fa")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	h.completeLogin(c, user, nil)
+}
+
+// completeLogin oturumu açar, girişi denetim kaydına yazar ve kullanıcıyı yönlendirir.
+// Hem tek adımlı girişte hem de iki adımlı doğrulamanın ikinci adımında kullanılır.
+func (h *AuthHandler) completeLogin(c *gin.Context, user *models.User, auditDiff interface{}) {
+	redirectURL, err := h.startSession(c, user, auditDiff)
 	if err != nil {
 		renderAuthError(c, "#login-inner-box", "Oturum başlatılamadı.")
 		return
@@ -89,11 +164,25 @@ func (h *AuthHandler) LoginHandler(c *gin.Context) {
 
 	// --- Yönlendirme Değişikliği ---
 	// c.Redirect yerine HX-Redirect header'ı kullan
-	redirectURL := "/?success=" + url.QueryEscape("login_ok")
 	c.Header("HX-Redirect", redirectURL)
 	c.Status(http.StatusOK) // HTMX'in header'ı işlemesi için genellikle 2xx yanıt gerekir
 }
 
+// startSession token'ları yazar, girişi denetim kaydına ekler, anonim müzikleri sahiplenir
+// ve kullanıcının yönlendirileceği adresi döner.
+func (h *AuthHandler) startSession(c *gin.Context, user *models.User, auditDiff interface{}) (string, error) {
+	if err := utils.GenerateToken(c, user.ID.String(), user.Username, user.Role, h.cfg.JWTSecret); err != nil {
+		return "", err
+	}
+	h.services.Audit.Record(userAuditEvent(c, models.AuditLoginSuccess, user, auditDiff))
+
+	redirectURL := "/?success=" + url.QueryEscape("login_ok")
+	if h.claimAnonymousMusic(c, user.ID) > 0 {
+		redirectURL = "/library?success=" + url.QueryEscape("claim_ok")
+	}
+	return redirectURL, nil
+}
+
 func (h *AuthHandler) RegisterHandler(c *gin.Context) {
 	var req RegisterRequest
 	// Hem JSON hem form verisi için ShouldBind kullan
@@ -114,8 +203,15 @@ func (h *AuthHandler) RegisterHandler(c *gin.Context) {
 		return
 	}
 
-	// Kullanıcı adı kontrolü de eklenebilir
-	// _, err = h.repo.User.GetByUsername(req.Username) ...
+	req.Username = strings.TrimSpace(req.Username)
+	if err := h.services.Account.CheckUsernameAvailable(req.Username); err != nil {
+		status, message := usernameErrorResponse(err)
+		if status == http.StatusInternalServerError {
+			log.Printf("Checking username %q failed: %v", req.Username, err)
+		}
+		c.JSON(status, ErrorResponse{Error: message})
+		return
+	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -130,6 +226,7 @@ func (h *AuthHandler) RegisterHandler(c *gin.Context) {
 		Username:     req.Username,
 		Email:        req.Email,
 		PasswordHash: string(hashedPassword),
+		Role:         models.RoleUser, // ADMIN_EMAILS adresleri admin yetkisini e-posta doğrulanınca alır
 	}
 
 	if err := h.repo.User.Create(user); err != nil {
@@ -139,8 +236,15 @@ func (h *AuthHandler) RegisterHandler(c *gin.Context) {
 		return
 	}
 
+	h.services.Audit.Record(userAuditEvent(c, models.AuditRegister, user, gin.H{"email": user.Email, "role": user.Role}))
+
+	// Doğrulama e-postası gönderilemese de kayıt tamamlanır; kullanıcı ayarlardan yeniden isteyebilir.
+	if err := h.services.EmailVerification.SendVerification(user); err != nil {
+		log.Printf("Sending verification email to new user %s failed: %v", user.ID, err)
+	}
+
 	// Başarılı kayıt sonrası token oluştur
-	err = utils.GenerateToken(c, user.ID.String(), user.Username, h.cfg.JWTSecret, time.Hour*24)
+	err = utils.GenerateToken(c, user.ID.String(), user.Username, user.Role, h.cfg.JWTSecret)
 	if err != nil {
 		log.Printf("Token generation failed after registration for user %s: %v", user.Email, err)
 		// Kullanıcı oluşturuldu ama token verilemedi. Bu durumu kullanıcıya bildirmek önemli.
@@ -154,17 +258,208 @@ func (h *AuthHandler) RegisterHandler(c *gin.Context) {
 	// --- Yönlendirme Değişikliği ---
 	// c.Redirect yerine HX-Redirect header'ı kullan
 	redirectURL := "/?success=" + url.QueryEscape("register_ok")
+	if h.claimAnonymousMusic(c, user.ID) > 0 {
+		redirectURL = "/library?success=" + url.QueryEscape("register_claim_ok")
+	}
 	c.Header("HX-Redirect", redirectURL)
 	c.Status(http.StatusOK) // HTMX header'ı işlesin diye 2xx yanıt
 }
 
+// userAuditEvent, giriş/kayıt gibi bağlamda henüz kullanıcı bulunmayan istekler için aktörü kullanıcının kendisi yapar.
+func userAuditEvent(c *gin.Context, action string, user *models.User, diff interface{}) services.AuditEvent {
+	event := auditEvent(c, action, "user", user.ID.String(), diff)
+	event.ActorID = user.ID
+	event.ActorName = user.Username
+	return event
+}
+
+// recordLoginFailure başarısız giriş denemesini kaydeder. Bilinmeyen e-postalarda aktör olarak denenen e-posta yazılır.
+func (h *AuthHandler) recordLoginFailure(c *gin.Context, email string, user *models.User, reason string) {
+	if user != nil {
+		h.services.Audit.Record(userAuditEvent(c, models.AuditLoginFailure, user, gin.H{"reason": reason}))
+		return
+	}
+	event := auditEvent(c, models.AuditLoginFailure, "user", "", gin.H{"reason": reason})
+	event.ActorName = email
+	h.services.Audit.Record(event)
+}
+
+// registerLoginFailure ayrılan denemeyi hatalı olarak onaylar; hesap bu denemeyle kilitlendiyse denetim kaydına yazar.
+func (h *AuthHandler) registerLoginFailure(c *gin.Context, attempt *services.LoginAttempt, user *models.User) {
+	if attempt.Fail() {
+		h.services.Audit.Record(userAuditEvent(c, models.AuditAccountLocked, user, gin.H{
+			"failed_attempts": user.FailedLoginCount,
+			"locked_until":    user.LockedUntil,
+		}))
+	}
+}
+
+// respondLoginBlocked çok sık ya da kilitli hesaba yapılan giriş denemesini reddeder.
+// Mesaj, hesabın var olup olmadığından bağımsız olarak aynıdır.
+func respondLoginBlocked(c *gin.Context, err error) {
+	message := "Çok fazla başarısız giriş denemesi. Lütfen daha sonra tekrar deneyin."
+	var blocked *services.LoginBlockedError
+	if errors.As(err, &blocked) {
+		seconds := int(math.Ceil(blocked.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		if !blocked.Locked {
+			message = fmt.Sprintf("Lütfen tekrar denemeden önce %d saniye bekleyin.", seconds)
+		}
+	}
+	c.JSON(http.StatusTooManyRequests, ErrorResponse{Error: message})
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// dummyPasswordHash bilinmeyen e-postalarla yapılan denemelerde karşılaştırılacak sabit bir bcrypt özetidir.
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("aurify-dummy-password"), bcrypt.DefaultCost)
+	})
+	return dummyHash
+}
+
+// claimAnonymousMusic, bu tarayıcıda giriş yapmadan üretilmiş müzikleri kullanıcının kütüphanesine aktarır.
+// Hata oturum açmayı engellemez; sadece loglanır. Aktarılan parça sayısını döner.
+func (h *AuthHandler) claimAnonymousMusic(c *gin.Context, userID uuid.UUID) int64 {
+	sessionID, ok := utils.GetAnonSessionID(c)
+	if !ok {
+		return 0
+	}
+	claimed, err := h.repo.Music.ClaimAnonymous(sessionID, userID, time.Now().Add(-h.cfg.AnonSessionTTL))
+	if err != nil {
+		log.Printf("Failed to claim anonymous music (session %s) for user %s: %v", sessionID, userID, err)
+		return 0
+	}
+	utils.ClearAnonSession(c)
+	if claimed > 0 {
+		log.Printf("Claimed %d anonymous track(s) from session %s for user %s", claimed, sessionID, userID)
+	}
+	return claimed
+}
+
 func (h *AuthHandler) LogoutHandler(c *gin.Context) {
-	err := utils.RevokeToken(c)
+	err := utils.RevokeToken(c, h.cfg.JWTSecret)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Logout failed"})
 		return
 	}
+	if userID, _, authenticated := middleware.GetUserInfoFromContext(c); authenticated {
+		h.services.Audit.Record(auditEvent(c, models.AuditLogout, "user", userID.String(), gin.H{"session_id": middleware.GetSessionIDFromContext(c)}))
+	}
 	// Navbar'daki JS yönlendirmesi çalıştığı için bu JSON yanıtı kalabilir.
 	// Eğer JS yönlendirmesi olmasaydı, buradan da HX-Redirect header ile yönlendirme yapılabilirdi.
 	c.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
 }
+
+// VerifyEmail e-postadaki doğrulama linkinin açtığı sayfadır. Başarılı olursa ana sayfaya yönlendirir,
+// link geçersizse (giriş yapılmışsa yeniden gönderme butonuyla) bir hata sayfası gösterir.
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	user, err := h.services.EmailVerification.Verify(c.Query("token"))
+	if err == nil {
+		h.services.Audit.Record(userAuditEvent(c, models.AuditEmailVerified, user, gin.H{"email": user.Email, "role": user.Role}))
+		c.Redirect(http.StatusSeeOther, "/?success=email_verified")
+		return
+	}
+	if !errors.Is(err, services.ErrVerificationTokenInvalid) {
+		log.Printf("Email verification failed: %v", err)
+	}
+	_, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	c.HTML(http.StatusBadRequest, "auth/verify_email.html", gin.H{
+		"title":    "Verify Email - Aurify",
+		"auth":     isAuthenticated,
+		"username": username,
+	})
+}
+
+// ForgotPasswordHandler e-posta adresine şifre sıfırlama linki gönderir.
+// Hesap olsun ya da olmasın aynı yanıt döner; böylece kayıtlı e-postalar sorgulanamaz.
+func (h *AuthHandler) ForgotPasswordHandler(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Please enter a valid email address.")
+		return
+	}
+
+	user, err := h.services.PasswordReset.RequestReset(req.Email, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, services.ErrResetRateLimited) {
+			respondError(c, http.StatusTooManyRequests, "Too many reset requests for this address. Please try again later.")
+			return
+		}
+		log.Printf("Password reset request failed: %v", err)
+		respondError(c, http.StatusInternalServerError, "Could not send the reset link. Please try again.")
+		return
+	}
+	if user != nil {
+		h.services.Audit.Record(userAuditEvent(c, models.AuditPasswordResetRequest, user, nil))
+	}
+
+	const message = "If an account exists for this address, a reset link is on its way."
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"message": message})
+		return
+	}
+	c.HTML(http.StatusOK, "partials/_password_reset_sent.html", gin.H{"Email": req.Email})
+}
+
+// ResetPasswordHandler linkteki token ile yeni şifreyi kaydeder. Başarılı olursa tüm oturumlar kapatılır
+// ve kullanıcı giriş sayfasına yönlendirilir.
+func (h *AuthHandler) ResetPasswordHandler(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "New password must be at least 8 characters.")
+		return
+	}
+	if req.NewPassword != req.ConfirmPassword {
+		respondError(c, http.StatusBadRequest, "New passwords do not match.")
+		return
+	}
+
+	user, err := h.services.PasswordReset.ResetPassword(req.Token, req.NewPassword)
+	if err != nil {
+		if errors.Is(err, services.ErrResetTokenInvalid) {
+			respondError(c, http.StatusBadRequest, "This reset link is invalid or has expired. Please request a new one.")
+			return
+		}
+		log.Printf("Password reset failed: %v", err)
+		respondError(c, http.StatusInternalServerError, "Could not reset the password.")
+		return
+	}
+	h.services.Audit.Record(userAuditEvent(c, models.AuditPasswordReset, user, nil))
+
+	// Bu tarayıcıda başka bir hesapla açık oturum kalmasın
+	utils.ClearAuthCookies(c)
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+		return
+	}
+	c.Header("HX-Redirect", "/login?success=password_reset")
+	c.Status(http.StatusOK)
+}
+
+// RefreshTokenHandler refresh token çerezini döndürür ve yeni bir access token yazar.
+// Eski bir refresh token tekrar kullanılırsa oturumun tamamı iptal edilir.
+func (h *AuthHandler) RefreshTokenHandler(c *gin.Context) {
+	claims, err := utils.RefreshToken(c, h.cfg.JWTSecret)
+	if err != nil {
+		if errors.Is(err, utils.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Refresh token was already used; session has been revoked"})
+			return
+		}
+		if errors.Is(err, utils.ErrRefreshTokenInvalid) || errors.Is(err, utils.ErrSessionInactive) {
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid or expired refresh token"})
+			return
+		}
+		log.Printf("Token refresh failed: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Token refresh failed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Token refreshed",
+		"expires_at": claims.ExpiresAt.Time,
+	})
+}
@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	middleware "github.com/morgarakt/aurify/internal/middlewares"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/services"
+	"gorm.io/gorm"
+)
+
+// commentsPerPage bir seferde yüklenen üst düzey yorum sayısıdır; yanıtlar her zaman yorumlarıyla birlikte gelir.
+const commentsPerPage = 20
+
+type CommentRequest struct {
+	Body      string `json:"body" form:"body"`
+	Timestamp string `json:"timestamp" form:"timestamp"` // İsteğe bağlı parça anı, ör. "0:42"
+	// Sadece yazarken: yanıtlanan yorum. Bir yanıta verilen yanıt aynı üst yorumun altına eklenir.
+	ParentID string `json:"parent_id" form:"parent_id"`
+}
+
+// commentViewer yorumları görüntüleyen kullanıcıdır; düzenleme ve silme butonları buna göre gösterilir.
+type commentViewer struct {
+	UserID uuid.UUID
+	Role   string
+	Auth   bool
+}
+
+func commentViewerFromContext(c *gin.Context) commentViewer {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	return commentViewer{UserID: userID, Role: middleware.GetUserRoleFromContext(c), Auth: isAuthenticated}
+}
+
+// GetComments parçanın yorumlarının bir sonraki sayfasını döner. ?cursor= bir önceki yanıttaki NextCursor değeridir.
+func (h *MusicHandler) GetComments(c *gin.Context) {
+	music, ok := h.loadCommentableMusic(c)
+	if !ok {
+		return
+	}
+	viewer := commentViewerFromContext(c)
+	page, err := h.commentsPageData(music, viewer, c.Query("cursor"))
+	if err != nil {
+		if errors.Is(err, errInvalidFeedCursor) {
+			respondError(c, http.StatusBadRequest, "Invalid comment cursor.")
+			return
+		}
+		log.Printf("Error loading comments for music %s: %v", music.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not load comments.")
+		return
+	}
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"comments": page["Comments"], "next_cursor": page["NextCursor"]})
+		return
+	}
+	c.HTML(http.StatusOK, "partials/_comment_page.html", page)
+}
+
+// PostComment parçaya yorum ya da bir yoruma yanıt yazar. HTMX isteklerine yeni yorum ve güncel yorum sayısı döner.
+func (h *MusicHandler) PostComment(c *gin.Context) {
+	userID, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in to comment.")
+		return
+	}
+	music, ok := h.loadCommentableMusic(c)
+	if !ok {
+		return
+	}
+	var req CommentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid comment.")
+		return
+	}
+	var parentID *uuid.UUID
+	if req.ParentID != "" {
+		id, err := uuid.Parse(req.ParentID)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid comment ID.")
+			return
+		}
+		parentID = &id
+	}
+
+	comment, err := h.services.Comments.Post(userID, music, parentID, req.Body, req.Timestamp)
+	if err != nil {
+		respondCommentError(c, err, "Could not post your comment.")
+		return
+	}
+	// Yorum kartında yazarın avatarı da gösterildiği için kullanıcı kaydı okunur
+	if err := h.repo.User.GetByID(userID, &comment.User); err != nil {
+		comment.User = models.User{ID: userID, Username: username}
+	}
+
+	viewer := commentViewerFromContext(c)
+	view := h.commentView(comment, music, viewer)
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusCreated, view)
+		return
+	}
+	view["CountOOB"] = h.commentCountData(music.ID)
+	c.HTML(http.StatusCreated, "partials/_comment.html", view)
+}
+
+// UpdateComment yorumun metnini ve parça anını değiştirir; sadece yazarı düzenleyebilir.
+func (h *MusicHandler) UpdateComment(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in to edit comments.")
+		return
+	}
+	comment, music, ok := h.loadComment(c)
+	if !ok {
+		return
+	}
+	var req CommentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid comment.")
+		return
+	}
+	if err := h.services.Comments.Edit(comment, userID, req.Body, req.Timestamp); err != nil {
+		respondCommentError(c, err, "Could not save your comment.")
+		return
+	}
+
+	view := h.commentView(comment, music, commentViewerFromContext(c))
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, view)
+		return
+	}
+	c.HTML(http.StatusOK, "partials/_comment_body.html", view)
+}
+
+// DeleteComment yorumu (üst düzey ise yanıtlarıyla birlikte) siler. Yazarı, parçanın sahibi ve moderatörler silebilir;
+// yazarı dışında biri sildiğinde denetim kaydı tutulur.
+func (h *MusicHandler) DeleteComment(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in to delete comments.")
+		return
+	}
+	comment, music, ok := h.loadComment(c)
+	if !ok {
+		return
+	}
+	if err := h.services.Comments.Delete(comment, music, userID, middleware.GetUserRoleFromContext(c)); err != nil {
+		respondCommentError(c, err, "Could not delete the comment.")
+		return
+	}
+	if comment.UserID != userID {
+		h.services.Audit.Record(auditEvent(c, models.AuditCommentDelete, "comment", comment.ID.String(), gin.H{
+			"music_id": music.ID.String(),
+			"author":   comment.User.Username,
+			"body":     comment.Body,
+		}))
+	}
+	log.Printf("User %s deleted comment %s on music %s", userID, comment.ID, music.ID)
+
+	if !isHTMXRequest(c) {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	// Boş yanıt yorumu sayfadan kaldırır (hx-swap="outerHTML"); sayaç out-of-band güncellenir
+	c.HTML(http.StatusOK, "partials/_comment_count.html", h.commentCountData(music.ID))
+}
+
+// loadCommentableMusic :id parçasını yükler. Yorumlar parçanın kendisiyle aynı kurala tabidir:
+// herkese açık parçalarda herkes, gizli parçalarda sadece sahibi görebilir ve yazabilir.
+func (h *MusicHandler) loadCommentableMusic(c *gin.Context) (*models.Music, bool) {
+	musicID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid music ID.")
+		return nil, false
+	}
+	music, err := h.repo.Music.GetByIDWithRelations(musicID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Music not found.")
+			return nil, false
+		}
+		log.Printf("Error fetching music %s for comments: %v", musicID, err)
+		respondError(c, http.StatusInternalServerError, "Could not load comments.")
+		return nil, false
+	}
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	isOwner := isAuthenticated && music.UserID != nil && *music.UserID == userID
+	if !music.IsPublic && !isOwner {
+		respondError(c, http.StatusNotFound, "Music not found.")
+		return nil, false
+	}
+	return music, true
+}
+
+// loadComment :id yorumunu ve ait olduğu parçayı yükler. Yetki kontrolü servis tarafından yapılır.
+func (h *MusicHandler) loadComment(c *gin.Context) (*models.Comment, *models.Music, bool) {
+	commentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid comment ID.")
+		return nil, nil, false
+	}
+	comment, err := h.repo.Comment.GetByID(commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Comment not found.")
+			return nil, nil, false
+		}
+		log.Printf("Error fetching comment %s: %v", commentID, err)
+		respondError(c, http.StatusInternalServerError, "Could not load the comment.")
+		return nil, nil, false
+	}
+	var music models.Music
+	if err := h.repo.Music.GetByID(comment.MusicID, &music); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) { // Parça çöp kutusunda
+			respondError(c, http.StatusNotFound, "Comment not found.")
+			return nil, nil, false
+		}
+		log.Printf("Error fetching music %s for comment %s: %v", comment.MusicID, commentID, err)
+		respondError(c, http.StatusInternalServerError, "Could not load the comment.")
+		return nil, nil, false
+	}
+	return comment, &music, true
+}
+
+// commentsPageData yorumların bir sayfasını okur. Bir fazla kayıt istenerek sonraki sayfanın olup olmadığı anlaşılır.
+// İmleç takip akışıyla aynı "<unix mikrosaniye>_<ID>" biçimindedir.
+func (h *MusicHandler) commentsPageData(music *models.Music, viewer commentViewer, rawCursor string) (gin.H, error) {
+	var before *repository.CommentCursor
+	if rawCursor != "" {
+		cursor, ok := parseFeedCursor(rawCursor)
+		if !ok {
+			return nil, errInvalidFeedCursor
+		}
+		before = &repository.CommentCursor{CreatedAt: cursor.PublishedAt, ID: cursor.ID}
+	}
+	threads, err := h.repo.Comment.ListThreads(music.ID, before, commentsPerPage+1)
+	if err != nil {
+		return nil, err
+	}
+	nextCursor := ""
+	if len(threads) > commentsPerPage {
+		threads = threads[:commentsPerPage]
+		last := threads[commentsPerPage-1]
+		nextCursor = formatFeedCursor(last.CreatedAt, last.ID)
+	}
+	items := make([]gin.H, 0, len(threads))
+	for i := range threads {
+		items = append(items, h.commentView(&threads[i], music, viewer))
+	}
+	return gin.H{
+		"Comments":   items,
+		"NextCursor": nextCursor,
+		"MusicID":    music.ID.String(),
+	}, nil
+}
+
+// commentsSectionData parça detay sayfasındaki yorum bölümünün ilk halini hazırlar.
+func (h *MusicHandler) commentsSectionData(music *models.Music, viewer commentViewer, loginRedirect string) gin.H {
+	section := gin.H{
+		"MusicID":  music.ID.String(),
+		"Auth":     viewer.Auth,
+		"LoginURL": "/login?redirect=" + loginRedirect,
+		"Count":    h.commentCountData(music.ID),
+	}
+	page, err := h.commentsPageData(music, viewer, "")
+	if err != nil { // Yorumlar okunamazsa sayfanın geri kalanı yine gösterilir
+		log.Printf("Error loading comments for music %s: %v", music.ID, err)
+		section["LoadFailed"] = true
+		return section
+	}
+	section["Page"] = page
+	return section
+}
+
+// commentCountData _comment_count.html'in beklediği alanları hazırlar.
+func (h *MusicHandler) commentCountData(musicID uuid.UUID) gin.H {
+	count, err := h.repo.Comment.CountByMusic(musicID)
+	if err != nil {
+		log.Printf("Error counting comments for music %s: %v", musicID, err)
+	}
+	return gin.H{"Count": count}
+}
+
+// commentView bir yorumu (üst düzey ise yanıtlarıyla) template ve JSON için hazırlar.
+func (h *MusicHandler) commentView(comment *models.Comment, music *models.Music, viewer commentViewer) gin.H {
+	view := gin.H{
+		"ID":         comment.ID.String(),
+		"MusicID":    music.ID.String(),
+		"Body":       comment.Body,
+		"Author":     comment.User.Username,
+		"AuthorURL":  profileURL(comment.User.Username),
+		"AvatarURL":  h.services.Account.AvatarURL(&comment.User),
+		"Initial":    strings.ToUpper(string([]rune(comment.User.Username)[:1])),
+		"CreatedAt":  comment.CreatedAt.Format("02 Jan 2006 15:04"),
+		"Edited":     comment.EditedAt != nil,
+		"IsReply":    comment.IsReply(),
+		"IsOwnTrack": music.UserID != nil && *music.UserID == comment.UserID,
+		"Auth":       viewer.Auth,
+		"CanEdit":    viewer.Auth && comment.UserID == viewer.UserID,
+		"CanDelete":  services.CanDeleteComment(comment, music, viewer.UserID, viewer.Role),
+		"Timestamp":  "",
+	}
+	if comment.TimestampSeconds != nil {
+		view["Timestamp"] = services.FormatCommentTimestamp(*comment.TimestampSeconds)
+		view["TimestampSeconds"] = *comment.TimestampSeconds
+	}
+	if comment.ParentID != nil {
+		view["ParentID"] = comment.ParentID.String()
+	}
+	if !comment.IsReply() {
+		replies := make([]gin.H, 0, len(comment.Replies))
+		for i := range comment.Replies {
+			replies = append(replies, h.commentView(&comment.Replies[i], music, viewer))
+		}
+		view["Replies"] = replies
+	}
+	return view
+}
+
+func respondCommentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrCommentEmpty):
+		respondError(c, http.StatusBadRequest, "Please write something first.")
+	case errors.Is(err, services.ErrCommentTooLong):
+		respondError(c, http.StatusBadRequest, "Comments can be at most 2000 characters.")
+	case errors.Is(err, services.ErrCommentTimestamp):
+		respondError(c, http.StatusBadRequest, "Timestamps look like 0:42 or 1:02:03.")
+	case errors.Is(err, services.ErrCommentParent):
+		respondError(c, http.StatusNotFound, "The comment you are replying to no longer exists.")
+	case errors.Is(err, services.ErrCommentForbidden):
+		respondError(c, http.StatusForbidden, "You cannot change this comment.")
+	case errors.Is(err, services.ErrCommentRateLimited):
+		respondError(c, http.StatusTooManyRequests, "You are commenting too fast. Please wait a few minutes.")
+	default:
+		log.Printf("Comment request failed: %v", err)
+		respondError(c, http.StatusInternalServerError, fallback)
+	}
+}
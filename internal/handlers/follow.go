@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	middleware "github.com/morgarakt/aurify/internal/middlewares"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ToggleFollow :username kullanıcısını takip eder veya takibi bırakır ve takip butonu parçasını
+// güncel takipçi sayısıyla yeniden çizer. ToggleLikeMusic ile aynı akışı izler.
+func (h *FrontendHandler) ToggleFollow(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		c.Header("HX-Reswap", "none")
+		c.Header("HX-Trigger", `{"showNotification": {"type": "error", "message": "Please log in to follow users."}}`)
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	followee, err := h.repo.User.GetByUsername(strings.TrimSpace(c.Param("username")))
+	if err != nil || followee.IsSuspended() {
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Error loading user %q for follow toggle: %v", c.Param("username"), err)
+			c.Header("HX-Reswap", "none")
+			c.Header("HX-Trigger", `{"showNotification": {"type": "error", "message": "Could not process follow."}}`)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Header("HX-Reswap", "none")
+		c.Header("HX-Trigger", `{"showNotification": {"type": "error", "message": "User not found."}}`)
+		c.Status(http.StatusNotFound)
+		return
+	}
+	if followee.ID == userID {
+		c.Header("HX-Reswap", "none")
+		c.Header("HX-Trigger", `{"showNotification": {"type": "error", "message": "You cannot follow yourself."}}`)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	isFollowing, err := h.repo.UserFollow.IsFollowing(userID, followee.ID)
+	if err != nil {
+		log.Printf("Error checking if user %s follows %s: %v", userID, followee.ID, err)
+		c.Header("HX-Reswap", "none")
+		c.Header("HX-Trigger", `{"showNotification": {"type": "error", "message": "Could not process follow."}}`)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if isFollowing {
+		if err := h.repo.UserFollow.Unfollow(userID, followee.ID); err != nil {
+			log.Printf("Error removing follow %s -> %s: %v", userID, followee.ID, err)
+			c.Header("HX-Reswap", "none")
+			c.Header("HX-Trigger", `{"showNotification": {"type": "error", "message": "Failed to unfollow."}}`)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	} else {
+		follow := &models.UserFollow{
+			FollowerID: userID,
+			FolloweeID: followee.ID,
+			CreatedAt:  time.Now(),
+		}
+		if err := h.repo.UserFollow.Follow(follow); err != nil {
+			log.Printf("Error adding follow %s -> %s: %v", userID, followee.ID, err)
+			c.Header("HX-Reswap", "none")
+			c.Header("HX-Trigger", `{"showNotification": {"type": "error", "message": "Failed to follow."}}`)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	stats, err := h.repo.User.GetProfileStats(followee.ID)
+	if err != nil { // Takip durumu değişti; sayaç okunamazsa sadece buton güncellensin
+		log.Printf("Error refetching profile stats after follow toggle for user %s: %v", followee.ID, err)
+	}
+	c.HTML(http.StatusOK, "partials/_follow_button_partial.html", followButtonData(followee, stats, !isFollowing, true, false))
+}
+
+// Following takip edilen hesapların yeni yayınlanan parçalarını listeleyen akış sayfasıdır.
+func (h *FrontendHandler) Following(c *gin.Context) {
+	userID, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		c.Redirect(http.StatusSeeOther, "/login?redirect=/following")
+		return
+	}
+	feed, err := h.followingFeedData(c, userID)
+	if err != nil {
+		log.Printf("Error loading following feed for user %s: %v", userID, err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load your feed."})
+		return
+	}
+	c.HTML(http.StatusOK, "music/following.html", gin.H{
+		"title":    "Following - Aurify",
+		"auth":     isAuthenticated,
+		"username": username,
+		"Feed":     feed,
+	})
+}
+
+// GetFollowingFeed akışın bir sonraki sayfasını döner. ?cursor= bir önceki yanıttaki NextCursor değeridir.
+func (h *FrontendHandler) GetFollowingFeed(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in to view your feed.")
+		return
+	}
+	feed, err := h.followingFeedData(c, userID)
+	if err != nil {
+		if errors.Is(err, errInvalidFeedCursor) {
+			respondError(c, http.StatusBadRequest, "Invalid feed cursor.")
+			return
+		}
+		log.Printf("Error loading following feed for user %s: %v", userID, err)
+		respondError(c, http.StatusInternalServerError, "Could not load your feed.")
+		return
+	}
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"music": feed["Music"], "next_cursor": feed["NextCursor"]})
+		return
+	}
+	c.HTML(http.StatusOK, "partials/_following_feed.html", feed)
+}
+
+var errInvalidFeedCursor = errors.New("invalid feed cursor")
+
+// followingFeedData akışın bir sayfasını okur. Bir fazla kayıt istenerek sonraki sayfanın olup olmadığı anlaşılır.
+func (h *FrontendHandler) followingFeedData(c *gin.Context, userID uuid.UUID) (gin.H, error) {
+	var after *repository.FeedCursor
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, ok := parseFeedCursor(raw)
+		if !ok {
+			return nil, errInvalidFeedCursor
+		}
+		after = cursor
+	}
+	limit := getPerPageFromQueryOrDefault(c, h.cfg.DefaultPerPage, h.cfg.MinPerPage, h.cfg.MaxPerPage)
+
+	musicList, err := h.repo.Music.QueryFollowingFeed(userID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	nextCursor := ""
+	if len(musicList) > limit {
+		musicList = musicList[:limit]
+		last := musicList[limit-1]
+		nextCursor = formatFeedCursor(*last.PublishedAt, last.ID)
+	}
+
+	items := make([]gin.H, 0, len(musicList))
+	for i := range musicList {
+		item := h.musicCardData(&musicList[i], true, userID)
+		item["PublishedAt"] = musicList[i].PublishedAt.Format("02 Jan 2006 15:04")
+		item["Auth"] = true // _like_button_partial $.Auth bekler
+		items = append(items, item)
+	}
+	return gin.H{
+		"Music":      items,
+		"NextCursor": nextCursor,
+		"PerPage":    limit,
+		"IsFirst":    after == nil,
+	}, nil
+}
+
+// Akış imleci "<yayınlanma zamanı, unix mikrosaniye>_<parça ID>" biçimindedir. Postgres zaman damgaları
+// mikrosaniye hassasiyetinde olduğu için karşılaştırma kayıpsızdır.
+func formatFeedCursor(publishedAt time.Time, id uuid.UUID) string {
+	return strconv.FormatInt(publishedAt.UnixMicro(), 10) + "_" + id.String()
+}
+
+func parseFeedCursor(raw string) (*repository.FeedCursor, bool) {
+	micros, idPart, found := strings.Cut(raw, "_")
+	if !found {
+		return nil, false
+	}
+	ts, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	id, err := uuid.Parse(idPart)
+	if err != nil {
+		return nil, false
+	}
+	return &repository.FeedCursor{PublishedAt: time.UnixMicro(ts), ID: id}, true
+}
+
+// followButtonData _follow_button_partial.html'in beklediği alanları hazırlar. stats nil ise sayaç gösterilmez.
+func followButtonData(user *models.User, stats *repository.ProfileStats, isFollowing, isAuthenticated, isSelf bool) gin.H {
+	data := gin.H{
+		"Username":    user.Username,
+		"ToggleURL":   "/api/v1/users/" + url.PathEscape(user.Username) + "/toggle-follow",
+		"LoginURL":    "/login?redirect=" + url.QueryEscape(profileURL(user.Username)),
+		"IsFollowing": isFollowing,
+		"Auth":        isAuthenticated,
+		"IsSelf":      isSelf,
+		"HasStats":    stats != nil,
+	}
+	if stats != nil {
+		data["Followers"] = stats.Followers
+		data["Following"] = stats.Following
+	}
+	return data
+}
@@ -2,12 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math" // Min fonksiyonu için
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -24,13 +26,15 @@ type FrontendHandler struct {
 	repo           *repository.Repository
 	cfg            *config.Config
 	rabbitmqClient *services.RabbitMQClient
+	services       *services.Services
 }
 
-func NewFrontendHandler(repo *repository.Repository, cfg *config.Config, rmqClient *services.RabbitMQClient) *FrontendHandler {
+func NewFrontendHandler(repo *repository.Repository, cfg *config.Config, rmqClient *services.RabbitMQClient, svc *services.Services) *FrontendHandler {
 	return &FrontendHandler{
 		repo:           repo,
 		cfg:            cfg,
 		rabbitmqClient: rmqClient,
+		services:       svc,
 	}
 }
 
@@ -70,6 +74,28 @@ func getPerPageFromQueryOrDefault(c *gin.Context, defaultVal, minVal, maxVal int
 	return i
 }
 
+// parseTagFilter "tags" parametrelerini (tekrarlı veya virgülle ayrılmış) normalize eder; geçersiz etiketler atlanır.
+func parseTagFilter(query url.Values) []string {
+	var raw []string
+	for _, v := range query["tags"] {
+		raw = append(raw, strings.Split(v, ",")...)
+	}
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, r := range raw {
+		name, ok := models.NormalizeTagName(r)
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		tags = append(tags, name)
+		if len(tags) == models.MaxTagsPerMusic {
+			break
+		}
+	}
+	return tags
+}
+
 // min helper for int64
 func minInt64(a, b int64) int64 {
 	if a < b {
@@ -90,57 +116,8 @@ func (h *FrontendHandler) preparePaginationData(
 	requestingUserID uuid.UUID,
 ) ([]gin.H, gin.H) {
 	formattedMusic := []gin.H{}
-	for _, m := range musicList {
-		hasLiked := false
-		if isAuthenticated && requestingUserID != uuid.Nil {
-			var errLikeCheck error
-			hasLiked, errLikeCheck = h.repo.UserLikes.HasUserLiked(requestingUserID, m.ID)
-			if errLikeCheck != nil {
-				log.Printf("Error checking like status for music %s, user %s: %v", m.ID, requestingUserID, errLikeCheck)
-			}
-		}
-
-		isOwner := false
-		if isAuthenticated && m.UserID != nil && *m.UserID == requestingUserID {
-			isOwner = true
-		}
-
-		creatorUsername := "Anonymous"
-		if m.User.ID != uuid.Nil && m.User.Username != "" {
-			creatorUsername = m.User.Username
-		}
-		musicTypeName := "Unknown"
-		if m.MusicType.ID != uuid.Nil && m.MusicType.Name != "" {
-			musicTypeName = m.MusicType.Name
-		}
-		modelTypeName := "Unknown"
-		if m.ModelType.ID != uuid.Nil && m.ModelType.Name != "" {
-			modelTypeName = m.ModelType.Name
-		}
-		coverArtPath := m.CoverArtPath
-		if coverArtPath == "" {
-			coverArtPath = "/static/images/placeholder_cover.png"
-		}
-		title := m.Title
-		if title == "" {
-			title = "Untitled Track"
-		}
-
-		formattedMusic = append(formattedMusic, gin.H{
-			"ID":           m.ID.String(),
-			"Title":        title,
-			"Creator":      creatorUsername,
-			"MusicType":    musicTypeName,
-			"ModelType":    modelTypeName,
-			"CreationYear": m.CreatedAt.Year(),
-			"Mp3FilePath":  m.Mp3FilePath,  // Kartta play butonu için
-			"MidiFilePath": m.MidiFilePath, // Kartta indirme için
-			"CoverArtPath": coverArtPath,
-			"LikesCount":   m.LikesCount,
-			"HasLiked":     hasLiked,
-			"IsOwner":      isOwner,    // YENİ EKLENDİ
-			"IsPublic":     m.IsPublic, // _visibility_toggle_partial için kartta da gerekebilir
-		})
+	for i := range musicList {
+		formattedMusic = append(formattedMusic, h.musicCardData(&musicList[i], isAuthenticated, requestingUserID))
 	}
 
 	// ... (Sayfalama hesaplamaları (totalPages, pageNumbers, linkParams, startItem, endItem) aynı kalır)
@@ -200,6 +177,10 @@ func (h *FrontendHandler) preparePaginationData(
 	if sortVal := currentQuery.Get("sort"); sortVal != "" {
 		linkParams.Set("sort", sortVal)
 	}
+	tagFilter := strings.Join(parseTagFilter(currentQuery), ",")
+	if tagFilter != "" {
+		linkParams.Set("tags", tagFilter)
+	}
 	linkParams.Set("per_page", strconv.Itoa(perPage))
 	linkQueryString := linkParams.Encode()
 
@@ -225,6 +206,7 @@ func (h *FrontendHandler) preparePaginationData(
 		"SearchQuery":     currentQuery.Get("q"),
 		"MusicTypeFilter": currentQuery.Get("musictype"),
 		"SortBy":          currentQuery.Get("sort"),
+		"TagsFilter":      tagFilter,
 		"BaseLink":        viewPath,
 		"LinkQuery":       linkQueryString,
 		"StartItem":       startItem,
@@ -233,6 +215,67 @@ func (h *FrontendHandler) preparePaginationData(
 	return formattedMusic, pagination
 }
 
+// musicCardData müzik kartlarının (liste, explore, profil, takip akışı) beklediği alanları hazırlar.
+func (h *FrontendHandler) musicCardData(m *models.Music, isAuthenticated bool, requestingUserID uuid.UUID) gin.H {
+	hasLiked := false
+	if isAuthenticated && requestingUserID != uuid.Nil {
+		var errLikeCheck error
+		hasLiked, errLikeCheck = h.repo.UserLikes.HasUserLiked(requestingUserID, m.ID)
+		if errLikeCheck != nil {
+			log.Printf("Error checking like status for music %s, user %s: %v", m.ID, requestingUserID, errLikeCheck)
+		}
+	}
+
+	isOwner := false
+	if isAuthenticated && m.UserID != nil && *m.UserID == requestingUserID {
+		isOwner = true
+	}
+
+	creatorUsername, creatorURL := "Anonymous", ""
+	if m.User.ID != uuid.Nil && m.User.Username != "" {
+		creatorUsername, creatorURL = m.User.Username, profileURL(m.User.Username)
+	}
+	musicTypeName := "Unknown"
+	if m.MusicType.ID != uuid.Nil && m.MusicType.Name != "" {
+		musicTypeName = m.MusicType.Name
+	}
+	modelTypeName := "Unknown"
+	if m.ModelType.ID != uuid.Nil && m.ModelType.Name != "" {
+		modelTypeName = m.ModelType.Name
+	}
+	coverArtPath := m.CoverArtPath
+	if coverArtPath == "" {
+		coverArtPath = "/static/images/placeholder_cover.png"
+	}
+	title := m.Title
+	if title == "" {
+		title = "Untitled Track"
+	}
+
+	tagNames := make([]string, len(m.Tags))
+	for i, t := range m.Tags {
+		tagNames[i] = t.Name
+	}
+
+	return gin.H{
+		"ID":           m.ID.String(),
+		"Tags":         tagNames,
+		"Title":        title,
+		"Creator":      creatorUsername,
+		"CreatorURL":   creatorURL,
+		"MusicType":    musicTypeName,
+		"ModelType":    modelTypeName,
+		"CreationYear": m.CreatedAt.Year(),
+		"Mp3FilePath":  m.Mp3FilePath,  // Kartta play butonu için
+		"MidiFilePath": m.MidiFilePath, // Kartta indirme için
+		"CoverArtPath": coverArtPath,
+		"LikesCount":   m.LikesCount,
+		"HasLiked":     hasLiked,
+		"IsOwner":      isOwner,    // YENİ EKLENDİ
+		"IsPublic":     m.IsPublic, // _visibility_toggle_partial için kartta da gerekebilir
+	}
+}
+
 func (h *FrontendHandler) GetMusicsLibrary(c *gin.Context) {
 	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c) // Auth bilgisini de al
 	if !isAuthenticated {                                              // Auth bilgisini kontrol et
@@ -251,6 +294,7 @@ func (h *FrontendHandler) GetMusicsLibrary(c *gin.Context) {
 		SearchQuery:     c.Query("q"),
 		MusicTypeFilter: c.Query("musictype"),
 		SortBy:          c.Query("sort"),
+		Tags:            parseTagFilter(c.Request.URL.Query()),
 		Page:            page,
 		PerPage:         perPage,
 	}
@@ -292,6 +336,7 @@ func (h *FrontendHandler) GetExploreMusicData(c *gin.Context) {
 		SearchQuery:     c.Query("q"),
 		MusicTypeFilter: c.Query("musictype"),
 		SortBy:          c.Query("sort"),
+		Tags:            parseTagFilter(c.Request.URL.Query()),
 		Page:            page,
 		PerPage:         perPage,
 	}
@@ -339,6 +384,7 @@ func (h *FrontendHandler) Library(c *gin.Context) {
 		SearchQuery:     c.Query("q"),
 		MusicTypeFilter: c.Query("musictype"),
 		SortBy:          c.Query("sort"),
+		Tags:            parseTagFilter(c.Request.URL.Query()),
 		Page:            page,
 		PerPage:         perPage,
 	}
@@ -363,6 +409,62 @@ func (h *FrontendHandler) Library(c *gin.Context) {
 	})
 }
 
+// RecentlyDeleted, kullanıcının çöp kutusundaki (hâlâ geri alınabilir) müziklerini listeler.
+func (h *FrontendHandler) RecentlyDeleted(c *gin.Context) {
+	userID, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		c.Redirect(http.StatusSeeOther, "/login?redirect=/library/deleted")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage := h.cfg.MaxPerPage
+
+	restorableSince := h.services.Purger.RestorableSince()
+	musicList, totalItems, err := h.repo.Music.QueryUserDeletedMusic(userID, restorableSince, page, perPage)
+	if err != nil {
+		log.Printf("Error loading deleted music for %s: %v", userID, err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load recently deleted tracks."})
+		return
+	}
+
+	deleted := make([]gin.H, 0, len(musicList))
+	for _, m := range musicList {
+		title := m.Title
+		if title == "" {
+			title = "Untitled Track"
+		}
+		purgeAt := m.DeletedAt.Time.Add(h.services.Purger.Retention())
+		deleted = append(deleted, gin.H{
+			"ID":           m.ID.String(),
+			"Title":        title,
+			"CoverArtPath": m.CoverArtPath,
+			"MusicType":    m.MusicType.Name,
+			"DeletedAt":    m.DeletedAt.Time.Format("02 Jan 2006 15:04"),
+			"DaysLeft":     int(math.Ceil(time.Until(purgeAt).Hours() / 24)),
+		})
+	}
+
+	totalPages := int(math.Ceil(float64(totalItems) / float64(perPage)))
+	c.HTML(http.StatusOK, "music/deleted.html", gin.H{
+		"title":         "Recently Deleted - Aurify",
+		"auth":          isAuthenticated,
+		"username":      username,
+		"Deleted":       deleted,
+		"RetentionDays": retentionDays(h.services.Purger.Retention()),
+		"CurrentPage":   page,
+		"TotalPages":    totalPages,
+		"TotalItems":    totalItems,
+		"HasPrev":       page > 1,
+		"HasNext":       page < totalPages,
+		"PrevPage":      page - 1,
+		"NextPage":      page + 1,
+	})
+}
+
 func (h *FrontendHandler) Explore(c *gin.Context) {
 	requestingUserID, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
 
@@ -378,6 +480,7 @@ func (h *FrontendHandler) Explore(c *gin.Context) {
 		SearchQuery:     c.Query("q"),
 		MusicTypeFilter: c.Query("musictype"),
 		SortBy:          c.Query("sort"),
+		Tags:            parseTagFilter(c.Request.URL.Query()),
 		Page:            page,
 		PerPage:         perPage,
 	}
@@ -406,11 +509,11 @@ func (h *FrontendHandler) Explore(c *gin.Context) {
 // ... (diğer handler metodlarınız)
 func (h *FrontendHandler) HomePage(c *gin.Context) {
 	userID, username, auth := middleware.GetUserInfoFromContext(c)
-	musicTypes, err := h.repo.MusicType.GetAll()
+	musicTypes, err := h.repo.MusicType.ListEnabled()
 	if err != nil {
 		log.Printf("Error fetching music types: %v", err)
 	}
-	modelTypes, err := h.repo.ModelType.GetAll()
+	modelTypes, err := h.repo.ModelType.ListEnabled()
 	if err != nil {
 		log.Printf("Error fetching model types: %v", err)
 	}
@@ -431,8 +534,7 @@ func (h *FrontendHandler) Login(c *gin.Context) {
 		c.Redirect(http.StatusFound, "/")
 		return
 	}
-	data := gin.H{"title": "Login to Aurify", "auth": false}
-	c.HTML(http.StatusOK, "auth/login.html", data)
+	c.HTML(http.StatusOK, "auth/login.html", loginPageData(h.services, ""))
 }
 
 func (h *FrontendHandler) Register(c *gin.Context) {
@@ -445,6 +547,28 @@ func (h *FrontendHandler) Register(c *gin.Context) {
 	c.HTML(http.StatusOK, "auth/register.html", data)
 }
 
+func (h *FrontendHandler) ForgotPassword(c *gin.Context) {
+	_, _, auth := middleware.GetUserInfoFromContext(c)
+	if auth {
+		c.Redirect(http.StatusFound, "/settings/sessions")
+		return
+	}
+	c.HTML(http.StatusOK, "auth/forgot_password.html", gin.H{"title": "Forgot Password - Aurify", "auth": false})
+}
+
+// ResetPassword e-postadaki linkin açtığı sayfadır; link geçersizse form yerine yeni link isteme yönlendirmesi gösterilir.
+func (h *FrontendHandler) ResetPassword(c *gin.Context) {
+	token := c.Query("token")
+	data := gin.H{"title": "Reset Password - Aurify", "auth": false, "Token": token, "Valid": true}
+	if err := h.services.PasswordReset.ValidateToken(token); err != nil {
+		if !errors.Is(err, services.ErrResetTokenInvalid) {
+			log.Printf("Validating password reset link failed: %v", err)
+		}
+		data["Valid"] = false
+	}
+	c.HTML(http.StatusOK, "auth/reset_password.html", data)
+}
+
 func (h *FrontendHandler) GenerateMusicHandler(c *gin.Context) {
 	userID, username, auth := middleware.GetUserInfoFromContext(c)
 	var formReq struct {
@@ -468,12 +592,56 @@ func (h *FrontendHandler) GenerateMusicHandler(c *gin.Context) {
 		c.HTML(http.StatusInternalServerError, "partials/play_button.html", gin.H{"Error": "Generation service is currently unavailable.", "auth": auth})
 		return
 	}
+
+	// Yalnızca katalogda etkin olan tür ve modeller üretilebilir.
+	requestedMusicType, err := h.repo.MusicType.GetByName(musicTypeName)
+	if err != nil || !requestedMusicType.Enabled {
+		c.HTML(http.StatusBadRequest, "partials/play_button.html", gin.H{"Error": "The selected music type is not available.", "auth": auth})
+		return
+	}
+	requestedModelType, err := h.repo.ModelType.GetByName(aiModelName)
+	if err != nil || !requestedModelType.Enabled {
+		c.HTML(http.StatusBadRequest, "partials/play_button.html", gin.H{"Error": "The selected AI model is not available.", "auth": auth})
+		return
+	}
+
+	if auth && userID != uuid.Nil {
+		if limit, verified, exceeded, err := h.generationQuotaExceeded(userID); err != nil {
+			log.Printf("Error checking generation quota for user %s: %v", userID, err)
+		} else if exceeded {
+			message := fmt.Sprintf("Daily generation limit reached (%d per 24 hours). Please try again later.", limit)
+			if !verified {
+				message = fmt.Sprintf("Unverified accounts can generate %d tracks per 24 hours. Verify your email address to raise the limit.", limit)
+			}
+			c.HTML(http.StatusTooManyRequests, "partials/play_button.html", gin.H{"Error": message, "auth": auth})
+			return
+		}
+	}
+
+	// Her üretim denemesi analiz için kaydedilir; sonuç varsayılan olarak hata, başarılı kayıtta güncellenir.
+	generation := &models.Generation{MusicTypeName: musicTypeName, ModelTypeName: aiModelName, Status: models.GenerationFailed}
+	if auth && userID != uuid.Nil {
+		generation.UserID = &userID
+	}
+	defer h.recordGeneration(generation)
+
 	reqParams := map[string]interface{}{
 		"run_mode": aiModelName, "model_type": aiModelName, "music_type": musicTypeName,
 		"start_sequence": []int{60, 64, 67, 72}, "length": 150, "temperature": 0.85,
 		"bpm": 120, "note_duration": 0.4, "instrument_program": 0,
 	}
+	// Katalogdaki varsayılan parametreler önce tür, sonra model için uygulanır; seçim anahtarları değiştirilemez.
+	for _, params := range []map[string]interface{}{requestedMusicType.Params(), requestedModelType.Params()} {
+		for key, value := range params {
+			switch key {
+			case "run_mode", "model_type", "music_type":
+				continue
+			}
+			reqParams[key] = value
+		}
+	}
 	taskID := uuid.New().String()
+	generation.TaskID = taskID
 	rabbitRequest := GenerateMusicRabbitMQRequest{TaskID: taskID, Params: reqParams}
 	requestBody, err := json.Marshal(rabbitRequest)
 	if err != nil {
@@ -482,8 +650,14 @@ func (h *FrontendHandler) GenerateMusicHandler(c *gin.Context) {
 		return
 	}
 	log.Printf("Sending request to RabbitMQ (TaskID: %s)...", taskID)
+	rpcStarted := time.Now()
 	responseBody, err := h.rabbitmqClient.Call("music_requests", requestBody, 60*time.Second)
+	generation.LatencyMs = time.Since(rpcStarted).Milliseconds()
 	if err != nil {
+		if errors.Is(err, services.ErrRPCTimeout) {
+			generation.Status = models.GenerationTimedOut
+		}
+		generation.ErrorMessage = err.Error()
 		log.Printf("Error calling RabbitMQ service (TaskID: %s): %v", taskID, err)
 		c.HTML(http.StatusInternalServerError, "partials/play_button.html", gin.H{"Error": "Music generation timed out or service is unavailable.", "auth": auth})
 		return
@@ -492,12 +666,17 @@ func (h *FrontendHandler) GenerateMusicHandler(c *gin.Context) {
 	var rabbitResponse GenerateMusicRabbitMQResponse
 	if err := json.Unmarshal(responseBody, &rabbitResponse); err != nil {
 		log.Printf("Error unmarshalling RabbitMQ response (TaskID: %s): %v\nResponse Body: %s", taskID, err, string(responseBody))
+		generation.ErrorMessage = "invalid worker response"
 		c.HTML(http.StatusInternalServerError, "partials/play_button.html", gin.H{"Error": "Invalid response from generation service.", "auth": auth})
 		return
 	}
+	if rabbitResponse.ModelUsed != "" {
+		generation.ModelTypeName = rabbitResponse.ModelUsed
+	}
 	log.Printf("DEBUG: RabbitMQ Response Parsed - Status: '%s', ModelUsed: '%s', ImageUrl: '%s'", rabbitResponse.Status, rabbitResponse.ModelUsed, rabbitResponse.ImageUrl)
 	if rabbitResponse.Status == "error" {
 		log.Printf("Music generation failed via worker (TaskID: %s): %s", taskID, rabbitResponse.Message)
+		generation.ErrorMessage = rabbitResponse.Message
 		c.HTML(http.StatusInternalServerError, "partials/play_button.html", gin.H{"Error": fmt.Sprintf("Generation failed: %s", rabbitResponse.Message), "auth": auth})
 		return
 	}
@@ -507,12 +686,7 @@ func (h *FrontendHandler) GenerateMusicHandler(c *gin.Context) {
 		return
 	}
 
-	musicType, err := h.repo.MusicType.GetByName(musicTypeName)
-	if err != nil {
-		log.Printf("Error finding MusicType '%s' for saving: %v", musicTypeName, err)
-		c.HTML(http.StatusInternalServerError, "partials/play_button.html", gin.H{"Error": "Failed to process music type details.", "auth": auth})
-		return
-	}
+	musicType := requestedMusicType
 	modelType, err := h.repo.ModelType.GetByName(rabbitResponse.ModelUsed)
 	if err != nil {
 		log.Printf("Error finding ModelType '%s' for saving: %v", rabbitResponse.ModelUsed, err)
@@ -538,6 +712,10 @@ func (h *FrontendHandler) GenerateMusicHandler(c *gin.Context) {
 	}
 	if auth && userID != uuid.Nil {
 		newMusic.UserID = &userID
+	} else {
+		// Anonim üretimler tarayıcıya bağlanır; aynı tarayıcıdan kayıt/giriş yapılınca kullanıcıya aktarılır.
+		anonSessionID := utils.GetOrCreateAnonSessionID(c, h.cfg.AnonSessionTTL)
+		newMusic.AnonymousSessionID = &anonSessionID
 	}
 	if err := h.repo.Music.Create(&newMusic); err != nil {
 		log.Printf("!!! CRITICAL: Failed to auto-save music (TaskID: %s, UserID: %s): %v", taskID, userID, err)
@@ -545,6 +723,19 @@ func (h *FrontendHandler) GenerateMusicHandler(c *gin.Context) {
 		return
 	}
 	log.Printf("Music auto-saved: ID=%s, UserID=%s, CoverArt=%s", newMusic.ID, userID, newMusic.CoverArtPath)
+	generation.Status = models.GenerationSucceeded
+	generation.MusicID = &newMusic.ID
+
+	// Waveform peak'leri worker'ın ürettiği WAV'dan arka planda çıkarılır; oynatıcı hazır olmadıkları sürece çizmez.
+	if rabbitResponse.WavUrl != "" {
+		go func(musicID uuid.UUID, wavURL string) {
+			if err := h.services.Waveform.GenerateForMusic(musicID, wavURL); err != nil {
+				log.Printf("Waveform generation failed for music %s: %v", musicID, err)
+			}
+		}(newMusic.ID, rabbitResponse.WavUrl)
+	} else {
+		log.Printf("No wav_url from worker for music %s, skipping waveform generation.", newMusic.ID)
+	}
 
 	data := gin.H{
 		"Auth": auth, "Username": username, "Mp3Url": rabbitResponse.Mp3Url, "MidiUrl": rabbitResponse.MidiUrl,
@@ -557,6 +748,33 @@ func (h *FrontendHandler) GenerateMusicHandler(c *gin.Context) {
 	c.HTML(http.StatusOK, "partials/music_player.html", data)
 }
 
+// generationQuotaExceeded kullanıcının 24 saatlik üretim kotasını doldurup doldurmadığını bildirir.
+// Uygulanan kotayı ve e-postanın doğrulanmış olup olmadığını da döner (mesaj için).
+func (h *FrontendHandler) generationQuotaExceeded(userID uuid.UUID) (limit int, verified bool, exceeded bool, err error) {
+	var user models.User
+	if err := h.repo.User.GetByID(userID, &user); err != nil {
+		return 0, false, false, err
+	}
+	verified = user.IsEmailVerified()
+	limit = h.cfg.GenerationQuota(verified)
+	if limit <= 0 {
+		return limit, verified, false, nil
+	}
+	used, err := h.repo.Generation.CountSucceededSince(userID, user.QuotaWindowStart(time.Now()))
+	if err != nil {
+		return limit, verified, false, err
+	}
+	return limit, verified, used >= int64(limit), nil
+}
+
+// recordGeneration üretim kaydını yazar. Hata kullanıcıya yansıtılmaz, sadece loglanır.
+func (h *FrontendHandler) recordGeneration(generation *models.Generation) {
+	generation.ErrorMessage = services.Truncate(generation.ErrorMessage, 512)
+	if err := h.repo.Generation.Create(generation); err != nil {
+		log.Printf("Failed to record generation (TaskID: %s, Status: %s): %v", generation.TaskID, generation.Status, err)
+	}
+}
+
 func (h *FrontendHandler) NotFoundPage(c *gin.Context) {
 	_, username, auth := middleware.GetUserInfoFromContext(c)
 	c.HTML(http.StatusNotFound, "error/notfound.html", gin.H{
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	middleware "github.com/morgarakt/aurify/internal/middlewares"
+	"github.com/morgarakt/aurify/internal/services"
+)
+
+// respondError HTMX isteklerinde bildirim tetikler, diğer isteklerde JSON hata döner.
+func respondError(c *gin.Context, status int, message string) {
+	if !isHTMXRequest(c) {
+		c.AbortWithStatusJSON(status, gin.H{"error": message})
+		return
+	}
+	trigger, _ := json.Marshal(gin.H{"showNotification": gin.H{"type": "error", "message": message}})
+	c.Header("HX-Reswap", "none")
+	c.Header("HX-Trigger", string(trigger))
+	c.AbortWithStatus(status)
+}
+
+func isHTMXRequest(c *gin.Context) bool {
+	return c.GetHeader("HX-Request") == "true"
+}
+
+// notifySuccess HTMX yanıtına başarı bildirimi tetikleyen HX-Trigger başlığını ekler.
+func notifySuccess(c *gin.Context, message string) {
+	trigger, _ := json.Marshal(gin.H{"showNotification": gin.H{"type": "success", "message": message}})
+	c.Header("HX-Trigger", string(trigger))
+}
+
+// auditEvent istekteki aktör, IP ve user agent bilgileriyle bir denetim kaydı hazırlar.
+// Oturum açılmamışsa aktör boş kalır; çağıran gerekirse ActorID/ActorName'i doldurur.
+func auditEvent(c *gin.Context, action, targetType, targetID string, diff interface{}) services.AuditEvent {
+	event := services.AuditEvent{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IPAddress:  c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		Diff:       diff,
+	}
+	if userID, username, authenticated := middleware.GetUserInfoFromContext(c); authenticated {
+		event.ActorID = userID
+		event.ActorName = username
+	}
+	return event
+}
+
+// readUploadedImage multipart formdaki field alanından en fazla maxBytes baytlık bir görsel okur.
+// Hata durumunda yanıtı kendisi yazar ve false döner; boyut aşımında tooLargeMessage gösterilir.
+func readUploadedImage(c *gin.Context, field string, maxBytes int, tooLargeMessage string) ([]byte, bool) {
+	// Multipart sarmalayıcısı için biraz pay bırakılır; dosyanın kendisi aşağıda ayrıca kontrol edilir
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(maxBytes)+64<<10)
+	header, err := c.FormFile(field)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, http.StatusRequestEntityTooLarge, tooLargeMessage)
+			return nil, false
+		}
+		respondError(c, http.StatusBadRequest, "Please choose an image to upload.")
+		return nil, false
+	}
+	if header.Size > int64(maxBytes) {
+		respondError(c, http.StatusRequestEntityTooLarge, tooLargeMessage)
+		return nil, false
+	}
+	file, err := header.Open()
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Could not read the uploaded image.")
+		return nil, false
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, int64(maxBytes)+1))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Could not read the uploaded image.")
+		return nil, false
+	}
+	return data, true
+}
@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -15,22 +20,57 @@ import (
 	middleware "github.com/morgarakt/aurify/internal/middlewares"
 	"github.com/morgarakt/aurify/internal/models"
 	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/services"
+	"github.com/morgarakt/aurify/internal/utils"
 	"gorm.io/gorm"
 )
 
 type MusicHandler struct {
-	repo *repository.Repository
-	cfg  *config.Config
+	repo     *repository.Repository
+	cfg      *config.Config
+	services *services.Services
 }
 
-func NewMusicHandler(repo *repository.Repository, cfg *config.Config) *MusicHandler {
-	return &MusicHandler{repo: repo, cfg: cfg}
+func NewMusicHandler(repo *repository.Repository, cfg *config.Config, svc *services.Services) *MusicHandler {
+	return &MusicHandler{repo: repo, cfg: cfg, services: svc}
 }
 
 type UpdateMusicTitleRequest struct {
 	Title string `json:"title" binding:"required,min=1,max=200"`
 }
 
+// UpdateMusicMetadataRequest kısmi güncelleme içindir: gönderilmeyen (nil) alanlar değiştirilmez.
+type UpdateMusicMetadataRequest struct {
+	Title       *string  `json:"title"`
+	Description *string  `json:"description"`
+	Mood        *string  `json:"mood"`
+	License     *string  `json:"license"`
+	Tags        *TagList `json:"tags"`
+}
+
+// TagList JSON'da hem dizi (["lofi","chill"]) hem de virgülle ayrılmış metin ("lofi, chill") olarak kabul edilir;
+// ikincisi json-enc ile gönderilen HTML formları içindir.
+type TagList []string
+
+func (t *TagList) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*t = list
+		return nil
+	}
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return errors.New("tags must be an array or a comma separated string")
+	}
+	*t = TagList{}
+	for _, part := range strings.Split(raw, ",") {
+		if strings.TrimSpace(part) != "" {
+			*t = append(*t, part)
+		}
+	}
+	return nil
+}
+
 func (h *MusicHandler) GetMusicPage(c *gin.Context) {
 	musicIDStr := c.Param("id")
 	musicID, err := uuid.Parse(musicIDStr)
@@ -72,13 +112,13 @@ func (h *MusicHandler) GetMusicPage(c *gin.Context) {
 		hasLiked, _ = h.repo.UserLikes.HasUserLiked(requestingUserID, music.ID)
 	}
 
-	creatorUsername := "Anonymous"
+	creatorUsername, creatorURL := "Anonymous", ""
 	if music.User.ID != uuid.Nil {
-		creatorUsername = music.User.Username
+		creatorUsername, creatorURL = music.User.Username, profileURL(music.User.Username)
 	} else if music.UserID != nil {
 		var tempUser models.User
 		if h.repo.User.GetByID(*music.UserID, &tempUser) == nil {
-			creatorUsername = tempUser.Username
+			creatorUsername, creatorURL = tempUser.Username, profileURL(tempUser.Username)
 		}
 	}
 
@@ -91,10 +131,13 @@ func (h *MusicHandler) GetMusicPage(c *gin.Context) {
 		musicTitle = "Untitled Track"
 	}
 
+	metadata := musicMetadataView(music, isOwner)
+
 	musicDataForTemplate := gin.H{
 		"ID":                  music.ID.String(),
 		"Title":               musicTitle,
 		"Creator":             creatorUsername,
+		"CreatorURL":          creatorURL,
 		"MusicType":           music.MusicType.Name,
 		"ModelType":           music.ModelType.Name,
 		"CreationYear":        music.CreatedAt.Year(),
@@ -111,6 +154,7 @@ func (h *MusicHandler) GetMusicPage(c *gin.Context) {
 		"HasLiked":            hasLiked,
 		"IsDetailPageContext": true,
 		"CurrentURLPath":      c.Request.URL.RequestURI(), // Paylaşım butonu ve login to save redirect için
+		"Metadata":            metadata,
 	}
 
 	c.HTML(http.StatusOK, "music/detail.html", gin.H{
@@ -118,6 +162,7 @@ func (h *MusicHandler) GetMusicPage(c *gin.Context) {
 		"auth":     isAuthenticated,
 		"username": requestingUsername,
 		"Music":    musicDataForTemplate,
+		"Comments": h.commentsSectionData(music, commentViewerFromContext(c), url.QueryEscape(c.Request.URL.RequestURI())),
 	})
 }
 
@@ -269,6 +314,20 @@ func (h *MusicHandler) ToggleMusicVisibility(c *gin.Context) {
 	// Mevcut IsPublic durumunun tersini al
 	newIsPublicState := !music.IsPublic
 
+	// Doğrulanmamış hesaplar parça yayınlayamaz (gizliye almak her zaman serbest)
+	if newIsPublicState {
+		var owner models.User
+		if err := h.repo.User.GetByID(requestingUserID, &owner); err != nil {
+			log.Printf("Error loading user %s for visibility toggle: %v", requestingUserID, err)
+			respondError(c, http.StatusInternalServerError, "Failed to update visibility.")
+			return
+		}
+		if !owner.IsEmailVerified() {
+			respondError(c, http.StatusForbidden, "Verify your email address before publishing tracks.")
+			return
+		}
+	}
+
 	// Veritabanını yeni durumla güncelle
 	if err := h.repo.Music.UpdateVisibility(musicID, newIsPublicState); err != nil {
 		log.Printf("Error updating visibility for music %s to %t: %v", musicID, newIsPublicState, err)
@@ -279,6 +338,8 @@ func (h *MusicHandler) ToggleMusicVisibility(c *gin.Context) {
 	}
 
 	log.Printf("Visibility for music %s updated to %t by user %s", musicID, newIsPublicState, requestingUserID)
+	h.services.Audit.Record(auditEvent(c, models.AuditMusicVisibilityChange, "music", musicID.String(),
+		services.AuditDiff(gin.H{"is_public": music.IsPublic}, gin.H{"is_public": newIsPublicState})))
 
 	// Partial'ı render ederken YENİ durumu kullan
 	c.HTML(http.StatusOK, "partials/_visibility_toggle_partial.html", gin.H{
@@ -342,6 +403,7 @@ func (h *MusicHandler) UpdateMusicTitleHandler(c *gin.Context) {
 		return
 	}
 
+	previousTitle := existingMusic.Title
 	existingMusic.Title = req.Title
 	if errUpdate := h.repo.Music.Update(existingMusic); errUpdate != nil {
 		log.Printf("Error updating title for music %s: %v", musicID, errUpdate)
@@ -351,9 +413,427 @@ func (h *MusicHandler) UpdateMusicTitleHandler(c *gin.Context) {
 	}
 
 	log.Printf("Title for music %s updated to '%s' by user %s", musicID, req.Title, requestingUserID)
+	h.services.Audit.Record(auditEvent(c, models.AuditMusicTitleChange, "music", musicID.String(),
+		services.AuditDiff(gin.H{"title": previousTitle}, gin.H{"title": req.Title})))
 	c.String(http.StatusOK, req.Title)
 }
 
+// GetMusicWaveform, müziğin önceden hesaplanmış waveform peak verisini döner.
+// ?resolution= istenen peak sayısı (en yakın büyük çözünürlük seçilir), ?format=json|bin.
+func (h *MusicHandler) GetMusicWaveform(c *gin.Context) {
+	musicID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid music ID."})
+		return
+	}
+
+	music, err := h.repo.Music.GetByIDWithRelations(musicID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Music not found."})
+			return
+		}
+		log.Printf("Error fetching music %s for waveform: %v", musicID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not load waveform."})
+		return
+	}
+
+	requestingUserID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	isOwner := isAuthenticated && music.UserID != nil && *music.UserID == requestingUserID
+	if !music.IsPublic && !isOwner {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Music not found."})
+		return
+	}
+
+	resolution, err := strconv.Atoi(c.DefaultQuery("resolution", "256"))
+	if err != nil || resolution < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resolution."})
+		return
+	}
+	format := c.DefaultQuery("format", models.AssetFormatJSON)
+	if format != models.AssetFormatJSON && format != models.AssetFormatBinary {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be json or bin."})
+		return
+	}
+
+	asset, err := h.services.Waveform.FindWaveform(musicID, resolution, format)
+	if err != nil {
+		log.Printf("Error looking up waveform for music %s: %v", musicID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not load waveform."})
+		return
+	}
+	if asset == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Waveform not available."})
+		return
+	}
+	data, err := h.services.Waveform.ReadAsset(asset)
+	if err != nil {
+		log.Printf("Error reading waveform asset %s for music %s: %v", asset.StorageKey, musicID, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Waveform not available."})
+		return
+	}
+
+	if music.IsPublic {
+		c.Header("Cache-Control", "public, max-age=86400")
+	} else {
+		c.Header("Cache-Control", "private, max-age=3600")
+	}
+	c.Header("X-Waveform-Resolution", strconv.Itoa(asset.Resolution))
+	contentType := "application/json"
+	if format == models.AssetFormatBinary {
+		contentType = "application/octet-stream"
+	}
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// UpdateMusicMetadata, sahibinin başlık, açıklama, ruh hali, lisans ve etiketleri kısmi olarak güncellemesini sağlar.
+// HTMX isteklerine güncel metadata partial'ı, diğer isteklere JSON döner.
+func (h *MusicHandler) UpdateMusicMetadata(c *gin.Context) {
+	requestingUserID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+
+	musicID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid music ID.")
+		return
+	}
+
+	var req UpdateMusicMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("Update metadata request binding error for MusicID %s: %v", musicID, err)
+		respondError(c, http.StatusBadRequest, "Invalid request body.")
+		return
+	}
+
+	music, err := h.repo.Music.GetByIDWithRelations(musicID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Music not found.")
+		} else {
+			log.Printf("Error fetching music %s for metadata update: %v", musicID, err)
+			respondError(c, http.StatusInternalServerError, "Could not retrieve music details.")
+		}
+		return
+	}
+	if music.UserID == nil || *music.UserID != requestingUserID {
+		respondError(c, http.StatusForbidden, "You are not authorized to edit this track.")
+		return
+	}
+
+	fields := map[string]interface{}{}
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if n := utf8.RuneCountInString(title); n < 1 || n > 200 {
+			respondError(c, http.StatusUnprocessableEntity, "Title must be between 1 and 200 characters.")
+			return
+		}
+		fields["title"] = title
+	}
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		if utf8.RuneCountInString(description) > models.MaxDescriptionLen {
+			respondError(c, http.StatusUnprocessableEntity, fmt.Sprintf("Description cannot be longer than %d characters.", models.MaxDescriptionLen))
+			return
+		}
+		fields["description"] = description
+	}
+	if req.Mood != nil {
+		mood := strings.ToLower(strings.TrimSpace(*req.Mood))
+		if mood != "" && !slices.Contains(models.MusicMoods, mood) {
+			respondError(c, http.StatusUnprocessableEntity, "Unknown mood.")
+			return
+		}
+		fields["mood"] = mood
+	}
+	if req.License != nil {
+		license := strings.ToLower(strings.TrimSpace(*req.License))
+		if license != "" && !slices.Contains(models.MusicLicenses, license) {
+			respondError(c, http.StatusUnprocessableEntity, "Unknown license.")
+			return
+		}
+		fields["license"] = license
+	}
+
+	var tags *[]models.Tag
+	if req.Tags != nil {
+		names, invalid := normalizeTagNames(*req.Tags)
+		if invalid != "" {
+			respondError(c, http.StatusUnprocessableEntity, fmt.Sprintf("Invalid tag %q: use up to %d letters, digits or dashes.", invalid, models.MaxTagLength))
+			return
+		}
+		if len(names) > models.MaxTagsPerMusic {
+			respondError(c, http.StatusUnprocessableEntity, fmt.Sprintf("A track can have at most %d tags.", models.MaxTagsPerMusic))
+			return
+		}
+		found, err := h.repo.Tag.FindOrCreateByNames(names)
+		if err != nil {
+			log.Printf("Error resolving tags for music %s: %v", musicID, err)
+			respondError(c, http.StatusInternalServerError, "Failed to update tags.")
+			return
+		}
+		tags = &found
+	}
+
+	if err := h.repo.Music.UpdateMetadata(musicID, fields, tags); err != nil {
+		log.Printf("Error updating metadata for music %s: %v", musicID, err)
+		respondError(c, http.StatusInternalServerError, "Failed to update music details.")
+		return
+	}
+	log.Printf("Metadata for music %s updated by user %s", musicID, requestingUserID)
+	if title, ok := fields["title"]; ok && title != music.Title {
+		h.services.Audit.Record(auditEvent(c, models.AuditMusicTitleChange, "music", musicID.String(),
+			services.AuditDiff(gin.H{"title": music.Title}, gin.H{"title": title})))
+	}
+
+	updated, err := h.repo.Music.GetByIDWithRelations(musicID)
+	if err != nil {
+		log.Printf("Error refetching music %s after metadata update: %v", musicID, err)
+		respondError(c, http.StatusInternalServerError, "Details saved, but refreshing them failed.")
+		return
+	}
+
+	if !isHTMXRequest(c) {
+		tagNames := make([]string, len(updated.Tags))
+		for i, t := range updated.Tags {
+			tagNames[i] = t.Name
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"id":               updated.ID.String(),
+			"title":            updated.Title,
+			"description":      updated.Description,
+			"description_html": utils.RenderMarkdown(updated.Description),
+			"mood":             updated.Mood,
+			"license":          updated.License,
+			"tags":             tagNames,
+		})
+		return
+	}
+
+	c.Header("HX-Trigger", `{"showNotification": {"type": "success", "message": "Track details saved."}}`)
+	c.HTML(http.StatusOK, "partials/_music_metadata_partial.html", musicMetadataView(updated, true))
+}
+
+// normalizeTagNames etiketleri normalize eder ve tekrarları atar. Geçersiz bir etiket varsa onu döner.
+func normalizeTagNames(raw []string) ([]string, string) {
+	names := make([]string, 0, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for _, r := range raw {
+		name, ok := models.NormalizeTagName(r)
+		if !ok {
+			return nil, r
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, ""
+}
+
+// musicMetadataView detay sayfasındaki açıklama/etiket bölümünün ve düzenleme formunun verisini hazırlar.
+func musicMetadataView(music *models.Music, isOwner bool) gin.H {
+	tagNames := make([]string, len(music.Tags))
+	for i, t := range music.Tags {
+		tagNames[i] = t.Name
+	}
+	return gin.H{
+		"MusicID":         music.ID.String(),
+		"Description":     music.Description,
+		"DescriptionHTML": utils.RenderMarkdown(music.Description),
+		"Mood":            music.Mood,
+		"License":         music.License,
+		"Tags":            tagNames,
+		"TagsString":      strings.Join(tagNames, ", "),
+		"IsOwner":         isOwner,
+		"Moods":           models.MusicMoods,
+		"Licenses":        models.MusicLicenses,
+		"MaxDescription":  models.MaxDescriptionLen,
+	}
+}
+
+// DeleteMusic, müziği çöp kutusuna taşır. Parça geri alma süresi boyunca
+// "Recently Deleted" sayfasından geri yüklenebilir, süre dolunca purge job'u kalıcı olarak siler.
+func (h *MusicHandler) DeleteMusic(c *gin.Context) {
+	requestingUserID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+
+	musicID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid music ID.")
+		return
+	}
+
+	music, err := h.repo.Music.GetByIDWithRelations(musicID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Music not found.")
+		} else {
+			log.Printf("Error fetching music %s for delete: %v", musicID, err)
+			respondError(c, http.StatusInternalServerError, "Could not retrieve music details.")
+		}
+		return
+	}
+
+	if music.UserID == nil || *music.UserID != requestingUserID {
+		respondError(c, http.StatusForbidden, "You are not authorized to delete this track.")
+		return
+	}
+
+	if err := h.repo.Music.SoftDelete(musicID); err != nil {
+		log.Printf("Error soft deleting music %s: %v", musicID, err)
+		respondError(c, http.StatusInternalServerError, "Failed to delete music.")
+		return
+	}
+	log.Printf("Music %s moved to trash by user %s", musicID, requestingUserID)
+	h.services.Audit.Record(auditEvent(c, models.AuditMusicDelete, "music", musicID.String(), gin.H{"title": music.Title}))
+
+	restorableUntil := time.Now().Add(h.services.Purger.Retention())
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{
+			"id":               musicID.String(),
+			"deleted":          true,
+			"restorable_until": restorableUntil,
+		})
+		return
+	}
+
+	// Detay sayfasında silinen parçanın sayfası artık açılamaz, kütüphaneye dön.
+	if c.Query("context") == "detail" {
+		c.Header("HX-Redirect", "/library?success=delete_ok")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	title := music.Title
+	if title == "" {
+		title = "Untitled Track"
+	}
+	c.HTML(http.StatusOK, "partials/_deleted_music_partial.html", gin.H{
+		"ID":            musicID.String(),
+		"Title":         title,
+		"RetentionDays": retentionDays(h.services.Purger.Retention()),
+	})
+}
+
+// RestoreMusic, çöp kutusundaki bir müziği geri alma süresi dolmadıysa geri yükler.
+func (h *MusicHandler) RestoreMusic(c *gin.Context) {
+	requestingUserID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+
+	musicID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid music ID.")
+		return
+	}
+
+	music, ok := h.getOwnedDeletedMusic(c, musicID, requestingUserID)
+	if !ok {
+		return
+	}
+
+	if music.DeletedAt.Time.Before(h.services.Purger.RestorableSince()) {
+		respondError(c, http.StatusGone, "The restore window for this track has expired.")
+		return
+	}
+
+	if err := h.repo.Music.Restore(musicID); err != nil {
+		log.Printf("Error restoring music %s: %v", musicID, err)
+		respondError(c, http.StatusInternalServerError, "Failed to restore music.")
+		return
+	}
+	log.Printf("Music %s restored by user %s", musicID, requestingUserID)
+	h.services.Audit.Record(auditEvent(c, models.AuditMusicRestore, "music", musicID.String(), gin.H{"title": music.Title}))
+
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"id": musicID.String(), "restored": true})
+		return
+	}
+
+	title := music.Title
+	if title == "" {
+		title = "Untitled Track"
+	}
+	c.HTML(http.StatusOK, "partials/_restored_music_partial.html", gin.H{
+		"ID":    musicID.String(),
+		"Title": title,
+	})
+}
+
+// PurgeDeletedMusic, çöp kutusundaki bir müziği süre dolmasını beklemeden kalıcı olarak siler.
+func (h *MusicHandler) PurgeDeletedMusic(c *gin.Context) {
+	requestingUserID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+
+	musicID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid music ID.")
+		return
+	}
+
+	music, ok := h.getOwnedDeletedMusic(c, musicID, requestingUserID)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Purger.PurgeMusic(music); err != nil {
+		log.Printf("Error permanently deleting music %s: %v", musicID, err)
+		respondError(c, http.StatusInternalServerError, "Failed to delete music permanently.")
+		return
+	}
+	log.Printf("Music %s permanently deleted by user %s", musicID, requestingUserID)
+	h.services.Audit.Record(auditEvent(c, models.AuditMusicPurge, "music", musicID.String(), gin.H{"title": music.Title}))
+
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"id": musicID.String(), "purged": true})
+		return
+	}
+	// Satır outerHTML ile boş içerikle değiştirilerek listeden kaldırılır.
+	c.Header("HX-Trigger", `{"showNotification": {"type": "success", "message": "Track permanently deleted."}}`)
+	c.String(http.StatusOK, "")
+}
+
+// getOwnedDeletedMusic çöp kutusundaki müziği getirir ve isteği yapan kullanıcıya ait olduğunu doğrular.
+// Hata durumunda yanıtı yazar ve false döner.
+func (h *MusicHandler) getOwnedDeletedMusic(c *gin.Context, musicID, userID uuid.UUID) (*models.Music, bool) {
+	music, err := h.repo.Music.GetDeletedByID(musicID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Deleted music not found.")
+		} else {
+			log.Printf("Error fetching deleted music %s: %v", musicID, err)
+			respondError(c, http.StatusInternalServerError, "Could not retrieve music details.")
+		}
+		return nil, false
+	}
+	// Başkasının çöp kutusundaki parçanın varlığı da açığa çıkmamalı.
+	if music.UserID == nil || *music.UserID != userID {
+		respondError(c, http.StatusNotFound, "Deleted music not found.")
+		return nil, false
+	}
+	return music, true
+}
+
+// retentionDays geri alma süresini tam gün olarak döner (en az 1).
+func retentionDays(d time.Duration) int {
+	days := int(math.Ceil(d.Hours() / 24))
+	if days < 1 {
+		return 1
+	}
+	return days
+}
+
 func (h *MusicHandler) renderNotFound(c *gin.Context) {
 	_, username, auth := middleware.GetUserInfoFromContext(c)
 	c.HTML(http.StatusNotFound, "error/notfound.html", gin.H{
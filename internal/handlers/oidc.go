@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/services"
+	"github.com/morgarakt/aurify/internal/utils"
+)
+
+// loginPageData giriş sayfasının ortak verileridir; OIDC açıksa sağlayıcı butonu gösterilir.
+func loginPageData(svc *services.Services, errorMessage string) gin.H {
+	data := gin.H{"title": "Login to Aurify", "auth": false}
+	if svc.OIDC != nil {
+		data["oidcProvider"] = svc.OIDC.ProviderName()
+	}
+	if errorMessage != "" {
+		data["error"] = errorMessage
+	}
+	return data
+}
+
+// renderOIDCError OIDC dönüşünde oluşan hatayı giriş sayfasında gösterir.
+func (h *AuthHandler) renderOIDCError(c *gin.Context, status int, message string) {
+	c.HTML(status, "auth/login.html", loginPageData(h.services, message))
+}
+
+// OIDCLogin state, nonce ve PKCE verifier'ı imzalı çereze yazıp kullanıcıyı kimlik sağlayıcısına yönlendirir.
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	if h.services.OIDC == nil {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+	state, err := utils.NewOIDCLoginState()
+	if err != nil {
+		log.Printf("Generating OIDC state failed: %v", err)
+		h.renderOIDCError(c, http.StatusInternalServerError, "Could not start single sign-on. Please try again.")
+		return
+	}
+	authURL, err := h.services.OIDC.AuthCodeURL(c.Request.Context(), state)
+	if err != nil {
+		log.Printf("Building OIDC authorization URL failed: %v", err)
+		h.renderOIDCError(c, http.StatusBadGateway, "The identity provider is not reachable right now. Please try again later.")
+		return
+	}
+	if err := utils.SetOIDCLoginState(c, state, h.cfg.JWTSecret); err != nil {
+		log.Printf("Storing OIDC state failed: %v", err)
+		h.renderOIDCError(c, http.StatusInternalServerError, "Could not start single sign-on. Please try again.")
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback sağlayıcıdan dönen kodu ID token ile değiştirir, hesabı bulur (gerekirse bağlar ya da oluşturur)
+// ve oturumu açar. Hesapta 2FA açıksa şifreli girişteki gibi kod adımına yönlendirilir.
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	if h.services.OIDC == nil {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+	state, err := utils.ConsumeOIDCLoginState(c, h.cfg.JWTSecret, c.Query("state"))
+	if err != nil {
+		h.renderOIDCError(c, http.StatusBadRequest, "Your sign-in attempt has expired. Please try again.")
+		return
+	}
+	if providerError := c.Query("error"); providerError != "" {
+		log.Printf("OIDC provider returned error %q: %s", providerError, c.Query("error_description"))
+		h.renderOIDCError(c, http.StatusUnauthorized, "Sign-in was cancelled or denied by the identity provider.")
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		h.renderOIDCError(c, http.StatusBadRequest, "The identity provider did not return an authorization code.")
+		return
+	}
+
+	claims, err := h.services.OIDC.Exchange(c.Request.Context(), code, state)
+	if err != nil {
+		log.Printf("OIDC code exchange failed: %v", err)
+		h.renderOIDCError(c, http.StatusUnauthorized, "Could not verify your sign-in with the identity provider.")
+		return
+	}
+
+	user, match, err := h.services.OIDC.ResolveUser(claims)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOIDCEmailMissing):
+			h.renderOIDCError(c, http.StatusForbidden, "The identity provider did not share your email address.")
+		case errors.Is(err, services.ErrOIDCEmailUnverified):
+			h.renderOIDCError(c, http.StatusForbidden, "An account with this email already exists. Verify your email with the identity provider or sign in with your password.")
+		case errors.Is(err, services.ErrOIDCAccountUnverified):
+			h.renderOIDCError(c, http.StatusForbidden, "An account with this email already exists but is not verified. Sign in with your password and verify your email first.")
+		default:
+			log.Printf("Resolving OIDC user (iss=%s sub=%s) failed: %v", claims.Issuer, claims.Subject, err)
+			h.renderOIDCError(c, http.StatusInternalServerError, "Could not sign you in. Please try again.")
+		}
+		return
+	}
+
+	identity := gin.H{"method": "oidc", "issuer": claims.Issuer, "subject": claims.Subject}
+	switch match {
+	case services.OIDCUserLinked:
+		log.Printf("Linked OIDC identity (iss=%s sub=%s) to user %s", claims.Issuer, claims.Subject, user.ID)
+		h.services.Audit.Record(userAuditEvent(c, models.AuditIdentityLinked, user, identity))
+	case services.OIDCUserCreated:
+		h.services.Audit.Record(userAuditEvent(c, models.AuditRegister, user, gin.H{"email": user.Email, "role": user.Role, "issuer": claims.Issuer}))
+		if !user.IsEmailVerified() {
+			if err := h.services.EmailVerification.SendVerification(user); err != nil {
+				log.Printf("Sending verification email to new user %s failed: %v", user.ID, err)
+			}
+		}
+	}
+
+	if user.IsSuspended() {
+		log.Printf("OIDC login rejected for suspended user %s", user.ID)
+		h.recordLoginFailure(c, user.Email, user, "suspended")
+		h.renderOIDCError(c, http.StatusForbidden, "Hesabınız askıya alınmış. Lütfen destek ile iletişime geçin.")
+		return
+	}
+
+	if user.TwoFactorEnabled() {
+		if _, err := utils.IssueTwoFactorChallenge(c, user.ID, h.cfg.JWTSecret, h.cfg.TwoFactorChallengeTTL); err != nil {
+			log.Printf("Issuing 2FA challenge for user %s failed: %v", user.ID, err)
+			h.renderOIDCError(c, http.StatusInternalServerError, "Oturum başlatılamadı.")
+			return
+		}
+		c.Redirect(http.StatusFound, "/login/2fa")
+		return
+	}
+
+	redirectURL, err := h.startSession(c, user, identity)
+	if err != nil {
+		log.Printf("Starting session after OIDC login for user %s failed: %v", user.ID, err)
+		h.renderOIDCError(c, http.StatusInternalServerError, "Oturum başlatılamadı.")
+		return
+	}
+	c.Redirect(http.StatusFound, redirectURL)
+}
@@ -0,0 +1,550 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/config"
+	middleware "github.com/morgarakt/aurify/internal/middlewares"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/services"
+	"gorm.io/gorm"
+)
+
+type PlaylistHandler struct {
+	repo     *repository.Repository
+	cfg      *config.Config
+	services *services.Services
+}
+
+func NewPlaylistHandler(repo *repository.Repository, cfg *config.Config, svc *services.Services) *PlaylistHandler {
+	return &PlaylistHandler{repo: repo, cfg: cfg, services: svc}
+}
+
+type PlaylistRequest struct {
+	Title       string `json:"title" form:"title"`
+	Description string `json:"description" form:"description"`
+	Visibility  string `json:"visibility" form:"visibility"`
+	// Sadece oluştururken: "listeye ekle" penceresinden gelen parça yeni listeye hemen eklenir
+	MusicID string `json:"music_id" form:"music_id"`
+}
+
+type PlaylistTrackRequest struct {
+	MusicID string `json:"music_id" form:"music_id" binding:"required"`
+}
+
+type ReorderPlaylistRequest struct {
+	// Virgülle ayrılmış liste parçası ID'leri, yeni sırasıyla
+	EntryIDs string `json:"entry_ids" form:"entry_ids" binding:"required"`
+}
+
+// MyPlaylists kullanıcının tüm çalma listelerini ve yeni liste formunu gösterir.
+func (h *PlaylistHandler) MyPlaylists(c *gin.Context) {
+	userID, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		c.Redirect(http.StatusSeeOther, "/login?redirect=/playlists")
+		return
+	}
+	summaries, err := h.repo.Playlist.ListByUser(userID, userID, false)
+	if err != nil {
+		log.Printf("Error listing playlists of user %s: %v", userID, err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load your playlists."})
+		return
+	}
+	c.HTML(http.StatusOK, "playlists/index.html", gin.H{
+		"title":        "Your Playlists - Aurify",
+		"auth":         isAuthenticated,
+		"username":     username,
+		"Playlists":    playlistCards(h.services.Playlists, summaries),
+		"Visibilities": models.PlaylistVisibilities,
+	})
+}
+
+// PlaylistPage listenin detay sayfasıdır. Gizli listeler sadece sahibine açılır; listedeki başkasına ait
+// gizli ya da silinmiş parçalar kimseye, sahibin kendi gizli parçaları sadece sahibine gösterilir.
+func (h *PlaylistHandler) PlaylistPage(c *gin.Context) {
+	userID, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	playlist, ok := h.loadViewablePlaylist(c, userID)
+	if !ok {
+		if !c.Writer.Written() {
+			c.HTML(http.StatusNotFound, "error/notfound.html", gin.H{"title": "Sayfa Bulunamadı - Aurify", "auth": isAuthenticated, "username": username})
+		}
+		return
+	}
+	entries, err := h.repo.Playlist.ListEntries(playlist.ID, userID)
+	if err != nil {
+		log.Printf("Error listing entries of playlist %s: %v", playlist.ID, err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load this playlist."})
+		return
+	}
+	isOwner := isAuthenticated && playlist.UserID == userID
+	rows := playlistEntryRows(entries, userID)
+
+	c.HTML(http.StatusOK, "playlists/detail.html", gin.H{
+		"title":        playlist.Title + " - Aurify",
+		"auth":         isAuthenticated,
+		"username":     username,
+		"Header":       h.playlistHeader(playlist, rows, isOwner),
+		"Entries":      rows,
+		"IsOwner":      isOwner,
+		"Visibilities": models.PlaylistVisibilities,
+	})
+}
+
+// CreatePlaylist yeni bir liste oluşturur. "Listeye ekle" penceresinden music_id ile gelindiyse parça
+// listeye eklenir ve pencere yeniden çizilir; aksi halde HTMX istekleri yeni listenin sayfasına yönlendirilir.
+func (h *PlaylistHandler) CreatePlaylist(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in to create playlists.")
+		return
+	}
+	var req PlaylistRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid playlist.")
+		return
+	}
+	var musicID uuid.UUID
+	if req.MusicID != "" {
+		var err error
+		if musicID, err = uuid.Parse(req.MusicID); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid music ID.")
+			return
+		}
+	}
+
+	playlist, err := h.services.Playlists.Create(userID, services.PlaylistDetails{
+		Title: req.Title, Description: req.Description, Visibility: req.Visibility,
+	})
+	if err != nil {
+		respondPlaylistError(c, err, "Could not create the playlist.")
+		return
+	}
+	log.Printf("User %s created playlist %s", userID, playlist.ID)
+
+	if musicID != uuid.Nil {
+		if err := h.services.Playlists.AddTrack(playlist, musicID); err != nil {
+			respondPlaylistError(c, err, "The playlist was created, but the track could not be added.")
+			return
+		}
+		if isHTMXRequest(c) {
+			notifySuccess(c, fmt.Sprintf("Added to %s.", playlist.Title))
+			h.renderPicker(c, userID, musicID)
+			return
+		}
+	}
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusCreated, playlistJSON(playlist))
+		return
+	}
+	c.Header("HX-Redirect", "/playlists/"+playlist.ID.String())
+	c.Status(http.StatusCreated)
+}
+
+// UpdatePlaylist başlık, açıklama ve görünürlüğü günceller.
+func (h *PlaylistHandler) UpdatePlaylist(c *gin.Context) {
+	userID, playlist, ok := h.loadOwnedPlaylist(c)
+	if !ok {
+		return
+	}
+	var req PlaylistRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid playlist.")
+		return
+	}
+	if err := h.services.Playlists.Update(playlist, services.PlaylistDetails{
+		Title: req.Title, Description: req.Description, Visibility: req.Visibility,
+	}); err != nil {
+		respondPlaylistError(c, err, "Could not save the playlist.")
+		return
+	}
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, playlistJSON(playlist))
+		return
+	}
+	notifySuccess(c, "Playlist saved.")
+	h.renderHeader(c, playlist, userID)
+}
+
+// DeletePlaylist listeyi kalıcı olarak siler; parçaların kendisi etkilenmez.
+func (h *PlaylistHandler) DeletePlaylist(c *gin.Context) {
+	userID, playlist, ok := h.loadOwnedPlaylist(c)
+	if !ok {
+		return
+	}
+	if err := h.services.Playlists.Delete(playlist); err != nil {
+		log.Printf("Error deleting playlist %s: %v", playlist.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not delete the playlist.")
+		return
+	}
+	log.Printf("User %s deleted playlist %s", userID, playlist.ID)
+	if !isHTMXRequest(c) {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.Header("HX-Redirect", "/playlists?success=playlist_deleted")
+	c.Status(http.StatusOK)
+}
+
+// UploadPlaylistCover listeye kendi kapak görselini yükler.
+func (h *PlaylistHandler) UploadPlaylistCover(c *gin.Context) {
+	userID, playlist, ok := h.loadOwnedPlaylist(c)
+	if !ok {
+		return
+	}
+	maxBytes := h.services.Playlists.CoverMaxBytes()
+	data, ok := readUploadedImage(c, "cover", maxBytes, coverTooLargeMessage(maxBytes))
+	if !ok {
+		return
+	}
+
+	if err := h.services.Playlists.SetCover(playlist, data); err != nil {
+		switch {
+		case errors.Is(err, services.ErrPlaylistCoverTooLarge):
+			respondError(c, http.StatusRequestEntityTooLarge, coverTooLargeMessage(maxBytes))
+		case errors.Is(err, services.ErrPlaylistCoverInvalid):
+			respondError(c, http.StatusUnsupportedMediaType, "Covers must be PNG, JPEG or GIF images up to 4096x4096 pixels.")
+		default:
+			log.Printf("Error saving cover of playlist %s: %v", playlist.ID, err)
+			respondError(c, http.StatusInternalServerError, "Could not save the cover.")
+		}
+		return
+	}
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, playlistJSON(playlist))
+		return
+	}
+	notifySuccess(c, "Cover updated.")
+	h.renderHeader(c, playlist, userID)
+}
+
+// DeletePlaylistCover kendi kapağı kaldırır; liste yeniden ilk parçanın kapağını kullanır.
+func (h *PlaylistHandler) DeletePlaylistCover(c *gin.Context) {
+	userID, playlist, ok := h.loadOwnedPlaylist(c)
+	if !ok {
+		return
+	}
+	if err := h.services.Playlists.RemoveCover(playlist); err != nil {
+		log.Printf("Error removing cover of playlist %s: %v", playlist.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not remove the cover.")
+		return
+	}
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, playlistJSON(playlist))
+		return
+	}
+	notifySuccess(c, "Cover removed.")
+	h.renderHeader(c, playlist, userID)
+}
+
+// AddPlaylistTrack parçayı listenin sonuna ekler. ?context=picker ile "listeye ekle" penceresi yeniden çizilir.
+func (h *PlaylistHandler) AddPlaylistTrack(c *gin.Context) {
+	userID, playlist, ok := h.loadOwnedPlaylist(c)
+	if !ok {
+		return
+	}
+	var req PlaylistTrackRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Please choose a track.")
+		return
+	}
+	musicID, err := uuid.Parse(req.MusicID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid music ID.")
+		return
+	}
+	if err := h.services.Playlists.AddTrack(playlist, musicID); err != nil {
+		respondPlaylistError(c, err, "Could not add the track.")
+		return
+	}
+	if !isHTMXRequest(c) {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	notifySuccess(c, fmt.Sprintf("Added to %s.", playlist.Title))
+	h.renderPicker(c, userID, musicID)
+}
+
+// RemovePlaylistTrack parçayı listeden çıkarır. Detay sayfasından gelen isteklerde satır boş yanıtla
+// kaldırılır; ?context=picker ile "listeye ekle" penceresi yeniden çizilir.
+func (h *PlaylistHandler) RemovePlaylistTrack(c *gin.Context) {
+	userID, playlist, ok := h.loadOwnedPlaylist(c)
+	if !ok {
+		return
+	}
+	musicID, err := uuid.Parse(c.Param("musicID"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid music ID.")
+		return
+	}
+	removed, err := h.repo.Playlist.RemoveEntry(playlist.ID, musicID)
+	if err != nil {
+		log.Printf("Error removing music %s from playlist %s: %v", musicID, playlist.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not remove the track.")
+		return
+	}
+	if !removed {
+		respondError(c, http.StatusNotFound, "This track is not in the playlist.")
+		return
+	}
+	if !isHTMXRequest(c) {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	notifySuccess(c, fmt.Sprintf("Removed from %s.", playlist.Title))
+	if c.Query("context") == "picker" {
+		h.renderPicker(c, userID, musicID)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// ReorderPlaylist sürükle-bırak sonrası sırayı kaydeder.
+func (h *PlaylistHandler) ReorderPlaylist(c *gin.Context) {
+	_, playlist, ok := h.loadOwnedPlaylist(c)
+	if !ok {
+		return
+	}
+	var req ReorderPlaylistRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid order.")
+		return
+	}
+	var entryIDs []uuid.UUID
+	for _, raw := range strings.Split(req.EntryIDs, ",") {
+		id, err := uuid.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid order.")
+			return
+		}
+		entryIDs = append(entryIDs, id)
+	}
+	if err := h.repo.Playlist.Reorder(playlist.ID, entryIDs); err != nil {
+		log.Printf("Error reordering playlist %s: %v", playlist.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not save the new order.")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetPlaylistPicker bir parça için "listeye ekle" penceresini döner.
+func (h *PlaylistHandler) GetPlaylistPicker(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in to use playlists.")
+		return
+	}
+	musicID, err := uuid.Parse(c.Query("music_id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid music ID.")
+		return
+	}
+	h.renderPicker(c, userID, musicID)
+}
+
+func (h *PlaylistHandler) renderPicker(c *gin.Context, userID, musicID uuid.UUID) {
+	items, err := h.repo.Playlist.ListForPicker(userID, musicID)
+	if err != nil {
+		log.Printf("Error listing playlists of user %s for picker: %v", userID, err)
+		respondError(c, http.StatusInternalServerError, "Could not load your playlists.")
+		return
+	}
+	rows := make([]gin.H, 0, len(items))
+	for _, item := range items {
+		rows = append(rows, gin.H{
+			"ID":         item.ID.String(),
+			"Title":      item.Title,
+			"Visibility": item.Visibility,
+			"Contains":   item.Contains,
+		})
+	}
+	c.HTML(http.StatusOK, "partials/_playlist_picker.html", gin.H{
+		"MusicID":   musicID.String(),
+		"Playlists": rows,
+	})
+}
+
+func (h *PlaylistHandler) renderHeader(c *gin.Context, playlist *models.Playlist, userID uuid.UUID) {
+	entries, err := h.repo.Playlist.ListEntries(playlist.ID, userID)
+	if err != nil {
+		log.Printf("Error listing entries of playlist %s: %v", playlist.ID, err)
+	}
+	c.HTML(http.StatusOK, "partials/_playlist_header.html", h.playlistHeader(playlist, playlistEntryRows(entries, userID), true))
+}
+
+// loadViewablePlaylist :id listesini yükler; liste yoksa, gizliyse ya da sahibi askıdaysa (sahibin kendisi
+// hariç) bulunamadı sayılır. Beklenmeyen hatalarda yanıt yazılır.
+func (h *PlaylistHandler) loadViewablePlaylist(c *gin.Context, userID uuid.UUID) (*models.Playlist, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, false
+	}
+	playlist, err := h.repo.Playlist.GetByID(id)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Error loading playlist %s: %v", id, err)
+			c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load this playlist."})
+		}
+		return nil, false
+	}
+	if !playlist.IsViewableBy(userID) || (playlist.UserID != userID && playlist.User.IsSuspended()) {
+		return nil, false
+	}
+	return playlist, true
+}
+
+// loadOwnedPlaylist değişiklik isteklerinde :id listesini yükler ve isteği yapanın sahibi olduğunu doğrular.
+// Başkasının gizli listesi için varlığı sızdırılmasın diye bulunamadı döner.
+func (h *PlaylistHandler) loadOwnedPlaylist(c *gin.Context) (uuid.UUID, *models.Playlist, bool) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return uuid.Nil, nil, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid playlist ID.")
+		return uuid.Nil, nil, false
+	}
+	playlist, err := h.repo.Playlist.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Playlist not found.")
+		} else {
+			log.Printf("Error loading playlist %s: %v", id, err)
+			respondError(c, http.StatusInternalServerError, "Could not load the playlist.")
+		}
+		return uuid.Nil, nil, false
+	}
+	if playlist.UserID != userID {
+		if playlist.IsViewableBy(userID) {
+			respondError(c, http.StatusForbidden, "You can only change your own playlists.")
+		} else {
+			respondError(c, http.StatusNotFound, "Playlist not found.")
+		}
+		return uuid.Nil, nil, false
+	}
+	return userID, playlist, true
+}
+
+func (h *PlaylistHandler) playlistHeader(playlist *models.Playlist, rows []gin.H, isOwner bool) gin.H {
+	coverURL := h.services.Playlists.CoverURL(playlist)
+	hasOwnCover := coverURL != ""
+	if !hasOwnCover {
+		for _, row := range rows {
+			if cover, _ := row["CoverArtPath"].(string); cover != "" {
+				coverURL = cover
+				break
+			}
+		}
+	}
+	return gin.H{
+		"ID":          playlist.ID.String(),
+		"Title":       playlist.Title,
+		"Description": playlist.Description,
+		"Visibility":  playlist.Visibility,
+		"Owner":       playlist.User.Username,
+		"OwnerURL":    profileURL(playlist.User.Username),
+		"CoverURL":    coverURL,
+		"HasOwnCover": hasOwnCover,
+		"TrackCount":  len(rows),
+		"UpdatedAt":   playlist.UpdatedAt.Format("02 Jan 2006"),
+		"IsOwner":     isOwner,
+	}
+}
+
+// playlistEntryRows liste satırlarını hazırlar. OnlyYou, sahibin listede kendi gizli parçasını
+// gördüğünü belirtir; bu parça diğer ziyaretçilere gösterilmez.
+func playlistEntryRows(entries []models.PlaylistEntry, viewerID uuid.UUID) []gin.H {
+	rows := make([]gin.H, 0, len(entries))
+	for i, e := range entries {
+		m := e.Music
+		title := m.Title
+		if title == "" {
+			title = "Untitled Track"
+		}
+		creator, creatorURL := "Anonymous", ""
+		if m.User.ID != uuid.Nil && m.User.Username != "" {
+			creator, creatorURL = m.User.Username, profileURL(m.User.Username)
+		}
+		rows = append(rows, gin.H{
+			"EntryID":      e.ID.String(),
+			"MusicID":      m.ID.String(),
+			"Number":       i + 1,
+			"Title":        title,
+			"Creator":      creator,
+			"CreatorURL":   creatorURL,
+			"MusicType":    m.MusicType.Name,
+			"CoverArtPath": m.CoverArtPath,
+			"Mp3FilePath":  m.Mp3FilePath,
+			"OnlyYou":      !m.IsPublic && m.UserID != nil && *m.UserID == viewerID,
+		})
+	}
+	return rows
+}
+
+// playlistCards liste kartlarını hazırlar; kendi kapağı olmayan listeler ilk görünür parçanın kapağını kullanır.
+func playlistCards(svc *services.PlaylistService, summaries []repository.PlaylistSummary) []gin.H {
+	cards := make([]gin.H, 0, len(summaries))
+	for i := range summaries {
+		s := &summaries[i]
+		coverURL := svc.CoverURL(&s.Playlist)
+		if coverURL == "" {
+			coverURL = s.FirstCover
+		}
+		cards = append(cards, gin.H{
+			"ID":         s.ID.String(),
+			"Title":      s.Title,
+			"Visibility": s.Visibility,
+			"CoverURL":   coverURL,
+			"TrackCount": s.EntryCount,
+			"URL":        "/playlists/" + s.ID.String(),
+		})
+	}
+	return cards
+}
+
+func playlistJSON(playlist *models.Playlist) gin.H {
+	return gin.H{
+		"id":          playlist.ID.String(),
+		"title":       playlist.Title,
+		"description": playlist.Description,
+		"visibility":  playlist.Visibility,
+		"has_cover":   playlist.CoverKey != "",
+		"created_at":  playlist.CreatedAt,
+		"updated_at":  playlist.UpdatedAt,
+	}
+}
+
+func respondPlaylistError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrPlaylistTitleRequired):
+		respondError(c, http.StatusBadRequest, "Please enter a playlist title.")
+	case errors.Is(err, services.ErrPlaylistTitleTooLong):
+		respondError(c, http.StatusBadRequest, "Playlist titles can be at most 100 characters.")
+	case errors.Is(err, services.ErrPlaylistDescriptionTooLong):
+		respondError(c, http.StatusBadRequest, "Playlist descriptions can be at most 1000 characters.")
+	case errors.Is(err, services.ErrPlaylistVisibility):
+		respondError(c, http.StatusBadRequest, "Please choose public, unlisted or private.")
+	case errors.Is(err, repository.ErrPlaylistEntryExists):
+		respondError(c, http.StatusConflict, "This track is already in the playlist.")
+	case errors.Is(err, services.ErrPlaylistTrackUnavailable), errors.Is(err, gorm.ErrRecordNotFound):
+		respondError(c, http.StatusNotFound, "Music not found.")
+	default:
+		log.Printf("Playlist request failed: %v", err)
+		respondError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+func coverTooLargeMessage(maxBytes int) string {
+	if maxBytes >= 1<<20 {
+		return fmt.Sprintf("Cover images can be at most %.1f MB.", float64(maxBytes)/(1<<20))
+	}
+	return fmt.Sprintf("Cover images can be at most %d KB.", maxBytes>>10)
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	middleware "github.com/morgarakt/aurify/internal/middlewares"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/services"
+	"github.com/morgarakt/aurify/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type RequestDataExportRequest struct {
+	IncludeFiles bool `json:"include_files" form:"include_files"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" form:"password" binding:"required"`
+	// 2FA açıksa zorunludur; kurtarma kodu da kabul edilir
+	Code string `json:"code" form:"code"`
+	// Yanlışlıkla silmeye karşı kullanıcı adının aynen yazılması beklenir
+	Confirm string `json:"confirm" form:"confirm" binding:"required"`
+	Policy  string `json:"policy" form:"policy" binding:"required"`
+}
+
+// Privacy veri dışa aktarma ve hesap silme işlemlerinin bulunduğu ayarlar sayfasıdır.
+func (h *SettingsHandler) Privacy(c *gin.Context) {
+	userID, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		c.Redirect(http.StatusSeeOther, "/login?redirect=/settings/privacy")
+		return
+	}
+	var user models.User
+	if err := h.repo.User.GetByID(userID, &user); err != nil {
+		log.Printf("Error loading user %s for privacy settings: %v", userID, err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load your account."})
+		return
+	}
+	exports, inProgress, err := h.dataExportRows(userID)
+	if err != nil {
+		log.Printf("Error listing data exports for user %s: %v", userID, err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load your data exports."})
+		return
+	}
+
+	c.HTML(http.StatusOK, "settings/privacy.html", gin.H{
+		"title":            "Privacy - Aurify",
+		"auth":             isAuthenticated,
+		"username":         username,
+		"Username":         user.Username,
+		"TwoFactorEnabled": user.TwoFactorEnabled(),
+		"Exports":          exports,
+		"InProgress":       inProgress,
+		"ExportTTLDays":    int(h.cfg.DataExportTTL / (24 * time.Hour)),
+	})
+}
+
+// ListDataExports kullanıcının son dışa aktarmalarını döner. HTMX isteklerinde liste parçası döner;
+// hazırlanan bir arşiv varsa parça kendini birkaç saniyede bir yeniler.
+func (h *SettingsHandler) ListDataExports(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+	exports, inProgress, err := h.dataExportRows(userID)
+	if err != nil {
+		log.Printf("Error listing data exports for user %s: %v", userID, err)
+		respondError(c, http.StatusInternalServerError, "Could not load your data exports.")
+		return
+	}
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"exports": exports})
+		return
+	}
+	c.HTML(http.StatusOK, "partials/_data_export_list.html", gin.H{"Exports": exports, "InProgress": inProgress})
+}
+
+// RequestDataExport yeni bir veri dışa aktarma işini kuyruğa ekler; arşiv hazır olunca e-posta gönderilir.
+func (h *SettingsHandler) RequestDataExport(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+	var req RequestDataExportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid export request.")
+		return
+	}
+
+	export, err := h.services.DataExports.Request(userID, req.IncludeFiles)
+	if err != nil {
+		var cooldown *services.DataExportCooldownError
+		switch {
+		case errors.Is(err, services.ErrDataExportInProgress):
+			respondError(c, http.StatusConflict, "Your data export is already being prepared.")
+		case errors.As(err, &cooldown):
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(cooldown.RetryAfter.Seconds()))))
+			respondError(c, http.StatusTooManyRequests, fmt.Sprintf("You can request a new export in %s.", formatWait(cooldown.RetryAfter)))
+		default:
+			log.Printf("Error requesting data export for user %s: %v", userID, err)
+			respondError(c, http.StatusInternalServerError, "Could not start the data export.")
+		}
+		return
+	}
+	log.Printf("User %s requested a data export (files: %t)", userID, req.IncludeFiles)
+	h.services.Audit.Record(auditEvent(c, models.AuditDataExportRequest, "data_export", export.ID.String(),
+		gin.H{"include_files": req.IncludeFiles}))
+
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusAccepted, dataExportRow(export, time.Now()))
+		return
+	}
+	exports, inProgress, err := h.dataExportRows(userID)
+	if err != nil {
+		log.Printf("Error listing data exports for user %s: %v", userID, err)
+	}
+	notifySuccess(c, "Your export is being prepared. We will email you when it is ready.")
+	c.HTML(http.StatusAccepted, "partials/_data_export_list.html", gin.H{"Exports": exports, "InProgress": inProgress})
+}
+
+// DownloadDataExport hazır arşivi sadece sahibine indirir.
+func (h *SettingsHandler) DownloadDataExport(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid export ID.")
+		return
+	}
+	export, archivePath, err := h.services.DataExports.Archive(userID, exportID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			respondError(c, http.StatusNotFound, "Export not found.")
+		case errors.Is(err, services.ErrDataExportNotReady):
+			respondError(c, http.StatusGone, "This export is not available for download. Please request a new one.")
+		default:
+			log.Printf("Error loading data export %s for user %s: %v", exportID, userID, err)
+			respondError(c, http.StatusInternalServerError, "Could not load the export.")
+		}
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(archivePath, "aurify-data-"+export.CreatedAt.Format("2006-01-02")+".zip")
+}
+
+// DeleteAccount hesabı kalıcı olarak siler. Şifre, 2FA açıksa geçerli bir kod ve onay olarak kullanıcı adı
+// istenir. Policy "delete" tüm parçaları siler, "anonymize" yayındaki parçaları sahipsiz olarak bırakır.
+func (h *SettingsHandler) DeleteAccount(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	var req DeleteAccountRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Password, confirmation and what to do with your tracks are required.")
+		return
+	}
+	if req.Policy != services.DeletePolicyRemove && req.Policy != services.DeletePolicyAnonymize {
+		respondError(c, http.StatusBadRequest, "Please choose what should happen to your public tracks.")
+		return
+	}
+	if strings.TrimSpace(req.Confirm) != user.Username {
+		respondError(c, http.StatusBadRequest, "Please type your username to confirm.")
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		respondError(c, http.StatusForbidden, "Password is incorrect.")
+		return
+	}
+	if user.TwoFactorEnabled() {
+		if strings.TrimSpace(req.Code) == "" {
+			respondError(c, http.StatusBadRequest, "Please enter your authentication code.")
+			return
+		}
+		if _, err := h.services.TwoFactor.Verify(user, req.Code); err != nil {
+			h.respondTwoFactorError(c, user, err, "Could not verify your authentication code.")
+			return
+		}
+	}
+
+	if _, err := h.services.Sessions.RevokeAllForUser(user.ID, uuid.Nil); err != nil {
+		log.Printf("Revoking sessions before deleting account %s failed: %v", user.ID, err)
+	}
+	// Arşiv kayıtları hesapla birlikte silineceği için dosyalar önce kaldırılır
+	if err := h.services.DataExports.DeleteForUser(user.ID); err != nil {
+		log.Printf("Removing data export archives of %s failed: %v", user.ID, err)
+	}
+	if err := h.services.Account.DeleteAccount(user, req.Policy); err != nil {
+		log.Printf("Deleting account %s failed: %v", user.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not delete your account.")
+		return
+	}
+	log.Printf("User %s (%s) deleted their account (policy: %s)", user.ID, user.Email, req.Policy)
+	event := auditEvent(c, models.AuditAccountDelete, "user", user.ID.String(),
+		gin.H{"username": user.Username, "policy": req.Policy})
+	event.ActorName = user.Username
+	h.services.Audit.Record(event)
+
+	utils.ClearAuthCookies(c)
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"deleted": true})
+		return
+	}
+	c.Header("HX-Redirect", "/?success=account_deleted")
+	c.Status(http.StatusOK)
+}
+
+// dataExportRows liste satırlarını ve hâlâ hazırlanan bir dışa aktarma olup olmadığını döner.
+func (h *SettingsHandler) dataExportRows(userID uuid.UUID) ([]gin.H, bool, error) {
+	exports, err := h.services.DataExports.List(userID)
+	if err != nil {
+		return nil, false, err
+	}
+	now := time.Now()
+	inProgress := false
+	rows := make([]gin.H, 0, len(exports))
+	for i := range exports {
+		if exports[i].Status == models.DataExportPending || exports[i].Status == models.DataExportProcessing {
+			inProgress = true
+		}
+		rows = append(rows, dataExportRow(&exports[i], now))
+	}
+	return rows, inProgress, nil
+}
+
+func dataExportRow(export *models.DataExport, now time.Time) gin.H {
+	row := gin.H{
+		"id":            export.ID.String(),
+		"status":        export.Status,
+		"include_files": export.IncludeFiles,
+		"created_at":    export.CreatedAt.Format("02 Jan 2006 15:04"),
+		"size":          "",
+		"expires_at":    "",
+		"downloadable":  export.IsDownloadable(now),
+		"download_url":  "",
+	}
+	if export.Status == models.DataExportReady {
+		row["size"] = formatSize(export.SizeBytes)
+		if export.ExpiresAt != nil {
+			row["expires_at"] = export.ExpiresAt.Format("02 Jan 2006 15:04")
+		}
+		if export.IsDownloadable(now) {
+			row["download_url"] = "/api/v1/account/exports/" + export.ID.String() + "/download"
+		}
+	}
+	return row
+}
+
+func formatSize(bytes int64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%d KB", bytes>>10)
+	default:
+		return fmt.Sprintf("%d B", bytes)
+	}
+}
+
+// formatWait bekleme süresini kullanıcıya gösterilecek kaba bir ifadeye çevirir.
+func formatWait(d time.Duration) string {
+	if d >= time.Hour {
+		hours := int(math.Ceil(d.Hours()))
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	minutes := int(math.Ceil(d.Minutes()))
+	if minutes <= 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
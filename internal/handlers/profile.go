@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	middleware "github.com/morgarakt/aurify/internal/middlewares"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Profil sayfasındaki sekmeler
+const (
+	profileTabTracks    = "tracks"
+	profileTabLikes     = "likes"
+	profileTabPlaylists = "playlists"
+)
+
+// profileURL kullanıcının herkese açık profil sayfasının yoludur.
+func profileURL(username string) string {
+	return "/u/" + url.PathEscape(username)
+}
+
+// UserProfile kullanıcının herkese açık profil sayfasıdır: avatar, bio, katılım tarihi, sayaçlar ve
+// explore ile aynı filtrelerle yayındaki parçaları. Beğeniler sekmesi sadece kullanıcı izin verdiyse görünür.
+func (h *FrontendHandler) UserProfile(c *gin.Context) {
+	h.renderUserProfile(c, profileTabTracks)
+}
+
+// UserProfileLikes profilin beğenilen parçalar sekmesidir.
+func (h *FrontendHandler) UserProfileLikes(c *gin.Context) {
+	h.renderUserProfile(c, profileTabLikes)
+}
+
+// UserProfilePlaylists profilin herkese açık çalma listeleri sekmesidir; liste dışı ve gizli listeler gösterilmez.
+func (h *FrontendHandler) UserProfilePlaylists(c *gin.Context) {
+	requestingUserID, _, _ := middleware.GetUserInfoFromContext(c)
+	user, ok := h.loadProfileUser(c, profileTabPlaylists)
+	if !ok {
+		if !c.Writer.Written() {
+			h.NotFoundPage(c)
+		}
+		return
+	}
+	summaries, err := h.repo.Playlist.ListByUser(user.ID, requestingUserID, true)
+	if err != nil {
+		log.Printf("Error loading profile playlists of user %s: %v", user.ID, err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load this profile."})
+		return
+	}
+	data := h.profilePageData(c, user, profileTabPlaylists)
+	data["Playlists"] = playlistCards(h.services.Playlists, summaries)
+	c.HTML(http.StatusOK, "users/profile.html", data)
+}
+
+// profilePageData tüm sekmelerde ortak olan profil başlığı ve takip butonu verilerini hazırlar.
+func (h *FrontendHandler) profilePageData(c *gin.Context, user *models.User, tab string) gin.H {
+	requestingUserID, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	stats, err := h.repo.User.GetProfileStats(user.ID)
+	if err != nil {
+		log.Printf("Error loading profile stats for user %s: %v", user.ID, err)
+		stats = &repository.ProfileStats{}
+	}
+
+	isSelf := isAuthenticated && requestingUserID == user.ID
+	isFollowing := false
+	if isAuthenticated && !isSelf {
+		if isFollowing, err = h.repo.UserFollow.IsFollowing(requestingUserID, user.ID); err != nil {
+			log.Printf("Error checking if user %s follows %s: %v", requestingUserID, user.ID, err)
+		}
+	}
+	return gin.H{
+		"title":        user.Username + " - Aurify",
+		"auth":         isAuthenticated,
+		"username":     username,
+		"Profile":      profileHeader(user, stats, h.services.Account.AvatarURL(user)),
+		"Tab":          tab,
+		"IsSelf":       isSelf,
+		"FollowButton": followButtonData(user, stats, isFollowing, isAuthenticated, isSelf),
+	}
+}
+
+func (h *FrontendHandler) renderUserProfile(c *gin.Context, tab string) {
+	requestingUserID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	user, ok := h.loadProfileUser(c, tab)
+	if !ok {
+		if !c.Writer.Written() {
+			h.NotFoundPage(c)
+		}
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage := getPerPageFromQueryOrDefault(c, h.cfg.DefaultPerPage, h.cfg.MinPerPage, h.cfg.MaxPerPage)
+	musicTypes, _ := h.repo.MusicType.GetAll()
+
+	musicList, totalItems, err := h.repo.Music.QueryPublicMusic(profileQueryParams(c, user.ID, tab, page, perPage))
+	if err != nil {
+		log.Printf("Error loading profile tracks (%s) of user %s: %v", tab, user.ID, err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load this profile."})
+		return
+	}
+
+	viewPath, apiPath := profilePaths(user.Username, tab)
+	musicsGinH, paginationData := h.preparePaginationData(c, musicList, totalItems, page, perPage, viewPath, c.Request.URL.Query(), isAuthenticated, requestingUserID)
+	paginationData["APIBaseURL"] = apiPath
+
+	data := h.profilePageData(c, user, tab)
+	data["MusicType"] = musicTypes
+	data["Music"] = musicsGinH
+	data["Pagination"] = paginationData
+	data["HXGetURL"] = apiPath
+	data["InitialPerPage"] = perPage
+	c.HTML(http.StatusOK, "users/profile.html", data)
+}
+
+// GetUserProfileMusic profil sekmelerinin sayfalama/filtre isteklerine müzik listesi parçasını döner.
+func (h *FrontendHandler) GetUserProfileMusic(c *gin.Context) {
+	h.renderUserProfileMusic(c, profileTabTracks)
+}
+
+// GetUserProfileLikes beğeniler sekmesinin müzik listesi parçasını döner.
+func (h *FrontendHandler) GetUserProfileLikes(c *gin.Context) {
+	h.renderUserProfileMusic(c, profileTabLikes)
+}
+
+func (h *FrontendHandler) renderUserProfileMusic(c *gin.Context, tab string) {
+	requestingUserID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	user, ok := h.loadProfileUser(c, tab)
+	if !ok {
+		if !c.Writer.Written() {
+			c.Header("HX-Trigger", `{"showNotification": {"type": "error", "message": "User not found."}}`)
+			c.Status(http.StatusNotFound)
+		}
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage := getPerPageFromQueryOrDefault(c, h.cfg.DefaultPerPage, h.cfg.MinPerPage, h.cfg.MaxPerPage)
+
+	musicList, totalItems, err := h.repo.Music.QueryPublicMusic(profileQueryParams(c, user.ID, tab, page, perPage))
+	if err != nil {
+		log.Printf("Error loading profile tracks (%s) of user %s: %v", tab, user.ID, err)
+		c.Header("HX-Trigger", `{"showNotification": {"type": "error", "message": "Could not load tracks."}}`)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	viewPath, apiPath := profilePaths(user.Username, tab)
+	pushedURLValues := c.Request.URL.Query()
+	pushedURLValues.Set("page", strconv.Itoa(page))
+	pushedURLValues.Set("per_page", strconv.Itoa(perPage))
+	c.Header("HX-Push-Url", viewPath+"?"+pushedURLValues.Encode())
+
+	musicsGinH, paginationData := h.preparePaginationData(c, musicList, totalItems, page, perPage, viewPath, c.Request.URL.Query(), isAuthenticated, requestingUserID)
+	paginationData["APIBaseURL"] = apiPath
+
+	c.HTML(http.StatusOK, "partials/musics-pagination.html", gin.H{
+		"Music":      musicsGinH,
+		"Pagination": paginationData,
+		"Auth":       isAuthenticated,
+	})
+}
+
+// loadProfileUser :username parametresindeki kullanıcıyı yükler. Askıya alınmış hesaplar ve beğenilerini
+// paylaşmayan kullanıcıların beğeniler sekmesi bulunamadı olarak döner (yanıt yazılmaz).
+func (h *FrontendHandler) loadProfileUser(c *gin.Context, tab string) (*models.User, bool) {
+	user, err := h.repo.User.GetByUsername(strings.TrimSpace(c.Param("username")))
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Error loading profile %q: %v", c.Param("username"), err)
+			c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load this profile."})
+		}
+		return nil, false
+	}
+	if user.IsSuspended() || (tab == profileTabLikes && !user.ShowLikes) {
+		return nil, false
+	}
+	return user, true
+}
+
+func profileQueryParams(c *gin.Context, userID uuid.UUID, tab string, page, perPage int) repository.MusicQueryParams {
+	params := repository.MusicQueryParams{
+		SearchQuery:     c.Query("q"),
+		MusicTypeFilter: c.Query("musictype"),
+		SortBy:          c.Query("sort"),
+		Tags:            parseTagFilter(c.Request.URL.Query()),
+		Page:            page,
+		PerPage:         perPage,
+	}
+	if tab == profileTabLikes {
+		params.LikedByID = &userID
+	} else {
+		params.CreatorID = &userID
+	}
+	return params
+}
+
+// profilePaths sekmenin sayfa yolunu ve HTMX ile çağrılan API yolunu döner.
+func profilePaths(username, tab string) (string, string) {
+	escaped := url.PathEscape(username)
+	if tab == profileTabLikes {
+		return "/u/" + escaped + "/likes", "/api/v1/users/" + escaped + "/likes"
+	}
+	return "/u/" + escaped, "/api/v1/users/" + escaped + "/music"
+}
+
+func profileHeader(user *models.User, stats *repository.ProfileStats, avatarURL string) gin.H {
+	return gin.H{
+		"Username":      user.Username,
+		"Initial":       strings.ToUpper(string([]rune(user.Username)[:1])),
+		"AvatarURL":     avatarURL,
+		"Bio":           user.Bio,
+		"JoinedAt":      user.CreatedAt.Format("January 2006"),
+		"PublicTracks":  stats.PublicTracks,
+		"LikesReceived": stats.LikesReceived,
+		"ShowLikes":     user.ShowLikes,
+		"URL":           profileURL(user.Username),
+	}
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/config"
+	middleware "github.com/morgarakt/aurify/internal/middlewares"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/services"
+	"github.com/morgarakt/aurify/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type SettingsHandler struct {
+	repo     *repository.Repository
+	cfg      *config.Config
+	services *services.Services
+}
+
+func NewSettingsHandler(repo *repository.Repository, cfg *config.Config, svc *services.Services) *SettingsHandler {
+	return &SettingsHandler{repo: repo, cfg: cfg, services: svc}
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" form:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" form:"new_password" binding:"required,min=8"`
+	ConfirmPassword string `json:"confirm_password" form:"confirm_password" binding:"required"`
+}
+
+// Sessions, kullanıcının aktif oturumlarını (cihaz, IP, son etkinlik) listeleyen ayarlar sayfasıdır.
+func (h *SettingsHandler) Sessions(c *gin.Context) {
+	userID, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		c.Redirect(http.StatusSeeOther, "/login?redirect=/settings/sessions")
+		return
+	}
+
+	sessions, err := h.sessionRows(userID, middleware.GetSessionIDFromContext(c))
+	if err != nil {
+		log.Printf("Error listing sessions for user %s: %v", userID, err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load your sessions."})
+		return
+	}
+
+	var user models.User
+	if err := h.repo.User.GetByID(userID, &user); err != nil {
+		log.Printf("Error loading user %s for settings: %v", userID, err)
+		c.HTML(http.StatusInternalServerError, "error/unauthorized.html", gin.H{"title": "Error", "message": "Could not load your account."})
+		return
+	}
+
+	var recoveryCodesLeft int64
+	if user.TwoFactorEnabled() {
+		if recoveryCodesLeft, err = h.services.TwoFactor.RemainingRecoveryCodes(userID); err != nil {
+			log.Printf("Error counting recovery codes for user %s: %v", userID, err)
+		}
+	}
+
+	c.HTML(http.StatusOK, "settings/sessions.html", gin.H{
+		"title":             "Sessions & Security - Aurify",
+		"auth":              isAuthenticated,
+		"username":          username,
+		"Sessions":          sessions,
+		"Email":             user.Email,
+		"EmailVerified":     user.IsEmailVerified(),
+		"TwoFactorEnabled":  user.TwoFactorEnabled(),
+		"RecoveryCodesLeft": recoveryCodesLeft,
+	})
+}
+
+// ResendVerification doğrulanmamış e-posta adresine yeni bir doğrulama linki gönderir.
+func (h *SettingsHandler) ResendVerification(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+	var user models.User
+	if err := h.repo.User.GetByID(userID, &user); err != nil {
+		log.Printf("Error loading user %s for verification resend: %v", userID, err)
+		respondError(c, http.StatusInternalServerError, "Could not send the verification email.")
+		return
+	}
+
+	if err := h.services.EmailVerification.SendVerification(&user); err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmailAlreadyVerified):
+			respondError(c, http.StatusConflict, "Your email address is already verified.")
+		case errors.Is(err, services.ErrVerificationRateLimited):
+			respondError(c, http.StatusTooManyRequests, "Too many verification emails. Please try again later.")
+		default:
+			log.Printf("Error sending verification email to user %s: %v", userID, err)
+			respondError(c, http.StatusInternalServerError, "Could not send the verification email.")
+		}
+		return
+	}
+
+	message := "Verification email sent to " + user.Email + "."
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"message": message})
+		return
+	}
+	notifySuccess(c, message)
+	c.Status(http.StatusOK)
+}
+
+// RevokeSession tek bir oturumu kapatır. Mevcut oturum kapatılırsa kullanıcı çıkış yapmış olur.
+func (h *SettingsHandler) RevokeSession(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid session ID.")
+		return
+	}
+
+	if err := h.services.Sessions.RevokeUserSession(userID, sessionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Session not found.")
+			return
+		}
+		log.Printf("Error revoking session %s for user %s: %v", sessionID, userID, err)
+		respondError(c, http.StatusInternalServerError, "Could not sign out the session.")
+		return
+	}
+	log.Printf("Session %s revoked by user %s", sessionID, userID)
+
+	if sessionID == middleware.GetSessionIDFromContext(c) {
+		utils.ClearAuthCookies(c)
+		if isHTMXRequest(c) {
+			c.Header("HX-Redirect", "/?success=logout_ok")
+			c.Status(http.StatusOK)
+			return
+		}
+	}
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"id": sessionID.String(), "revoked": true})
+		return
+	}
+	// Satır outerHTML ile boş içerikle değiştirilerek listeden kaldırılır.
+	notifySuccess(c, "Session signed out.")
+	c.String(http.StatusOK, "")
+}
+
+// RevokeOtherSessions mevcut oturum dışındaki tüm oturumları kapatır ve güncel listeyi döner.
+func (h *SettingsHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+	currentID := middleware.GetSessionIDFromContext(c)
+
+	revoked, err := h.services.Sessions.RevokeAllForUser(userID, currentID)
+	if err != nil {
+		log.Printf("Error revoking other sessions for user %s: %v", userID, err)
+		respondError(c, http.StatusInternalServerError, "Could not sign out other devices.")
+		return
+	}
+	log.Printf("User %s signed out %d other session(s)", userID, revoked)
+	h.services.Audit.Record(auditEvent(c, models.AuditSessionsRevoked, "user", userID.String(), gin.H{"scope": "others", "revoked": revoked}))
+
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+		return
+	}
+	sessions, err := h.sessionRows(userID, currentID)
+	if err != nil {
+		log.Printf("Error listing sessions for user %s: %v", userID, err)
+		respondError(c, http.StatusInternalServerError, "Other devices were signed out, but the list could not be refreshed.")
+		return
+	}
+	notifySuccess(c, "Signed out of all other devices.")
+	c.HTML(http.StatusOK, "partials/_sessions_list.html", gin.H{"Sessions": sessions})
+}
+
+// RevokeAllSessions mevcut oturum dahil tüm oturumları kapatır ("her yerden çıkış yap").
+func (h *SettingsHandler) RevokeAllSessions(c *gin.Context) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+
+	revoked, err := h.services.Sessions.RevokeAllForUser(userID, uuid.Nil)
+	if err != nil {
+		log.Printf("Error revoking all sessions for user %s: %v", userID, err)
+		respondError(c, http.StatusInternalServerError, "Could not sign out everywhere.")
+		return
+	}
+	log.Printf("User %s signed out everywhere (%d session(s))", userID, revoked)
+	h.services.Audit.Record(auditEvent(c, models.AuditSessionsRevoked, "user", userID.String(), gin.H{"scope": "all", "revoked": revoked}))
+
+	utils.ClearAuthCookies(c)
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+		return
+	}
+	c.Header("HX-Redirect", "/?success=logout_all_ok")
+	c.Status(http.StatusOK)
+}
+
+// ChangePassword mevcut şifreyi doğrulayıp yenisini kaydeder. Şifre değişince tüm oturumlar kapatılır,
+// isteği yapan tarayıcı için yeni bir oturum açılır.
+func (h *SettingsHandler) ChangePassword(c *gin.Context) {
+	userID, username, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "New password must be at least 8 characters.")
+		return
+	}
+	if req.NewPassword != req.ConfirmPassword {
+		respondError(c, http.StatusBadRequest, "New passwords do not match.")
+		return
+	}
+
+	var user models.User
+	if err := h.repo.User.GetByID(userID, &user); err != nil {
+		log.Printf("Error loading user %s for password change: %v", userID, err)
+		respondError(c, http.StatusInternalServerError, "Could not change password.")
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)); err != nil {
+		respondError(c, http.StatusForbidden, "Current password is incorrect.")
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("Password hashing failed: %v", err)
+		respondError(c, http.StatusInternalServerError, "Could not change password.")
+		return
+	}
+	if err := h.repo.User.UpdatePasswordHash(userID, string(hashedPassword)); err != nil {
+		log.Printf("Error saving new password for user %s: %v", userID, err)
+		respondError(c, http.StatusInternalServerError, "Could not change password.")
+		return
+	}
+
+	revoked, err := h.services.Sessions.RevokeAllForUser(userID, uuid.Nil)
+	if err != nil {
+		log.Printf("Password changed but revoking sessions failed for user %s: %v", userID, err)
+	}
+	log.Printf("Password changed for user %s, %d session(s) revoked", userID, revoked)
+	h.services.Audit.Record(auditEvent(c, models.AuditPasswordChange, "user", userID.String(), gin.H{"sessions_revoked": revoked}))
+
+	if err := utils.GenerateToken(c, userID.String(), username, user.Role, h.cfg.JWTSecret); err != nil {
+		log.Printf("Token generation failed after password change for user %s: %v", userID, err)
+		utils.ClearAuthCookies(c)
+		if isHTMXRequest(c) {
+			c.Header("HX-Redirect", "/login")
+			c.Status(http.StatusOK)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Password changed. Please log in again."})
+		return
+	}
+
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"message": "Password changed. All other sessions were signed out."})
+		return
+	}
+	c.Header("HX-Redirect", "/settings?success=password_changed")
+	c.Status(http.StatusOK)
+}
+
+func (h *SettingsHandler) sessionRows(userID, currentID uuid.UUID) ([]gin.H, error) {
+	sessions, err := h.services.Sessions.ListActive(userID)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]gin.H, 0, len(sessions))
+	for _, s := range sessions {
+		rows = append(rows, gin.H{
+			"ID":        s.ID.String(),
+			"Device":    utils.DescribeUserAgent(s.UserAgent),
+			"UserAgent": s.UserAgent,
+			"IPAddress": s.IPAddress,
+			"CreatedAt": s.CreatedAt.Format("02 Jan 2006 15:04"),
+			"LastSeen":  s.LastSeenAt.Format("02 Jan 2006 15:04"),
+			"IsCurrent": s.ID == currentID,
+		})
+	}
+	return rows, nil
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	middleware "github.com/morgarakt/aurify/internal/middlewares"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/services"
+	"github.com/morgarakt/aurify/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type TwoFactorLoginRequest struct {
+	Code string `json:"code" form:"code" binding:"required"`
+	// Çerez kullanmayan API istemcileri login yanıtındaki ara token'ı burada gönderir
+	Challenge string `json:"challenge" form:"challenge"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" form:"code" binding:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" form:"password" binding:"required"`
+	Code     string `json:"code" form:"code" binding:"required"`
+}
+
+// TwoFactorLoginPage şifresi doğrulanmış kullanıcının TOTP ya da kurtarma kodunu girdiği sayfadır.
+func (h *FrontendHandler) TwoFactorLoginPage(c *gin.Context) {
+	if _, err := utils.ParseTwoFactorChallenge(c, h.cfg.JWTSecret, ""); err != nil {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+	c.HTML(http.StatusOK, "auth/login_2fa.html", gin.H{"title": "Two-Factor Authentication - Aurify", "auth": false})
+}
+
+// TwoFactorLoginHandler girişin ikinci adımıdır: ara token ile birlikte gelen kodu doğrulayıp oturumu açar.
+func (h *AuthHandler) TwoFactorLoginHandler(c *gin.Context) {
+	var req TwoFactorLoginRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Please enter your authentication code.")
+		return
+	}
+	userID, err := utils.ParseTwoFactorChallenge(c, h.cfg.JWTSecret, req.Challenge)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "Your sign-in attempt has expired. Please sign in again.")
+		return
+	}
+	var user models.User
+	if err := h.repo.User.GetByID(userID, &user); err != nil || user.IsSuspended() {
+		utils.ClearTwoFactorChallenge(c)
+		respondError(c, http.StatusUnauthorized, "Your sign-in attempt has expired. Please sign in again.")
+		return
+	}
+
+	method, err := h.services.TwoFactor.Verify(&user, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTwoFactorInvalidCode):
+			h.services.Audit.Record(userAuditEvent(c, models.AuditLoginFailure, &user, gin.H{"reason": "invalid_2fa_code"}))
+			respondError(c, http.StatusUnauthorized, "Invalid authentication code.")
+		case errors.Is(err, services.ErrTwoFactorTooManyAttempts):
+			respondError(c, http.StatusTooManyRequests, "Too many attempts. Please wait a few minutes and try again.")
+		case errors.Is(err, services.ErrTwoFactorNotEnabled):
+			// 2FA bu arada kapatıldıysa şifre adımı yeterlidir
+			utils.ClearTwoFactorChallenge(c)
+			h.completeLogin(c, &user, nil)
+		default:
+			log.Printf("2FA verification for user %s failed: %v", user.ID, err)
+			respondError(c, http.StatusInternalServerError, "Could not verify the code.")
+		}
+		return
+	}
+
+	utils.ClearTwoFactorChallenge(c)
+	if method == services.TwoFactorMethodRecoveryCode {
+		log.Printf("User %s signed in with a recovery code", user.ID)
+	}
+	h.completeLogin(c, &user, gin.H{"second_factor": method})
+}
+
+// SetupTwoFactor yeni bir TOTP secret'ı üretir ve QR kodunu gösterir. Kod onaylanana kadar 2FA açılmaz.
+func (h *SettingsHandler) SetupTwoFactor(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	enrollment, err := h.services.TwoFactor.BeginEnrollment(user)
+	if err != nil {
+		if errors.Is(err, services.ErrTwoFactorAlreadyEnabled) {
+			respondError(c, http.StatusConflict, "Two-factor authentication is already enabled.")
+			return
+		}
+		log.Printf("Starting 2FA setup for user %s failed: %v", user.ID, err)
+		respondError(c, http.StatusInternalServerError, "Could not start two-factor setup.")
+		return
+	}
+
+	qrCode := base64.StdEncoding.EncodeToString(enrollment.QRCodePNG)
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"secret": enrollment.Secret, "uri": enrollment.URI, "qr_code_png": qrCode})
+		return
+	}
+	c.HTML(http.StatusOK, "partials/_two_factor_setup.html", gin.H{
+		"Secret": enrollment.Secret,
+		"QRCode": template.URL("data:image/png;base64," + qrCode),
+	})
+}
+
+// ConfirmTwoFactor authenticator'dan gelen ilk kodu doğrulayıp 2FA'yı açar ve kurtarma kodlarını bir kez gösterir.
+func (h *SettingsHandler) ConfirmTwoFactor(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Please enter the code from your authenticator app.")
+		return
+	}
+	codes, err := h.services.TwoFactor.ConfirmEnrollment(user, req.Code)
+	if err != nil {
+		h.respondTwoFactorError(c, user, err, "Could not enable two-factor authentication.")
+		return
+	}
+	h.services.Audit.Record(auditEvent(c, models.AuditTwoFactorEnabled, "user", user.ID.String(), nil))
+	h.respondRecoveryCodes(c, codes, "Two-factor authentication enabled.")
+}
+
+// RegenerateRecoveryCodes geçerli bir kodla tüm kurtarma kodlarını yeniler; eskiler geçersiz olur.
+func (h *SettingsHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Please enter a code from your authenticator app.")
+		return
+	}
+	codes, err := h.services.TwoFactor.RegenerateRecoveryCodes(user, req.Code)
+	if err != nil {
+		h.respondTwoFactorError(c, user, err, "Could not regenerate recovery codes.")
+		return
+	}
+	h.services.Audit.Record(auditEvent(c, models.AuditRecoveryCodesReset, "user", user.ID.String(), nil))
+	h.respondRecoveryCodes(c, codes, "New recovery codes generated.")
+}
+
+// DisableTwoFactor şifre ve geçerli bir kodla iki adımlı doğrulamayı kapatır.
+func (h *SettingsHandler) DisableTwoFactor(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	var req DisableTwoFactorRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Password and code are required.")
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		respondError(c, http.StatusForbidden, "Password is incorrect.")
+		return
+	}
+	if err := h.services.TwoFactor.Disable(user, req.Code); err != nil {
+		h.respondTwoFactorError(c, user, err, "Could not disable two-factor authentication.")
+		return
+	}
+	log.Printf("User %s disabled two-factor authentication", user.ID)
+	h.services.Audit.Record(auditEvent(c, models.AuditTwoFactorDisabled, "user", user.ID.String(), nil))
+
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+		return
+	}
+	c.Header("HX-Redirect", "/settings/sessions?success=two_factor_disabled")
+	c.Status(http.StatusOK)
+}
+
+// currentUser oturumdaki kullanıcıyı yükler; hata durumunda yanıtı yazar.
+func (h *SettingsHandler) currentUser(c *gin.Context) (*models.User, bool) {
+	userID, _, isAuthenticated := middleware.GetUserInfoFromContext(c)
+	if !isAuthenticated {
+		respondError(c, http.StatusUnauthorized, "Please log in.")
+		return nil, false
+	}
+	var user models.User
+	if err := h.repo.User.GetByID(userID, &user); err != nil {
+		log.Printf("Error loading user %s: %v", userID, err)
+		respondError(c, http.StatusInternalServerError, "Could not load your account.")
+		return nil, false
+	}
+	return &user, true
+}
+
+func (h *SettingsHandler) respondTwoFactorError(c *gin.Context, user *models.User, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrTwoFactorInvalidCode):
+		respondError(c, http.StatusBadRequest, "Invalid authentication code.")
+	case errors.Is(err, services.ErrTwoFactorTooManyAttempts):
+		respondError(c, http.StatusTooManyRequests, "Too many attempts. Please wait a few minutes and try again.")
+	case errors.Is(err, services.ErrTwoFactorNotEnabled):
+		respondError(c, http.StatusConflict, "Two-factor authentication is not enabled.")
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled):
+		respondError(c, http.StatusConflict, "Two-factor authentication is already enabled.")
+	case errors.Is(err, services.ErrTwoFactorNoPendingSetup):
+		respondError(c, http.StatusConflict, "Start the setup again.")
+	default:
+		log.Printf("2FA action for user %s failed: %v", user.ID, err)
+		respondError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+func (h *SettingsHandler) respondRecoveryCodes(c *gin.Context, codes []string, message string) {
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+		return
+	}
+	notifySuccess(c, message)
+	c.HTML(http.StatusOK, "partials/_recovery_codes.html", gin.H{"Codes": codes})
+}
@@ -2,38 +2,137 @@
 package middleware
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"net/url" // URL işlemleri için eklendi
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
 	"github.com/morgarakt/aurify/internal/utils"
 )
 
-// OptionalAuthMiddleware sets context if token is valid, but doesn't block
+// accessTokenPrincipalKey caches the resolved access token so AccessTokenAuth does not look it up twice.
+const accessTokenPrincipalKey = "accessTokenPrincipal"
+
+// OptionalAuthMiddleware sets context if token is valid, but doesn't block.
+// An expired access token is renewed transparently with the refresh token cookie.
+// Read-only requests (GET/HEAD) with a personal access token carrying music:read are resolved to the
+// token owner, so optional-auth pages show them their private tracks. The request is not marked as an
+// access token request; routes behind AuthMiddleware still need AccessTokenAuth to accept the token.
 func OptionalAuthMiddleware(secret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, err := utils.VerifyToken(c, secret)
+		if principal := optionalAccessToken(c); principal != nil {
+			c.Set("userID", principal.UserID.String())
+			c.Set("username", principal.Username)
+			c.Set("sessionID", "")
+			c.Set("role", principal.Role)
+			c.Set("authenticated", true)
+			c.Next()
+			return
+		}
+		claims, err := utils.Authenticate(c, secret)
 		if err == nil && claims != nil {
 			c.Set("userID", claims.UserID)
 			c.Set("username", claims.Username)
+			c.Set("sessionID", claims.ID)
+			c.Set("role", claims.Role)
 			c.Set("authenticated", true)
-			// Admin rolü kontrolü gelecekte burada eklenebilir
-			// Örneğin: c.Set("isAdmin", claims.IsAdmin)
 		} else {
 			c.Set("authenticated", false)
 			c.Set("userID", "")   // Ensure userID is empty if not authenticated
 			c.Set("username", "") // Ensure username is empty if not authenticated
-			// c.Set("isAdmin", false)
+			c.Set("sessionID", "")
+			c.Set("role", "")
+		}
+		c.Next()
+	}
+}
+
+// optionalAccessToken returns the principal of a valid music:read bearer token on a read-only request, or nil.
+// Invalid tokens are not rejected here; the request is handled as anonymous (or by its cookie).
+func optionalAccessToken(c *gin.Context) *utils.AccessTokenPrincipal {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return nil
+	}
+	if _, ok := utils.BearerToken(c); !ok {
+		return nil
+	}
+	principal, err := resolveAccessToken(c)
+	if err != nil {
+		if !errors.Is(err, utils.ErrAccessTokenInvalid) {
+			log.Printf("Access token authentication failed: %v", err)
+		}
+		return nil
+	}
+	if !principal.HasScope(models.ScopeMusicRead) {
+		return nil
+	}
+	return principal
+}
+
+// resolveAccessToken authenticates the bearer token once per request.
+func resolveAccessToken(c *gin.Context) (*utils.AccessTokenPrincipal, error) {
+	if cached, ok := c.Get(accessTokenPrincipalKey); ok {
+		return cached.(*utils.AccessTokenPrincipal), nil
+	}
+	principal, err := utils.AuthenticateAccessToken(c)
+	if err != nil {
+		return nil, err
+	}
+	c.Set(accessTokenPrincipalKey, principal)
+	return principal, nil
+}
+
+// AccessTokenAuth lets a route accept personal access tokens (Authorization: Bearer) that carry scope.
+// It must run before AuthMiddleware; requests without a bearer token fall through to cookie authentication.
+// Routes without it never accept access tokens.
+func AccessTokenAuth(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := utils.BearerToken(c); !ok {
+			c.Next()
+			return
+		}
+		principal, err := resolveAccessToken(c)
+		if err != nil {
+			if !errors.Is(err, utils.ErrAccessTokenInvalid) {
+				log.Printf("Access token authentication failed: %v", err)
+			}
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid, revoked or expired access token"})
+			return
+		}
+		if !principal.HasScope(scope) {
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access token is missing the " + scope + " scope"})
+			return
 		}
+
+		c.Set("userID", principal.UserID.String())
+		c.Set("username", principal.Username)
+		c.Set("sessionID", "") // Token isteklerinin sunucu tarafı oturumu yoktur
+		c.Set("role", principal.Role)
+		c.Set("accessTokenID", principal.TokenID.String())
+		c.Set("authenticated", true)
 		c.Next()
 	}
 }
 
-// AuthMiddleware requires a valid token and blocks if invalid/missing
+// IsAccessTokenRequest reports whether the request was authenticated with a personal access token.
+func IsAccessTokenRequest(c *gin.Context) bool {
+	return c.GetString("accessTokenID") != ""
+}
+
+// AuthMiddleware requires a valid token and blocks if invalid/missing (after trying a refresh).
+// Requests already authenticated by AccessTokenAuth pass through.
 func AuthMiddleware(secret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, err := utils.VerifyToken(c, secret)
+		if IsAccessTokenRequest(c) {
+			c.Next()
+			return
+		}
+		claims, err := utils.Authenticate(c, secret)
 		if err != nil || claims == nil {
 			// Kullanıcı giriş yapmamışsa veya token geçersizse
 			// Yönlendirme URL'sini al
@@ -55,24 +154,40 @@ func AuthMiddleware(secret string) gin.HandlerFunc {
 			return
 		}
 
-		// Admin kontrolü (gelecekte eklenecek)
-		// if claims.Role != "admin" { // Örneğin claims içinde Role alanı varsa
-		// 	c.HTML(http.StatusForbidden, "error/unauthorized.html", gin.H{
-		// 		"title":           "Yönetici Yetkisi Gerekli",
-		// 		"IsLoginRequired": false,
-		// 		"IsAdminRequired": true,
-		// 		"auth":            true, // Kullanıcı giriş yapmış ama admin değil
-		// 		"username":        claims.Username,
-		// 	})
-		// 	c.Abort()
-		// 	return
-		// }
-
 		// Set user info in context for downstream handlers
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("sessionID", claims.ID)
+		c.Set("role", claims.Role)
 		c.Set("authenticated", true)
-		// c.Set("isAdmin", claims.IsAdmin) // Admin rolü eklendiğinde
+		c.Next()
+	}
+}
+
+// RequireRole blocks users whose role is below minRole. It must run after AuthMiddleware.
+func RequireRole(minRole string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsAuthenticated(c) {
+			c.HTML(http.StatusUnauthorized, "error/unauthorized.html", gin.H{
+				"title":           "Yetkisiz Erişim",
+				"IsLoginRequired": true,
+				"RedirectURL":     url.QueryEscape(c.Request.URL.RequestURI()),
+				"auth":            false,
+			})
+			c.Abort()
+			return
+		}
+		if !models.RoleAtLeast(GetUserRoleFromContext(c), minRole) {
+			c.HTML(http.StatusForbidden, "error/unauthorized.html", gin.H{
+				"title":           "Yönetici Yetkisi Gerekli",
+				"IsLoginRequired": false,
+				"IsAdminRequired": true,
+				"auth":            true, // Kullanıcı giriş yapmış ama yetkisi yok
+				"username":        c.GetString("username"),
+			})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -87,10 +202,9 @@ func IsAuthenticated(c *gin.Context) bool {
 }
 
 // GetUserInfoFromContext retrieves user details and authentication status.
-// It now also includes a placeholder for isAdmin status.
-func GetUserInfoFromContext(c *gin.Context) (userID uuid.UUID, username string, auth bool /*, isAdmin bool */) {
+// The role is available separately through GetUserRoleFromContext.
+func GetUserInfoFromContext(c *gin.Context) (userID uuid.UUID, username string, auth bool) {
 	auth = IsAuthenticated(c)
-	// isAdmin = c.GetBool("isAdmin") // Admin rolü eklendiğinde
 
 	if auth {
 		username = c.GetString("username")
@@ -100,8 +214,24 @@ func GetUserInfoFromContext(c *gin.Context) (userID uuid.UUID, username string,
 		if err != nil {
 			auth = false
 			username = ""
-			// isAdmin = false
 		}
 	}
-	return userID, username, auth //, isAdmin
+	return userID, username, auth
+}
+
+// GetUserRoleFromContext returns the role of the authenticated user, or "" for anonymous requests.
+func GetUserRoleFromContext(c *gin.Context) string {
+	if !IsAuthenticated(c) {
+		return ""
+	}
+	return c.GetString("role")
+}
+
+// GetSessionIDFromContext returns the server-side session ID (JWT jti) of the current request.
+func GetSessionIDFromContext(c *gin.Context) uuid.UUID {
+	sessionID, err := uuid.Parse(c.GetString("sessionID"))
+	if err != nil {
+		return uuid.Nil
+	}
+	return sessionID
 }
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/utils"
+)
+
+// fakeAccessTokenStore token -> principal eşlemesiyle çalışır ve kaç kez sorgulandığını sayar.
+type fakeAccessTokenStore struct {
+	mu         sync.Mutex
+	principals map[string]*utils.AccessTokenPrincipal
+	lookups    int
+}
+
+func (s *fakeAccessTokenStore) AuthenticateAccessToken(token, ip string) (*utils.AccessTokenPrincipal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups++
+	if p, ok := s.principals[token]; ok {
+		return p, nil
+	}
+	return nil, utils.ErrAccessTokenInvalid
+}
+
+const (
+	readToken  = utils.AccessTokenPrefix + "read"
+	writeToken = utils.AccessTokenPrefix + "write"
+)
+
+func setupAccessTokens(t *testing.T) (*fakeAccessTokenStore, uuid.UUID) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	owner := uuid.New()
+	store := &fakeAccessTokenStore{principals: map[string]*utils.AccessTokenPrincipal{
+		readToken:  {TokenID: uuid.New(), UserID: owner, Username: "owner", Role: models.RoleUser, Scopes: []string{models.ScopeMusicRead}},
+		writeToken: {TokenID: uuid.New(), UserID: owner, Username: "owner", Role: models.RoleUser, Scopes: []string{models.ScopeMusicWrite}},
+	}}
+	utils.SetAccessTokenStore(store)
+	t.Cleanup(func() { utils.SetAccessTokenStore(nil) })
+	return store, owner
+}
+
+func whoAmI(c *gin.Context) {
+	userID, _, auth := GetUserInfoFromContext(c)
+	if !auth {
+		c.String(http.StatusOK, "anonymous")
+		return
+	}
+	c.String(http.StatusOK, userID.String())
+}
+
+func TestOptionalAuthAcceptsReadAccessToken(t *testing.T) {
+	_, owner := setupAccessTokens(t)
+	engine := gin.New()
+	engine.Use(OptionalAuthMiddleware("secret"))
+	engine.GET("/music/:id", whoAmI)
+	engine.POST("/music/:id", whoAmI)
+
+	cases := []struct {
+		name   string
+		method string
+		token  string
+		want   string
+	}{
+		{"read token on GET", http.MethodGet, readToken, owner.String()},
+		{"no token", http.MethodGet, "", "anonymous"},
+		{"token without music:read", http.MethodGet, writeToken, "anonymous"},
+		{"unknown token", http.MethodGet, utils.AccessTokenPrefix + "nope", "anonymous"},
+		{"read token on POST", http.MethodPost, readToken, "anonymous"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/music/1", nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+			if rec.Body.String() != tc.want {
+				t.Errorf("principal = %q, want %q", rec.Body.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestOptionalAuthDoesNotMarkAccessTokenRequest(t *testing.T) {
+	setupAccessTokens(t)
+	engine := gin.New()
+	engine.Use(OptionalAuthMiddleware("secret"))
+	engine.GET("/settings", func(c *gin.Context) {
+		// AuthMiddleware bu işarete güvenir; yalnızca AccessTokenAuth koyabilmeli
+		if IsAccessTokenRequest(c) {
+			c.String(http.StatusOK, "token request")
+			return
+		}
+		c.String(http.StatusOK, "cookie request")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/settings", nil)
+	req.Header.Set("Authorization", "Bearer "+readToken)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Body.String() != "cookie request" {
+		t.Fatalf("optional auth marked the request as an access token request")
+	}
+}
+
+func TestAccessTokenAuthReusesOptionalLookup(t *testing.T) {
+	store, owner := setupAccessTokens(t)
+	engine := gin.New()
+	engine.Use(OptionalAuthMiddleware("secret"))
+	engine.GET("/api/music", AccessTokenAuth(models.ScopeMusicRead), AuthMiddleware("secret"), whoAmI)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/music", nil)
+	req.Header.Set("Authorization", "Bearer "+readToken)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != owner.String() {
+		t.Fatalf("response = %d %q", rec.Code, rec.Body.String())
+	}
+	if store.lookups != 1 {
+		t.Errorf("token looked up %d times, want 1", store.lookups)
+	}
+}
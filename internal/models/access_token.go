@@ -0,0 +1,61 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kişisel erişim token'larının yetki alanları (scope). Token sadece seçilen alanlardaki API uçlarına erişebilir;
+// hesap, oturum ve admin işlemleri token ile hiçbir zaman yapılamaz.
+const (
+	ScopeMusicRead  = "music:read"
+	ScopeMusicWrite = "music:write"
+	ScopeGenerate   = "generate"
+)
+
+var AccessTokenScopes = []string{ScopeMusicRead, ScopeMusicWrite, ScopeGenerate}
+
+// IsValidScope scope'un tanımlı alanlardan biri olup olmadığını bildirir.
+func IsValidScope(scope string) bool {
+	for _, s := range AccessTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// PersonalAccessToken betik ve API kullanımı için kullanıcının oluşturduğu uzun ömürlü token'dır.
+// Token'ın kendisi sadece oluşturulurken bir kez gösterilir; SHA-256 özeti saklanır.
+// Prefix, listede token'ı tanımak için gizli değerin ilk karakterleridir.
+type PersonalAccessToken struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	User       User      `gorm:"constraint:OnDelete:CASCADE;"`
+	Name       string    `gorm:"size:64;not null"`
+	TokenHash  string    `gorm:"size:64;not null;uniqueIndex"`
+	Prefix     string    `gorm:"size:16;not null"`
+	Scopes     string    `gorm:"size:255;not null"` // boşlukla ayrılmış scope listesi
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	LastUsedIP string `gorm:"size:64"`
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// ScopeList token'ın scope'larını döner.
+func (t *PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// IsExpired token'ın süresinin dolup dolmadığını bildirir; süresiz token'lar hiç dolmaz.
+func (t *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// IsActive token'ın iptal edilmemiş ve süresinin dolmamış olduğunu bildirir.
+func (t *PersonalAccessToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && !t.IsExpired(now)
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CatalogEntry müzik türü ve model kataloglarının ortak alanlarıdır.
+// Kayıtlar silinmez, devre dışı bırakılır: eski müzikler ve üretim kayıtları onlara bağlı kalır.
+type CatalogEntry struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name        string    `gorm:"unique"` // Worker'a gönderilen anahtar; oluşturulduktan sonra değişmez
+	DisplayName string    `gorm:"size:100"`
+	Description string    `gorm:"type:text"`
+	Enabled     bool      `gorm:"not null;default:true;index"`
+	SortOrder   int       `gorm:"not null;default:0"`
+	Icon        string    `gorm:"size:64"` // Ad yanında gösterilen emoji veya kısa simge
+	// Üretim isteğindeki varsayılan parametrelerin üzerine yazılan JSON nesnesi
+	DefaultParams json.RawMessage `gorm:"type:jsonb"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Label arayüzde gösterilecek ismi döner; görünen isim yoksa anahtar kullanılır.
+func (e CatalogEntry) Label() string {
+	if e.DisplayName != "" {
+		return e.DisplayName
+	}
+	return e.Name
+}
+
+// Params varsayılan parametreleri çözümler; boş veya geçersizse nil döner.
+func (e CatalogEntry) Params() map[string]interface{} {
+	if len(e.DefaultParams) == 0 {
+		return nil
+	}
+	var params map[string]interface{}
+	if err := json.Unmarshal(e.DefaultParams, &params); err != nil {
+		return nil
+	}
+	return params
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comment bir parçaya yazılmış yorumdur. Yanıtlar tek seviyelidir: ParentID her zaman üst düzey bir
+// yoruma işaret eder, yanıta verilen yanıt da aynı üst yorumun altına eklenir.
+type Comment struct {
+	ID       uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	MusicID  uuid.UUID  `gorm:"type:uuid;not null;index:idx_comment_music_created"`
+	Music    Music      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID   uuid.UUID  `gorm:"type:uuid;not null;index"`
+	User     User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ParentID *uuid.UUID `gorm:"type:uuid;index"`    // Boş ise üst düzey yorumdur
+	Body     string     `gorm:"type:text;not null"` // Düz metin; görüntülenirken escape edilir
+	// Yorumun işaret ettiği parça anı (saniye), ör. "0:42'de"; boş ise yorum belirli bir ana bağlı değildir
+	TimestampSeconds *int
+	EditedAt         *time.Time
+	CreatedAt        time.Time `gorm:"index:idx_comment_music_created"`
+	UpdatedAt        time.Time
+	Replies          []Comment `gorm:"foreignKey:ParentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// IsReply yorumun bir yanıt olup olmadığını bildirir.
+func (c *Comment) IsReply() bool {
+	return c.ParentID != nil
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Veri dışa aktarma işinin durumları.
+const (
+	DataExportPending    = "pending"
+	DataExportProcessing = "processing"
+	DataExportReady      = "ready"
+	DataExportFailed     = "failed"
+)
+
+// DataExport kullanıcının hakkında tutulan tüm verileri indirmek için istediği zip arşividir.
+// Arşiv herkese açık dizin dışında, ArchiveName adıyla saklanır ve ExpiresAt'ten sonra silinir.
+type DataExport struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index"`
+	User         User      `gorm:"constraint:OnDelete:CASCADE;"`
+	Status       string    `gorm:"size:16;not null;index"`
+	IncludeFiles bool      // Üretilen mp3/midi/kapak dosyaları da arşive eklenir
+	ArchiveName  string    `gorm:"size:128"`
+	SizeBytes    int64
+	Error        string `gorm:"size:255"`
+	CreatedAt    time.Time
+	StartedAt    *time.Time
+	CompletedAt  *time.Time
+	ExpiresAt    *time.Time `gorm:"index"`
+}
+
+// IsDownloadable arşivin hazır ve süresinin dolmamış olduğunu bildirir.
+func (e *DataExport) IsDownloadable(now time.Time) bool {
+	return e.Status == DataExportReady && e.ExpiresAt != nil && now.Before(*e.ExpiresAt)
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailVerificationToken kayıt sonrası gönderilen e-posta doğrulama linkidir.
+// Email, linkin gönderildiği adrestir; kullanıcı adresini değiştirirse eski linkler geçersiz olur.
+type EmailVerificationToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	User      User      `gorm:"constraint:OnDelete:CASCADE;"`
+	Email     string    `gorm:"not null"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"index"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
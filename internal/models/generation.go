@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Üretim isteklerinin sonucu. Zaman aşımı, worker hatasından ayrı tutulur.
+const (
+	GenerationSucceeded = "success"
+	GenerationFailed    = "failure"
+	GenerationTimedOut  = "timeout"
+)
+
+// Generation her müzik üretim isteğinin kaydıdır; admin analizleri bu tablodan hesaplanır.
+// Tür ve model isimleri kopyalanır, böylece katalogdan silinen kayıtlar istatistikleri bozmaz.
+type Generation struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TaskID        string     `gorm:"size:64;index"`
+	UserID        *uuid.UUID `gorm:"type:uuid;index"`
+	MusicID       *uuid.UUID `gorm:"type:uuid"`
+	MusicTypeName string     `gorm:"size:64"`
+	ModelTypeName string     `gorm:"size:64"`
+	Status        string     `gorm:"size:16;index"`
+	ErrorMessage  string     `gorm:"size:512"`
+	LatencyMs     int64      // Worker'a istek gönderilmesinden yanıta kadar geçen süre
+	CreatedAt     time.Time  `gorm:"index"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity bir dış kimlik sağlayıcısındaki (OIDC) hesabı yerel kullanıcıya bağlar.
+// Sağlayıcı, issuer + subject çiftiyle tanınır; e-posta sadece bilgi amaçlı saklanır ve değişebilir.
+type Identity struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index"`
+	User        User      `gorm:"constraint:OnDelete:CASCADE;"`
+	Issuer      string    `gorm:"size:255;not null;uniqueIndex:idx_identity_subject"`
+	Subject     string    `gorm:"size:255;not null;uniqueIndex:idx_identity_subject"`
+	Email       string
+	LastLoginAt *time.Time
+	CreatedAt   time.Time
+}
@@ -1 +1,79 @@
 package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Denetim kaydı eylemleri. Yeni eylemler buraya eklenir; admin sayfasındaki filtre listesi buradan gelir.
+const (
+	AuditLoginSuccess         = "auth.login_success"
+	AuditLoginFailure         = "auth.login_failure"
+	AuditRegister             = "auth.register"
+	AuditLogout               = "auth.logout"
+	AuditPasswordChange       = "auth.password_change"
+	AuditSessionsRevoked      = "auth.sessions_revoked"
+	AuditPasswordResetRequest = "auth.password_reset_request"
+	AuditPasswordReset        = "auth.password_reset"
+	AuditEmailVerified        = "auth.email_verified"
+	AuditTwoFactorEnabled     = "auth.2fa_enabled"
+	AuditTwoFactorDisabled    = "auth.2fa_disabled"
+	AuditRecoveryCodesReset   = "auth.2fa_recovery_codes_regenerated"
+	AuditIdentityLinked       = "auth.identity_linked"
+	AuditAccessTokenCreate    = "auth.access_token_create"
+	AuditAccessTokenRevoke    = "auth.access_token_revoke"
+	AuditAccountLocked        = "auth.account_locked"
+
+	AuditUsernameChange    = "account.username_change"
+	AuditEmailChange       = "account.email_change"
+	AuditProfileUpdate     = "account.profile_update"
+	AuditDataExportRequest = "account.data_export"
+	AuditAccountDelete     = "account.delete"
+
+	AuditMusicTitleChange      = "music.title_change"
+	AuditMusicVisibilityChange = "music.visibility_change"
+	AuditMusicDelete           = "music.delete"
+	AuditMusicRestore          = "music.restore"
+	AuditMusicPurge            = "music.purge"
+	AuditCommentDelete         = "music.comment_delete" // Yazarı dışında biri (parça sahibi, moderatör) sildiğinde
+
+	AuditUserRoleChange = "admin.user_role_change"
+	AuditUserSuspend    = "admin.user_suspend"
+	AuditUserUnsuspend  = "admin.user_unsuspend"
+	AuditUserQuotaReset = "admin.user_quota_reset"
+	AuditUserDelete     = "admin.user_delete"
+	AuditUserUnlock     = "admin.user_unlock"
+	AuditCatalogCreate  = "admin.catalog_create"
+	AuditCatalogUpdate  = "admin.catalog_update"
+	AuditCatalogEnable  = "admin.catalog_enable"
+	AuditCatalogDisable = "admin.catalog_disable"
+)
+
+var AuditActions = []string{
+	AuditLoginSuccess, AuditLoginFailure, AuditRegister, AuditLogout, AuditPasswordChange, AuditSessionsRevoked,
+	AuditPasswordResetRequest, AuditPasswordReset, AuditEmailVerified,
+	AuditTwoFactorEnabled, AuditTwoFactorDisabled, AuditRecoveryCodesReset, AuditIdentityLinked,
+	AuditAccessTokenCreate, AuditAccessTokenRevoke, AuditAccountLocked,
+	AuditUsernameChange, AuditEmailChange, AuditProfileUpdate, AuditDataExportRequest, AuditAccountDelete,
+	AuditMusicTitleChange, AuditMusicVisibilityChange, AuditMusicDelete, AuditMusicRestore, AuditMusicPurge, AuditCommentDelete,
+	AuditUserRoleChange, AuditUserSuspend, AuditUserUnsuspend, AuditUserQuotaReset, AuditUserDelete, AuditUserUnlock,
+	AuditCatalogCreate, AuditCatalogUpdate, AuditCatalogEnable, AuditCatalogDisable,
+}
+
+// AuditLog güvenlik ve içerik açısından önemli bir eylemin kaydıdır.
+// Aktör silinse bile kayıt kalır; bu yüzden ActorID yabancı anahtar değildir ve aktör adı kopyalanır.
+type AuditLog struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ActorID    *uuid.UUID `gorm:"type:uuid;index"`
+	ActorName  string     `gorm:"size:255"` // Kullanıcı adı; başarısız girişte denenen e-posta
+	Action     string     `gorm:"size:64;index"`
+	TargetType string     `gorm:"size:32"`
+	TargetID   string     `gorm:"size:64;index"`
+	IPAddress  string     `gorm:"size:64"`
+	UserAgent  string     `gorm:"size:512"`
+	// Değişen alanlar {"alan": {"from": ..., "to": ...}} ya da eyleme özgü ek bilgiler
+	Diff      json.RawMessage `gorm:"type:jsonb"`
+	CreatedAt time.Time       `gorm:"index"`
+}
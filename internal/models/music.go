@@ -4,6 +4,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Düzenleme formunda sunulan ve PATCH isteğinde kabul edilen değerler. Boş değer "belirtilmemiş" demektir.
+var (
+	MusicMoods    = []string{"calm", "happy", "melancholic", "energetic", "dark", "epic", "romantic", "mysterious", "playful"}
+	MusicLicenses = []string{"all-rights-reserved", "cc-by", "cc-by-sa", "cc-by-nc", "cc-by-nc-sa", "cc0"}
 )
 
 type Music struct {
@@ -12,14 +19,22 @@ type Music struct {
 	Mp3FilePath  string
 	MidiFilePath string
 	CoverArtPath string
+	Description  string     `gorm:"type:text"` // Markdown; görüntülenirken sanitize edilir
+	Mood         string     `gorm:"size:32"`
+	License      string     `gorm:"size:32"`
+	Tags         []Tag      `gorm:"many2many:music_tags;"`
 	LikesCount   int        `gorm:"default:0"`
 	IsPublic     bool       `gorm:"default:false"`
+	PublishedAt  *time.Time `gorm:"index"` // İlk yayınlanma anı; tekrar gizliye alınıp açılınca değişmez
 	UserID       *uuid.UUID `gorm:"type:uuid"`
 	User         User
-	MusicTypeID  uuid.UUID `gorm:"type:uuid"`
-	MusicType    MusicType
-	ModelTypeID  uuid.UUID `gorm:"type:uuid"`
-	ModelType    ModelType
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	// Giriş yapmadan üretilen parçalarda tarayıcının anonim oturum ID'si; sahiplenilince temizlenir
+	AnonymousSessionID *uuid.UUID `gorm:"type:uuid;index"`
+	MusicTypeID        uuid.UUID  `gorm:"type:uuid"`
+	MusicType          MusicType
+	ModelTypeID        uuid.UUID `gorm:"type:uuid"`
+	ModelType          ModelType
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	DeletedAt          gorm.DeletedAt `gorm:"index"` // Soft delete: silinen parçalar saklama süresi boyunca geri alınabilir
 }
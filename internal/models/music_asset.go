@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AssetKindWaveform = "waveform"
+
+	AssetFormatJSON   = "json"
+	AssetFormatBinary = "bin"
+)
+
+// MusicAsset, bir müzikten türetilmiş ve depolamada tutulan ek dosyaları (örn. waveform peak verisi) tanımlar.
+type MusicAsset struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	MusicID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_music_assets_variant"`
+	Music      Music     `gorm:"foreignKey:MusicID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Kind       string    `gorm:"not null;uniqueIndex:idx_music_assets_variant"`
+	Format     string    `gorm:"not null;uniqueIndex:idx_music_assets_variant"`
+	Resolution int       `gorm:"not null;default:0;uniqueIndex:idx_music_assets_variant"`
+	StorageKey string    `gorm:"not null"`
+	SizeBytes  int64
+	CreatedAt  time.Time
+}
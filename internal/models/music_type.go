@@ -1,8 +1,9 @@
 package models
 
-import "github.com/google/uuid"
-
 type MusicType struct {
-	ID   uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	Name string    `gorm:"unique"`
+	CatalogEntry
+}
+
+func (m *MusicType) Entry() *CatalogEntry {
+	return &m.CatalogEntry
 }
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResetToken e-postayla gönderilen tek kullanımlık şifre sıfırlama linkidir.
+// Linkteki gizli değer saklanmaz; sadece SHA-256 özeti tutulur.
+type PasswordResetToken struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index"`
+	User        User      `gorm:"constraint:OnDelete:CASCADE;"`
+	TokenHash   string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt   time.Time `gorm:"index"`
+	UsedAt      *time.Time
+	RequestedIP string `gorm:"size:64"`
+	CreatedAt   time.Time
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Çalma listesi görünürlükleri. Liste dışı (unlisted) listeler bağlantıyı bilen herkese açıktır
+// ancak profilde listelenmez.
+const (
+	PlaylistPublic   = "public"
+	PlaylistUnlisted = "unlisted"
+	PlaylistPrivate  = "private"
+)
+
+var PlaylistVisibilities = []string{PlaylistPublic, PlaylistUnlisted, PlaylistPrivate}
+
+type Playlist struct {
+	ID          uuid.UUID       `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID      uuid.UUID       `gorm:"type:uuid;not null;index"`
+	User        User            `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Title       string          `gorm:"size:100;not null"`
+	Description string          `gorm:"type:text"` // Düz metin; görüntülenirken escape edilir
+	Visibility  string          `gorm:"size:16;not null;default:private;index"`
+	CoverKey    string          `gorm:"size:255"` // Storage anahtarı; boşsa ilk parçanın kapağı kullanılır
+	Entries     []PlaylistEntry `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// IsViewableBy listenin sayfasının verilen kullanıcıya (anonim ise uuid.Nil) gösterilip gösterilemeyeceğini bildirir.
+func (p *Playlist) IsViewableBy(userID uuid.UUID) bool {
+	return p.Visibility != PlaylistPrivate || (userID != uuid.Nil && p.UserID == userID)
+}
+
+// PlaylistEntry listedeki bir parçadır; Position listedeki sırayı belirler (0'dan başlar).
+type PlaylistEntry struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	PlaylistID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_playlist_entry_music"`
+	MusicID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_playlist_entry_music;index"`
+	Music      Music     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Position   int       `gorm:"not null;default:0"`
+	CreatedAt  time.Time
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryCode authenticator cihazı kaybolduğunda TOTP kodu yerine bir kez kullanılabilen koddur.
+// Kodun kendisi saklanmaz, bcrypt özeti tutulur.
+type RecoveryCode struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	User      User      `gorm:"constraint:OnDelete:CASCADE;"`
+	CodeHash  string    `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
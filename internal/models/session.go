@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session sunucu tarafındaki oturum kaydıdır. ID, JWT'nin jti alanında taşınır;
+// RevokedAt dolu veya ExpiresAt geçmiş olan oturumlara ait token'lar kabul edilmez.
+// Oturum aynı zamanda refresh token ailesidir: yalnızca güncel refresh token'ın özeti geçerlidir.
+type Session struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	User       User      `gorm:"constraint:OnDelete:CASCADE;"`
+	UserAgent  string    `gorm:"size:512"`
+	IPAddress  string    `gorm:"size:64"`
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time `gorm:"index"`
+	RevokedAt  *time.Time
+
+	RefreshTokenHash string `gorm:"size:64"`
+	// Eşzamanlı isteklerin aynı refresh token'ı kullanabilmesi için bir önceki özet kısa süre kabul edilir
+	PreviousRefreshHash string `gorm:"size:64"`
+	RefreshRotatedAt    *time.Time
+}
+
+// IsActive oturumun iptal edilmemiş ve süresinin dolmamış olduğunu bildirir.
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
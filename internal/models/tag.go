@@ -0,0 +1,36 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxTagLength      = 30
+	MaxTagsPerMusic   = 10
+	MaxDescriptionLen = 5000
+)
+
+var tagNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Tag kullanıcıların müziklere eklediği serbest etiketlerdir. Name her zaman normalize edilmiş haldedir.
+type Tag struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name      string    `gorm:"size:30;uniqueIndex;not null"`
+	CreatedAt time.Time
+}
+
+// NormalizeTagName etiketi küçük harfe çevirir, boşlukları tireyle değiştirir.
+// Geçersiz bir etiket için false döner.
+func NormalizeTagName(raw string) (string, bool) {
+	name := strings.ToLower(strings.TrimSpace(raw))
+	name = strings.TrimPrefix(name, "#")
+	name = strings.Join(strings.Fields(name), "-")
+	if name == "" || len(name) > MaxTagLength || !tagNamePattern.MatchString(name) {
+		return "", false
+	}
+	return name, true
+}
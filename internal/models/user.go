@@ -6,11 +6,84 @@ import (
 	"github.com/google/uuid"
 )
 
+// Kullanıcı rolleri. Roller hiyerarşiktir: admin, moderator yetkilerini de kapsar.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+var Roles = []string{RoleUser, RoleModerator, RoleAdmin}
+
+var roleRanks = map[string]int{RoleUser: 1, RoleModerator: 2, RoleAdmin: 3}
+
+// IsValidRole rolün tanımlı rollerden biri olup olmadığını bildirir.
+func IsValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAtLeast role'ün required rolünü (veya daha yetkili bir rolü) karşılayıp karşılamadığını bildirir.
+func RoleAtLeast(role, required string) bool {
+	rank, ok := roleRanks[role]
+	return ok && rank >= roleRanks[required]
+}
+
 type User struct {
-	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	Username     string    `gorm:"uniqueIndex;not null"`
-	Email        string    `gorm:"uniqueIndex;not null"`
-	PasswordHash string    `gorm:"not null"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID           uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Username     string     `gorm:"uniqueIndex;not null"`
+	Email        string     `gorm:"uniqueIndex;not null"`
+	PasswordHash string     `gorm:"not null"`
+	Role         string     `gorm:"size:16;not null;default:user;index"`
+	SuspendedAt  *time.Time // Dolu ise hesap askıdadır: giriş yapılamaz, oturumlar yenilenemez
+	QuotaResetAt *time.Time // Günlük üretim kotası bu andan itibaren sayılır (admin sıfırlaması)
+	// Boş ise e-posta doğrulanmamıştır: parça yayınlanamaz, üretim kotası düşüktür
+	EmailVerifiedAt *time.Time
+
+	Bio       string `gorm:"size:500"`
+	AvatarKey string `gorm:"size:255"`               // Profil fotoğrafının storage anahtarı; boşsa varsayılan avatar gösterilir
+	ShowLikes bool   `gorm:"not null;default:false"` // Beğenilen parçalar herkese açık profilde gösterilsin mi (opt-in)
+
+	// İki adımlı doğrulama: TOTP secret'ı şifreli saklanır. TOTPEnabledAt boşken secret sadece
+	// kurulum onayı bekleyen geçici değerdir. TOTPLastCounter aynı kodun tekrar kullanılmasını engeller.
+	TOTPSecret      string `gorm:"size:255"`
+	TOTPEnabledAt   *time.Time
+	TOTPLastCounter int64
+
+	// Başarısız giriş takibi: pencere içindeki ardışık hatalı şifre sayısı ve eşik aşılınca dolan kilit süresi
+	FailedLoginCount  int `gorm:"not null;default:0"`
+	LastFailedLoginAt *time.Time
+	LockedUntil       *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// QuotaWindowStart günlük üretim kotasının sayılmaya başladığı andır: son 24 saat
+// ya da daha yeniyse admin tarafından yapılan son sıfırlama.
+func (u *User) QuotaWindowStart(now time.Time) time.Time {
+	since := now.Add(-24 * time.Hour)
+	if u.QuotaResetAt != nil && u.QuotaResetAt.After(since) {
+		return *u.QuotaResetAt
+	}
+	return since
+}
+
+// IsEmailVerified e-posta adresinin doğrulanmış olup olmadığını bildirir.
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// TwoFactorEnabled girişte ikinci adım olarak TOTP kodu istenip istenmediğini bildirir.
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil && u.TOTPSecret != ""
+}
+
+// IsLocked hesabın çok sayıda hatalı giriş denemesi nedeniyle geçici olarak kilitli olup olmadığını bildirir.
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// IsSuspended hesabın askıya alınmış olup olmadığını bildirir.
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
 }
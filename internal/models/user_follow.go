@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserFollow, bir kullanıcının (Follower) başka bir kullanıcıyı (Followee) takip ettiğini gösteren ara tablo.
+type UserFollow struct {
+	FollowerID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	FolloweeID uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	Follower   User      `gorm:"foreignKey:FollowerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Followee   User      `gorm:"foreignKey:FolloweeID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt  time.Time
+}
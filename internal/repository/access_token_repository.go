@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+)
+
+type AccessTokenRepository interface {
+	Create(token *models.PersonalAccessToken) error
+	GetByHash(tokenHash string) (*models.PersonalAccessToken, error)
+	ListForUser(userID uuid.UUID) ([]models.PersonalAccessToken, error)
+	CountActiveForUser(userID uuid.UUID, now time.Time) (int64, error)
+	Revoke(userID, id uuid.UUID, now time.Time) (bool, error)
+	Touch(id uuid.UUID, at time.Time, ip string, minInterval time.Duration) error
+}
+
+type accessTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewAccessTokenRepository(db *gorm.DB) AccessTokenRepository {
+	return &accessTokenRepo{db: db}
+}
+
+func (r *accessTokenRepo) Create(token *models.PersonalAccessToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetByHash token'ı sahibiyle birlikte yükler; bulunamazsa gorm.ErrRecordNotFound döner.
+func (r *accessTokenRepo) GetByHash(tokenHash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	if err := r.db.Preload("User").Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListForUser iptal edilmemiş token'ları (süresi dolmuş olanlar dahil) yeniden eskiye döner.
+func (r *accessTokenRepo) ListForUser(userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).Order("created_at DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+func (r *accessTokenRepo) CountActiveForUser(userID uuid.UUID, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, now).
+		Count(&count).Error
+	return count, err
+}
+
+// Revoke kullanıcının token'ını iptal eder; token yoksa, başkasına aitse ya da zaten iptal edildiyse false döner.
+func (r *accessTokenRepo) Revoke(userID, id uuid.UUID, now time.Time) (bool, error) {
+	result := r.db.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", now)
+	return result.RowsAffected == 1, result.Error
+}
+
+// Touch son kullanım zamanını ve IP'yi yazar. Sık kullanılan token'lar için en fazla minInterval'da bir yazılır.
+func (r *accessTokenRepo) Touch(id uuid.UUID, at time.Time, ip string, minInterval time.Duration) error {
+	return r.db.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, at.Add(-minInterval)).
+		Updates(map[string]interface{}{"last_used_at": at, "last_used_ip": ip}).Error
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+)
+
+// AnalyticsRepository admin paneli için toplu (aggregate) sorguları içerir.
+// Tüm aralıklar [from, to) biçimindedir.
+type AnalyticsRepository interface {
+	GenerationsPerDay(from, to time.Time) ([]DailyGenerationCount, error)
+	GenerationOutcomes(from, to time.Time) ([]OutcomeCount, error)
+	MedianGenerationLatency(from, to time.Time) (float64, error)
+	SignupsPerDay(from, to time.Time) ([]DailyCount, error)
+	ActiveUsers(from, to time.Time) (int64, error)
+	LikesCount(from, to time.Time) (int64, error)
+	PublicTrackCounts(from, to time.Time) (total int64, created int64, err error)
+}
+
+// DailyGenerationCount bir gündeki model ve müzik türü bazında üretim sayısıdır.
+type DailyGenerationCount struct {
+	Day           time.Time
+	ModelTypeName string
+	MusicTypeName string
+	Count         int64
+}
+
+type OutcomeCount struct {
+	Status string
+	Count  int64
+}
+
+type DailyCount struct {
+	Day   time.Time
+	Count int64
+}
+
+type analyticsRepo struct {
+	db *gorm.DB
+}
+
+func NewAnalyticsRepository(db *gorm.DB) AnalyticsRepository {
+	return &analyticsRepo{db: db}
+}
+
+func (r *analyticsRepo) GenerationsPerDay(from, to time.Time) ([]DailyGenerationCount, error) {
+	var rows []DailyGenerationCount
+	err := r.db.Model(&models.Generation{}).
+		Select("date_trunc('day', created_at) AS day, model_type_name, music_type_name, COUNT(*) AS count").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("day, model_type_name, music_type_name").
+		Order("day asc, count desc").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *analyticsRepo) GenerationOutcomes(from, to time.Time) ([]OutcomeCount, error) {
+	var rows []OutcomeCount
+	err := r.db.Model(&models.Generation{}).
+		Select("status, COUNT(*) AS count").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("status").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// MedianGenerationLatency yalnızca başarılı üretimlerin medyan süresini (ms) döner; kayıt yoksa 0.
+func (r *analyticsRepo) MedianGenerationLatency(from, to time.Time) (float64, error) {
+	var median sql.NullFloat64
+	err := r.db.Model(&models.Generation{}).
+		Select("percentile_cont(0.5) WITHIN GROUP (ORDER BY latency_ms)").
+		Where("status = ? AND created_at >= ? AND created_at < ?", models.GenerationSucceeded, from, to).
+		Row().Scan(&median)
+	if err != nil {
+		return 0, err
+	}
+	return median.Float64, nil
+}
+
+func (r *analyticsRepo) SignupsPerDay(from, to time.Time) ([]DailyCount, error) {
+	var rows []DailyCount
+	err := r.db.Model(&models.User{}).
+		Select("date_trunc('day', created_at) AS day, COUNT(*) AS count").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("day").
+		Order("day asc").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// ActiveUsers aralıkta oturumu kullanılan ya da üretim yapan farklı kullanıcı sayısıdır.
+// Süresi dolan oturumlar silindiği için eski aralıklarda sayı üretimlere dayanır.
+func (r *analyticsRepo) ActiveUsers(from, to time.Time) (int64, error) {
+	var count int64
+	err := r.db.Raw(`SELECT COUNT(DISTINCT user_id) FROM (
+			SELECT user_id FROM sessions WHERE last_seen_at >= ? AND created_at < ?
+			UNION
+			SELECT user_id FROM generations WHERE user_id IS NOT NULL AND created_at >= ? AND created_at < ?
+		) AS active`, from, to, from, to).
+		Scan(&count).Error
+	return count, err
+}
+
+func (r *analyticsRepo) LikesCount(from, to time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserLikesMusic{}).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Count(&count).Error
+	return count, err
+}
+
+// PublicTrackCounts şu an herkese açık olan toplam parça sayısını ve bunların aralıkta oluşturulanlarını döner.
+func (r *analyticsRepo) PublicTrackCounts(from, to time.Time) (total int64, created int64, err error) {
+	if err = r.db.Model(&models.Music{}).Where("is_public = ?", true).Count(&total).Error; err != nil {
+		return 0, 0, err
+	}
+	err = r.db.Model(&models.Music{}).
+		Where("is_public = ? AND created_at >= ? AND created_at < ?", true, from, to).
+		Count(&created).Error
+	return total, created, err
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository interface {
+	Create(entry *models.AuditLog) error
+	Query(filter AuditLogFilter, page, perPage int) ([]models.AuditLog, int64, error)
+}
+
+// AuditLogFilter boş alanlar filtre uygulanmaz demektir. To hariçtir.
+type AuditLogFilter struct {
+	Action   string
+	Actor    string // Aktör ID'si ya da adında geçen ifade
+	TargetID string
+	From     time.Time
+	To       time.Time
+}
+
+type auditLogRepo struct {
+	*GenericRepository[models.AuditLog]
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepo{
+		GenericRepository: NewGenericRepository[models.AuditLog](db),
+		db:                db,
+	}
+}
+
+// Query filtreye uyan kayıtları en yeniden eskiye sayfalı döner.
+func (r *auditLogRepo) Query(filter AuditLogFilter, page, perPage int) ([]models.AuditLog, int64, error) {
+	session := r.db.Model(&models.AuditLog{})
+	if filter.Action != "" {
+		session = session.Where("action = ?", filter.Action)
+	}
+	if actor := strings.TrimSpace(filter.Actor); actor != "" {
+		if actorID, err := uuid.Parse(actor); err == nil {
+			session = session.Where("actor_id = ?", actorID)
+		} else {
+			session = session.Where("LOWER(actor_name) LIKE ?", "%"+strings.ToLower(actor)+"%")
+		}
+	}
+	if filter.TargetID != "" {
+		session = session.Where("target_id = ?", strings.TrimSpace(filter.TargetID))
+	}
+	if !filter.From.IsZero() {
+		session = session.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		session = session.Where("created_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := session.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var entries []models.AuditLog
+	err := session.Order("created_at desc").Offset((page - 1) * perPage).Limit(perPage).Find(&entries).Error
+	return entries, total, err
+}
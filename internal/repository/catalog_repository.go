@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CatalogRepository müzik türü ve model kataloglarının ortak işlemleridir.
+type CatalogRepository[T any] interface {
+	Create(entry *T) error
+	GetByID(id any, entry *T) error
+	GetByName(name string) (*T, error)
+	GetAll() ([]T, error)
+	ListEnabled() ([]T, error)
+	Update(id uuid.UUID, fields map[string]interface{}) error
+	SetEnabled(id uuid.UUID, enabled bool) error
+}
+
+type catalogRepo[T any] struct {
+	*GenericRepository[T]
+	db *gorm.DB
+}
+
+func newCatalogRepo[T any](db *gorm.DB) *catalogRepo[T] {
+	return &catalogRepo[T]{
+		GenericRepository: NewGenericRepository[T](db),
+		db:                db,
+	}
+}
+
+// Create tüm alanları yazar; aksi halde false olan Enabled, veritabanı varsayılanı (true) ile ezilirdi.
+func (r *catalogRepo[T]) Create(entry *T) error {
+	return r.db.Select("*").Create(entry).Error
+}
+
+func (r *catalogRepo[T]) GetByName(name string) (*T, error) {
+	var entry T
+	if err := r.db.Where("name = ?", name).First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetAll devre dışı olanlar dahil tüm kayıtları sıralı döner (filtreler ve admin paneli için).
+func (r *catalogRepo[T]) GetAll() ([]T, error) {
+	var entries []T
+	if err := r.db.Order("sort_order asc, name asc").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ListEnabled üretimde seçilebilecek kayıtları döner.
+func (r *catalogRepo[T]) ListEnabled() ([]T, error) {
+	var entries []T
+	if err := r.db.Where("enabled = ?", true).Order("sort_order asc, name asc").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *catalogRepo[T]) Update(id uuid.UUID, fields map[string]interface{}) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return r.db.Model(new(T)).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *catalogRepo[T]) SetEnabled(id uuid.UUID, enabled bool) error {
+	return r.db.Model(new(T)).Where("id = ?", id).Update("enabled", enabled).Error
+}
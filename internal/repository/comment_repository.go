@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+)
+
+type CommentRepository interface {
+	Create(comment *models.Comment) error
+	GetByID(id uuid.UUID) (*models.Comment, error)
+	UpdateBody(id uuid.UUID, body string, timestampSeconds *int, editedAt time.Time) error
+	Delete(id uuid.UUID) error
+	ListThreads(musicID uuid.UUID, before *CommentCursor, limit int) ([]models.Comment, error)
+	CountByMusic(musicID uuid.UUID) (int64, error)
+}
+
+// CommentCursor yorum sayfalamasında son görülen üst düzey yorumdur.
+type CommentCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+type commentRepo struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepo{db: db}
+}
+
+// Askıya alınan hesapların yorumları silinmez ama gösterilmez; askı kaldırılınca geri gelirler.
+const visibleCommentAuthor = "comments.user_id IN (SELECT id FROM users WHERE suspended_at IS NULL)"
+
+func (r *commentRepo) Create(comment *models.Comment) error {
+	return r.db.Create(comment).Error
+}
+
+func (r *commentRepo) GetByID(id uuid.UUID) (*models.Comment, error) {
+	var comment models.Comment
+	if err := r.db.Preload("User").First(&comment, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *commentRepo) UpdateBody(id uuid.UUID, body string, timestampSeconds *int, editedAt time.Time) error {
+	return r.db.Model(&models.Comment{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"body":              body,
+		"timestamp_seconds": timestampSeconds,
+		"edited_at":         editedAt,
+	}).Error
+}
+
+// Delete yorumu siler; üst düzey bir yorum silinirse altındaki yanıtlar da silinir.
+func (r *commentRepo) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("parent_id = ?", id).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Comment{}, "id = ?", id).Error
+	})
+}
+
+// ListThreads parçanın üst düzey yorumlarını en yeni önce gelecek şekilde, yanıtlarıyla (eskiden yeniye) birlikte döner.
+// before verilirse o yorumdan sonrakiler döner (keyset sayfalama).
+func (r *commentRepo) ListThreads(musicID uuid.UUID, before *CommentCursor, limit int) ([]models.Comment, error) {
+	var comments []models.Comment
+	session := r.db.Where("comments.music_id = ? AND comments.parent_id IS NULL", musicID).Where(visibleCommentAuthor)
+	if before != nil {
+		session = session.Where("(comments.created_at, comments.id) < (?, ?)", before.CreatedAt, before.ID)
+	}
+	err := session.Order("comments.created_at desc, comments.id desc").Limit(limit).
+		Preload("User").
+		Preload("Replies", func(db *gorm.DB) *gorm.DB {
+			return db.Where(visibleCommentAuthor).Order("comments.created_at asc, comments.id asc")
+		}).
+		Preload("Replies.User").
+		Find(&comments).Error
+	return comments, err
+}
+
+// CountByMusic parçanın görünen yorum sayısını (yanıtlar dahil) döner.
+func (r *commentRepo) CountByMusic(musicID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Comment{}).Where("comments.music_id = ?", musicID).Where(visibleCommentAuthor).Count(&count).Error
+	return count, err
+}
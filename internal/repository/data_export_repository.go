@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+)
+
+type DataExportRepository interface {
+	Create(export *models.DataExport) error
+	GetForUser(userID, id uuid.UUID) (*models.DataExport, error)
+	ListForUser(userID uuid.UUID, limit int) ([]models.DataExport, error)
+	LatestForUser(userID uuid.UUID) (*models.DataExport, error)
+	Claim(id uuid.UUID, now time.Time) (bool, error)
+	ListPending(limit int) ([]models.DataExport, error)
+	ResetStale(startedBefore time.Time) (int64, error)
+	MarkReady(id uuid.UUID, archiveName string, size int64, now, expiresAt time.Time) error
+	MarkFailed(id uuid.UUID, message string, now time.Time) error
+	ListExpired(now time.Time, limit int) ([]models.DataExport, error)
+	ListArchivesForUser(userID uuid.UUID) ([]string, error)
+	Delete(id uuid.UUID) error
+	Snapshot(userID uuid.UUID) (*UserDataSnapshot, error)
+}
+
+// UserDataSnapshot dışa aktarılan arşive giren, kullanıcıyla ilişkili tüm kayıtlardır.
+type UserDataSnapshot struct {
+	User         models.User
+	Music        []models.Music // Çöp kutusundakiler dahil
+	Likes        []models.UserLikesMusic
+	Following    []models.UserFollow
+	Playlists    []models.Playlist // Entries sırasıyla yüklenir
+	Comments     []models.Comment
+	Generations  []models.Generation
+	Sessions     []models.Session
+	Identities   []models.Identity
+	AccessTokens []models.PersonalAccessToken
+	AuditLogs    []models.AuditLog // Kullanıcının aktör olduğu kayıtlar
+}
+
+type dataExportRepo struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository(db *gorm.DB) DataExportRepository {
+	return &dataExportRepo{db: db}
+}
+
+func (r *dataExportRepo) Create(export *models.DataExport) error {
+	return r.db.Create(export).Error
+}
+
+// GetForUser kullanıcının kendi arşivini döner; başkasına aitse gorm.ErrRecordNotFound döner.
+func (r *dataExportRepo) GetForUser(userID, id uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *dataExportRepo) ListForUser(userID uuid.UUID, limit int) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Find(&exports).Error
+	return exports, err
+}
+
+// LatestForUser en son istenen arşivi döner; hiç yoksa gorm.ErrRecordNotFound döner.
+func (r *dataExportRepo) LatestForUser(userID uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// Claim bekleyen işi işleniyor olarak işaretler. İş başka bir worker tarafından alındıysa false döner.
+func (r *dataExportRepo) Claim(id uuid.UUID, now time.Time) (bool, error) {
+	result := r.db.Model(&models.DataExport{}).
+		Where("id = ? AND status = ?", id, models.DataExportPending).
+		Updates(map[string]interface{}{"status": models.DataExportProcessing, "started_at": now})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *dataExportRepo) ListPending(limit int) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.Where("status = ?", models.DataExportPending).Order("created_at").Limit(limit).Find(&exports).Error
+	return exports, err
+}
+
+// ResetStale uygulama yeniden başlarken yarım kalan işleri tekrar kuyruğa alır.
+func (r *dataExportRepo) ResetStale(startedBefore time.Time) (int64, error) {
+	result := r.db.Model(&models.DataExport{}).
+		Where("status = ? AND started_at < ?", models.DataExportProcessing, startedBefore).
+		Updates(map[string]interface{}{"status": models.DataExportPending, "started_at": nil})
+	return result.RowsAffected, result.Error
+}
+
+// MarkReady kayıt bu arada silindiyse (hesap silme) gorm.ErrRecordNotFound döner; çağıran arşivi kaldırmalıdır.
+func (r *dataExportRepo) MarkReady(id uuid.UUID, archiveName string, size int64, now, expiresAt time.Time) error {
+	result := r.db.Model(&models.DataExport{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       models.DataExportReady,
+		"archive_name": archiveName,
+		"size_bytes":   size,
+		"completed_at": now,
+		"expires_at":   expiresAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *dataExportRepo) MarkFailed(id uuid.UUID, message string, now time.Time) error {
+	return r.db.Model(&models.DataExport{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       models.DataExportFailed,
+		"error":        message,
+		"completed_at": now,
+	}).Error
+}
+
+// ListExpired süresi dolmuş hazır arşivleri ve bir günden eski başarısız işleri döner.
+func (r *dataExportRepo) ListExpired(now time.Time, limit int) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.Where("(status = ? AND expires_at < ?) OR (status = ? AND completed_at < ?)",
+		models.DataExportReady, now, models.DataExportFailed, now.Add(-24*time.Hour)).
+		Limit(limit).Find(&exports).Error
+	return exports, err
+}
+
+// ListArchivesForUser hesap silinirken kaldırılacak arşiv dosyalarının adlarını döner.
+func (r *dataExportRepo) ListArchivesForUser(userID uuid.UUID) ([]string, error) {
+	var names []string
+	err := r.db.Model(&models.DataExport{}).Where("user_id = ? AND archive_name <> ''", userID).Pluck("archive_name", &names).Error
+	return names, err
+}
+
+func (r *dataExportRepo) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.DataExport{}, "id = ?", id).Error
+}
+
+// Snapshot arşiv için kullanıcının tüm kayıtlarını okur. Tek bir salt okunur transaction içinde
+// çalışır, böylece arşiv tutarlı bir anın görüntüsüdür.
+func (r *dataExportRepo) Snapshot(userID uuid.UUID) (*UserDataSnapshot, error) {
+	snapshot := &UserDataSnapshot{}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&snapshot.User, "id = ?", userID).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Preload("Tags").Preload("MusicType").Preload("ModelType").
+			Where("user_id = ?", userID).Order("created_at").Find(&snapshot.Music).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Music", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+			Where("user_id = ?", userID).Order("created_at").Find(&snapshot.Likes).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Followee").Where("follower_id = ?", userID).Order("created_at").Find(&snapshot.Following).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Entries", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+			Preload("Entries.Music", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+			Where("user_id = ?", userID).Order("created_at").Find(&snapshot.Playlists).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Music", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+			Where("user_id = ?", userID).Order("created_at").Find(&snapshot.Comments).Error; err != nil {
+			return err
+		}
+		queries := []struct {
+			dest  interface{}
+			model interface{}
+			where string
+		}{
+			{&snapshot.Generations, &models.Generation{}, "user_id = ?"},
+			{&snapshot.Sessions, &models.Session{}, "user_id = ?"},
+			{&snapshot.Identities, &models.Identity{}, "user_id = ?"},
+			{&snapshot.AccessTokens, &models.PersonalAccessToken{}, "user_id = ?"},
+			{&snapshot.AuditLogs, &models.AuditLog{}, "actor_id = ?"},
+		}
+		for _, q := range queries {
+			if err := tx.Model(q.model).Where(q.where, userID).Order("created_at").Find(q.dest).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
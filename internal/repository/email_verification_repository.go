@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmailVerificationRepository interface {
+	Create(token *models.EmailVerificationToken) error
+	Consume(tokenHash string, now time.Time) (*models.EmailVerificationToken, error)
+	InvalidateForUser(userID uuid.UUID, now time.Time) (int64, error)
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type emailVerificationRepo struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationRepository(db *gorm.DB) EmailVerificationRepository {
+	return &emailVerificationRepo{db: db}
+}
+
+func (r *emailVerificationRepo) Create(token *models.EmailVerificationToken) error {
+	return r.db.Create(token).Error
+}
+
+// Consume token'ı kullanılmış olarak işaretleyip döner. Geçersiz, kullanılmış veya süresi dolmuş
+// token'lar için gorm.ErrRecordNotFound döner.
+func (r *emailVerificationRepo) Consume(tokenHash string, now time.Time) (*models.EmailVerificationToken, error) {
+	var tokens []models.EmailVerificationToken
+	result := r.db.Model(&tokens).Clauses(clause.Returning{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || len(tokens) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &tokens[0], nil
+}
+
+// InvalidateForUser kullanıcının bekleyen doğrulama linklerini geçersiz kılar.
+func (r *emailVerificationRepo) InvalidateForUser(userID uuid.UUID, now time.Time) (int64, error) {
+	result := r.db.Model(&models.EmailVerificationToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", now)
+	return result.RowsAffected, result.Error
+}
+
+// DeleteExpired süresi dolmuş ya da kullanılmış token'ları siler.
+func (r *emailVerificationRepo) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ? OR used_at IS NOT NULL", now).Delete(&models.EmailVerificationToken{})
+	return result.RowsAffected, result.Error
+}
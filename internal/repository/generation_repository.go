@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+)
+
+type GenerationRepository interface {
+	Create(generation *models.Generation) error
+	CountSucceededSince(userID uuid.UUID, since time.Time) (int64, error)
+}
+
+type generationRepo struct {
+	*GenericRepository[models.Generation]
+	db *gorm.DB
+}
+
+func NewGenerationRepository(db *gorm.DB) GenerationRepository {
+	return &generationRepo{
+		GenericRepository: NewGenericRepository[models.Generation](db),
+		db:                db,
+	}
+}
+
+// CountSucceededSince kullanıcının since'ten bu yana başarılı üretim sayısını döner (kota hesabı).
+func (r *generationRepo) CountSucceededSince(userID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Generation{}).
+		Where("user_id = ? AND status = ? AND created_at >= ?", userID, models.GenerationSucceeded, since).
+		Count(&count).Error
+	return count, err
+}
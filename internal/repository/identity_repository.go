@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+)
+
+type IdentityRepository interface {
+	Create(identity *models.Identity) error
+	FindBySubject(issuer, subject string) (*models.Identity, error)
+	ListForUser(userID uuid.UUID) ([]models.Identity, error)
+	TouchLogin(id uuid.UUID, email string, at time.Time) error
+}
+
+type identityRepo struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepo{db: db}
+}
+
+func (r *identityRepo) Create(identity *models.Identity) error {
+	return r.db.Create(identity).Error
+}
+
+// FindBySubject sağlayıcıdaki hesaba bağlı kimliği döner; bağlı değilse gorm.ErrRecordNotFound döner.
+func (r *identityRepo) FindBySubject(issuer, subject string) (*models.Identity, error) {
+	var identity models.Identity
+	if err := r.db.Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *identityRepo) ListForUser(userID uuid.UUID) ([]models.Identity, error) {
+	var identities []models.Identity
+	err := r.db.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error
+	return identities, err
+}
+
+// TouchLogin son giriş zamanını ve sağlayıcının bildirdiği güncel e-postayı yazar.
+func (r *identityRepo) TouchLogin(id uuid.UUID, email string, at time.Time) error {
+	return r.db.Model(&models.Identity{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"email": email, "last_login_at": at}).Error
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MusicAssetRepository interface {
+	Upsert(asset *models.MusicAsset) error
+	GetForMusic(musicID uuid.UUID, kind string) ([]models.MusicAsset, error)
+	DeleteForMusic(musicID uuid.UUID) error
+	ListStorageKeys() ([]string, error)
+}
+
+type musicAssetRepo struct {
+	db *gorm.DB
+}
+
+func NewMusicAssetRepository(db *gorm.DB) MusicAssetRepository {
+	return &musicAssetRepo{db: db}
+}
+
+// Upsert aynı müzik/tür/format/çözünürlük için kayıt varsa depolama bilgisini günceller.
+func (r *musicAssetRepo) Upsert(asset *models.MusicAsset) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "music_id"}, {Name: "kind"}, {Name: "format"}, {Name: "resolution"}},
+		DoUpdates: clause.AssignmentColumns([]string{"storage_key", "size_bytes", "created_at"}),
+	}).Create(asset).Error
+}
+
+func (r *musicAssetRepo) GetForMusic(musicID uuid.UUID, kind string) ([]models.MusicAsset, error) {
+	var assets []models.MusicAsset
+	err := r.db.Where("music_id = ? AND kind = ?", musicID, kind).Order("resolution asc").Find(&assets).Error
+	return assets, err
+}
+
+func (r *musicAssetRepo) DeleteForMusic(musicID uuid.UUID) error {
+	return r.db.Where("music_id = ?", musicID).Delete(&models.MusicAsset{}).Error
+}
+
+func (r *musicAssetRepo) ListStorageKeys() ([]string, error) {
+	var keys []string
+	err := r.db.Model(&models.MusicAsset{}).Pluck("storage_key", &keys).Error
+	return keys, err
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/morgarakt/aurify/internal/models" // Projenizin model yolu
@@ -16,6 +17,9 @@ type MusicQueryParams struct {
 	SearchQuery     string
 	MusicTypeFilter string
 	SortBy          string
+	Tags            []string   // Normalize edilmiş etiketler; müzik hepsine sahip olmalı
+	CreatorID       *uuid.UUID // Sadece QueryPublicMusic: verilen kullanıcının parçaları
+	LikedByID       *uuid.UUID // Sadece QueryPublicMusic: verilen kullanıcının beğendiği parçalar
 	Page            int
 	PerPage         int
 }
@@ -31,6 +35,22 @@ type MusicRepository interface {
 	QueryPublicMusic(params MusicQueryParams) ([]models.Music, int64, error)
 	UpdateLikesCount(musicID uuid.UUID, change int) error
 	UpdateVisibility(musicID uuid.UUID, isPublic bool) error
+	ListFileReferences() ([]string, error)
+	SoftDelete(musicID uuid.UUID) error
+	Restore(musicID uuid.UUID) error
+	GetDeletedByID(id uuid.UUID) (*models.Music, error)
+	QueryUserDeletedMusic(userID uuid.UUID, deletedAfter time.Time, page, perPage int) ([]models.Music, int64, error)
+	ListDeletedBefore(before time.Time, limit int) ([]models.Music, error)
+	HardDelete(musicID uuid.UUID) error
+	ClaimAnonymous(sessionID, userID uuid.UUID, createdAfter time.Time) (int64, error)
+	UpdateMetadata(musicID uuid.UUID, fields map[string]interface{}, tags *[]models.Tag) error
+	QueryFollowingFeed(followerID uuid.UUID, after *FeedCursor, limit int) ([]models.Music, error)
+}
+
+// FeedCursor takip akışında son gösterilen parçanın konumudur; sonraki sayfa bu parçadan sonrasıdır.
+type FeedCursor struct {
+	PublishedAt time.Time
+	ID          uuid.UUID
 }
 
 type musicRepo struct {
@@ -55,7 +75,9 @@ func (r *musicRepo) Delete(music *models.Music) error {
 
 func (r *musicRepo) GetByIDWithRelations(id uuid.UUID) (*models.Music, error) {
 	var music models.Music
-	err := r.db.Preload("User").Preload("MusicType").Preload("ModelType").First(&music, "musics.id = ?", id).Error
+	err := r.db.Preload("User").Preload("MusicType").Preload("ModelType").
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("tags.name asc") }).
+		First(&music, "musics.id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -80,8 +102,160 @@ func (r *musicRepo) UpdateLikesCount(musicID uuid.UUID, change int) error {
 	})
 }
 
+// UpdateMetadata verilen alanları günceller; tags nil değilse müziğin etiketlerini bu listeyle değiştirir.
+func (r *musicRepo) UpdateMetadata(musicID uuid.UUID, fields map[string]interface{}, tags *[]models.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(fields) > 0 {
+			if err := tx.Model(&models.Music{}).Where("id = ?", musicID).Updates(fields).Error; err != nil {
+				return err
+			}
+		}
+		if tags != nil {
+			if err := tx.Model(&models.Music{ID: musicID}).Association("Tags").Replace(*tags); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateVisibility parçanın görünürlüğünü değiştirir; ilk kez yayınlanıyorsa published_at da işaretlenir.
 func (r *musicRepo) UpdateVisibility(musicID uuid.UUID, isPublic bool) error {
-	return r.db.Model(&models.Music{}).Where("id = ?", musicID).Update("is_public", isPublic).Error
+	updates := map[string]interface{}{"is_public": isPublic}
+	if isPublic {
+		updates["published_at"] = gorm.Expr("COALESCE(published_at, ?)", time.Now())
+	}
+	return r.db.Model(&models.Music{}).Where("id = ?", musicID).Updates(updates).Error
+}
+
+// ListFileReferences tüm müzik kayıtlarının işaret ettiği dosya URL'lerini (mp3, midi, kapak) döner.
+func (r *musicRepo) ListFileReferences() ([]string, error) {
+	var rows []struct {
+		Mp3FilePath  string
+		MidiFilePath string
+		CoverArtPath string
+	}
+	// Çöp kutusundaki parçaların dosyaları da geri alınabilmeleri için referans sayılır.
+	if err := r.db.Unscoped().Model(&models.Music{}).Select("mp3_file_path, midi_file_path, cover_art_path").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	refs := make([]string, 0, len(rows)*3)
+	for _, row := range rows {
+		for _, p := range []string{row.Mp3FilePath, row.MidiFilePath, row.CoverArtPath} {
+			if p != "" {
+				refs = append(refs, p)
+			}
+		}
+	}
+	return refs, nil
+}
+
+// SoftDelete müziği çöp kutusuna taşır (deleted_at doldurulur).
+func (r *musicRepo) SoftDelete(musicID uuid.UUID) error {
+	return r.db.Delete(&models.Music{}, "id = ?", musicID).Error
+}
+
+// Restore çöp kutusundaki bir müziği geri alır.
+func (r *musicRepo) Restore(musicID uuid.UUID) error {
+	result := r.db.Unscoped().Model(&models.Music{}).
+		Where("id = ? AND deleted_at IS NOT NULL", musicID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *musicRepo) GetDeletedByID(id uuid.UUID) (*models.Music, error) {
+	var music models.Music
+	err := r.db.Unscoped().Preload("MusicType").Preload("ModelType").
+		Where("musics.deleted_at IS NOT NULL").
+		First(&music, "musics.id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &music, nil
+}
+
+// QueryUserDeletedMusic kullanıcının deletedAfter'dan sonra sildiği (hâlâ geri alınabilir) müzikleri listeler.
+func (r *musicRepo) QueryUserDeletedMusic(userID uuid.UUID, deletedAfter time.Time, page, perPage int) ([]models.Music, int64, error) {
+	var musicList []models.Music
+	var totalItems int64
+
+	query := r.db.Unscoped().Model(&models.Music{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL AND deleted_at > ?", userID, deletedAfter)
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, fmt.Errorf("counting deleted music failed: %w", err)
+	}
+	err := query.Order("deleted_at desc").
+		Offset((page - 1) * perPage).Limit(perPage).
+		Preload("MusicType").
+		Find(&musicList).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("finding deleted music failed: %w", err)
+	}
+	return musicList, totalItems, nil
+}
+
+// ListDeletedBefore saklama süresi dolmuş, kalıcı olarak silinecek müzikleri döner.
+func (r *musicRepo) ListDeletedBefore(before time.Time, limit int) ([]models.Music, error) {
+	var musicList []models.Music
+	err := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("deleted_at asc").Limit(limit).
+		Find(&musicList).Error
+	return musicList, err
+}
+
+// HardDelete müziği beğenileri, asset kayıtları, yorumları ve çalma listelerindeki yerleriyle birlikte kalıcı olarak siler.
+// Dosyaların silinmesi çağıranın sorumluluğundadır.
+func (r *musicRepo) HardDelete(musicID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("music_id = ?", musicID).Delete(&models.UserLikesMusic{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("music_id = ?", musicID).Delete(&models.MusicAsset{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("music_id = ?", musicID).Delete(&models.PlaylistEntry{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("music_id = ?", musicID).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM music_tags WHERE music_id = ?", musicID).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.Music{}, "id = ?", musicID).Error
+	})
+}
+
+// ClaimAnonymous anonim oturumda createdAfter'dan sonra üretilmiş sahipsiz müzikleri kullanıcıya aktarır.
+// Aktarılan satır sayısını döner.
+func (r *musicRepo) ClaimAnonymous(sessionID, userID uuid.UUID, createdAfter time.Time) (int64, error) {
+	result := r.db.Unscoped().Model(&models.Music{}).
+		Where("anonymous_session_id = ? AND user_id IS NULL AND created_at > ?", sessionID, createdAfter).
+		Updates(map[string]interface{}{
+			"user_id":              userID,
+			"anonymous_session_id": nil,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// applyTagFilter sorguyu verilen etiketlerin tümüne sahip müzikler ile sınırlar.
+func applyTagFilter(session *gorm.DB, tags []string) *gorm.DB {
+	if len(tags) == 0 {
+		return session
+	}
+	return session.Where(`musics.id IN (
+		SELECT mtg.music_id FROM music_tags AS mtg
+		JOIN tags AS t ON t.id = mtg.tag_id
+		WHERE t.name IN ?
+		GROUP BY mtg.music_id
+		HAVING COUNT(DISTINCT t.id) = ?)`, tags, len(tags))
 }
 
 // QueryUserMusic kullanıcıya ait müzikleri filtreler, sıralar ve sayfalar.
@@ -90,7 +264,7 @@ func (r *musicRepo) QueryUserMusic(userID uuid.UUID, params MusicQueryParams) ([
 	var totalItems int64
 
 	// Temel sorgu "musics" tablosu üzerinden başlar ve kullanıcıya göre filtrelenir.
-	baseQuery := r.db.Table("musics").Where("musics.user_id = ?", userID)
+	baseQuery := r.db.Table("musics").Where("musics.user_id = ? AND musics.deleted_at IS NULL", userID)
 
 	// JOIN'leri ekle (hem count hem de data sorgusu için gerekli olacaklar)
 	// Alias (takma ad) kullanarak tabloları ayırt et.
@@ -112,6 +286,7 @@ func (r *musicRepo) QueryUserMusic(userID uuid.UUID, params MusicQueryParams) ([
 	if params.MusicTypeFilter != "" {
 		filterSession = filterSession.Where("mt.name = ?", params.MusicTypeFilter)
 	}
+	filterSession = applyTagFilter(filterSession, params.Tags)
 
 	// Toplam öğe sayısını al
 	// SELECT count(musics.id) FROM musics ... (joinler ve where koşulları ile)
@@ -145,7 +320,7 @@ func (r *musicRepo) QueryUserMusic(userID uuid.UUID, params MusicQueryParams) ([
 	// Burada musics.* seçerek ve sonra Preload yaparak daha temiz olabilir.
 	err := dataSession.Select("musics.*").
 		Offset(offset).Limit(params.PerPage).
-		Preload("User").Preload("MusicType").Preload("ModelType").
+		Preload("User").Preload("MusicType").Preload("ModelType").Preload("Tags").
 		Find(&musicList).Error
 
 	if err != nil {
@@ -162,7 +337,7 @@ func (r *musicRepo) QueryPublicMusic(params MusicQueryParams) ([]models.Music, i
 	var musicList []models.Music
 	var totalItems int64
 
-	baseQuery := r.db.Table("musics").Where("musics.is_public = ?", true)
+	baseQuery := r.db.Table("musics").Where("musics.is_public = ? AND musics.deleted_at IS NULL", true)
 
 	queryWithJoins := baseQuery.
 		Joins("LEFT JOIN users AS u ON u.id = musics.user_id"). // UserID nullable olduğu için LEFT JOIN
@@ -181,6 +356,13 @@ func (r *musicRepo) QueryPublicMusic(params MusicQueryParams) ([]models.Music, i
 	if params.MusicTypeFilter != "" {
 		filterSession = filterSession.Where("mt.name = ?", params.MusicTypeFilter)
 	}
+	filterSession = applyTagFilter(filterSession, params.Tags)
+	if params.CreatorID != nil {
+		filterSession = filterSession.Where("musics.user_id = ?", *params.CreatorID)
+	}
+	if params.LikedByID != nil {
+		filterSession = filterSession.Where("musics.id IN (SELECT music_id FROM user_likes_musics WHERE user_id = ?)", *params.LikedByID)
+	}
 
 	if err := filterSession.Select("count(musics.id)").Count(&totalItems).Error; err != nil {
 		log.Printf("Error in countQuery for PublicMusic: %v", err)
@@ -202,7 +384,7 @@ func (r *musicRepo) QueryPublicMusic(params MusicQueryParams) ([]models.Music, i
 	offset := (params.Page - 1) * params.PerPage
 	err := dataSession.Select("musics.*").
 		Offset(offset).Limit(params.PerPage).
-		Preload("User").Preload("MusicType").Preload("ModelType").
+		Preload("User").Preload("MusicType").Preload("ModelType").Preload("Tags").
 		Find(&musicList).Error
 
 	if err != nil {
@@ -212,3 +394,23 @@ func (r *musicRepo) QueryPublicMusic(params MusicQueryParams) ([]models.Music, i
 
 	return musicList, totalItems, nil
 }
+
+// QueryFollowingFeed kullanıcının takip ettiği (askıda olmayan) hesapların yayındaki parçalarını en yeni
+// yayınlanan önce gelecek şekilde döner. after verilirse o parçadan sonrakiler döner (keyset sayfalama).
+func (r *musicRepo) QueryFollowingFeed(followerID uuid.UUID, after *FeedCursor, limit int) ([]models.Music, error) {
+	var musicList []models.Music
+	session := r.db.Model(&models.Music{}).
+		Where("musics.is_public = ? AND musics.published_at IS NOT NULL", true).
+		Where(`musics.user_id IN (SELECT f.followee_id FROM user_follows AS f
+			JOIN users AS u ON u.id = f.followee_id WHERE f.follower_id = ? AND u.suspended_at IS NULL)`, followerID)
+	if after != nil {
+		session = session.Where("(musics.published_at, musics.id) < (?, ?)", after.PublishedAt, after.ID)
+	}
+	err := session.Order("musics.published_at desc, musics.id desc").Limit(limit).
+		Preload("User").Preload("MusicType").Preload("ModelType").Preload("Tags").
+		Find(&musicList).Error
+	if err != nil {
+		return nil, fmt.Errorf("finding following feed failed: %w", err)
+	}
+	return musicList, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PasswordResetRepository interface {
+	Create(token *models.PasswordResetToken) error
+	FindValid(tokenHash string, now time.Time) (*models.PasswordResetToken, error)
+	Consume(tokenHash string, now time.Time) (*models.PasswordResetToken, error)
+	InvalidateForUser(userID uuid.UUID, now time.Time) (int64, error)
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type passwordResetRepo struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepo{db: db}
+}
+
+func (r *passwordResetRepo) Create(token *models.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+// FindValid kullanılmamış ve süresi dolmamış token'ı getirir; yoksa gorm.ErrRecordNotFound döner.
+func (r *passwordResetRepo) FindValid(tokenHash string, now time.Time) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Consume token'ı tek bir koşullu güncellemeyle kullanılmış olarak işaretler; aynı link iki kez kullanılamaz.
+// Token geçersizse, kullanılmışsa veya süresi dolmuşsa gorm.ErrRecordNotFound döner.
+func (r *passwordResetRepo) Consume(tokenHash string, now time.Time) (*models.PasswordResetToken, error) {
+	var tokens []models.PasswordResetToken
+	result := r.db.Model(&tokens).Clauses(clause.Returning{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || len(tokens) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &tokens[0], nil
+}
+
+// InvalidateForUser kullanıcının bekleyen tüm sıfırlama linklerini geçersiz kılar.
+func (r *passwordResetRepo) InvalidateForUser(userID uuid.UUID, now time.Time) (int64, error) {
+	result := r.db.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", now)
+	return result.RowsAffected, result.Error
+}
+
+// DeleteExpired süresi dolmuş ya da kullanılmış token'ları siler.
+func (r *passwordResetRepo) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ? OR used_at IS NOT NULL", now).Delete(&models.PasswordResetToken{})
+	return result.RowsAffected, result.Error
+}
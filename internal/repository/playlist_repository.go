@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrPlaylistEntryExists parça listede zaten varsa AddEntry tarafından döner.
+var ErrPlaylistEntryExists = errors.New("track is already in the playlist")
+
+type PlaylistRepository interface {
+	Create(playlist *models.Playlist) error
+	GetByID(id uuid.UUID) (*models.Playlist, error)
+	UpdateDetails(id uuid.UUID, fields map[string]interface{}) error
+	SetCoverKey(id uuid.UUID, key string) error
+	Delete(id uuid.UUID) error
+	ListByUser(ownerID, viewerID uuid.UUID, publicOnly bool) ([]PlaylistSummary, error)
+	ListForPicker(userID, musicID uuid.UUID) ([]PlaylistPickerItem, error)
+	ListEntries(playlistID, viewerID uuid.UUID) ([]models.PlaylistEntry, error)
+	AddEntry(playlistID, musicID uuid.UUID) error
+	RemoveEntry(playlistID, musicID uuid.UUID) (bool, error)
+	Reorder(playlistID uuid.UUID, entryIDs []uuid.UUID) error
+	ListCoverKeys() ([]string, error)
+}
+
+// PlaylistSummary liste kartları için çalma listesi ve izleyicinin görebildiği parça sayısıdır.
+// FirstCover listenin kendi kapağı yoksa gösterilecek ilk görünür parçanın kapağıdır.
+type PlaylistSummary struct {
+	models.Playlist
+	EntryCount int64
+	FirstCover string
+}
+
+// PlaylistPickerItem "listeye ekle" penceresindeki bir satırdır.
+type PlaylistPickerItem struct {
+	ID         uuid.UUID
+	Title      string
+	Visibility string
+	Contains   bool
+}
+
+type playlistRepo struct {
+	db *gorm.DB
+}
+
+func NewPlaylistRepository(db *gorm.DB) PlaylistRepository {
+	return &playlistRepo{db: db}
+}
+
+// visibleEntryFilter izleyicinin görebileceği liste parçalarını seçer: silinmemiş ve herkese açık parçalar ile
+// izleyicinin kendi parçaları. Herkese açık bir listedeki gizli parça böylece sadece sahibine görünür.
+const visibleEntryFilter = "m.deleted_at IS NULL AND (m.is_public = TRUE OR m.user_id = ?)"
+
+func (r *playlistRepo) Create(playlist *models.Playlist) error {
+	return r.db.Create(playlist).Error
+}
+
+func (r *playlistRepo) GetByID(id uuid.UUID) (*models.Playlist, error) {
+	var playlist models.Playlist
+	if err := r.db.Preload("User").First(&playlist, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+func (r *playlistRepo) UpdateDetails(id uuid.UUID, fields map[string]interface{}) error {
+	return r.db.Model(&models.Playlist{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// SetCoverKey kapak görselinin storage anahtarını yazar; boş anahtar kapağı kaldırır.
+func (r *playlistRepo) SetCoverKey(id uuid.UUID, key string) error {
+	return r.db.Model(&models.Playlist{}).Where("id = ?", id).Update("cover_key", key).Error
+}
+
+// Delete listeyi parçalarıyla birlikte siler. Kapak dosyasının silinmesi çağıranın sorumluluğundadır.
+func (r *playlistRepo) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("playlist_id = ?", id).Delete(&models.PlaylistEntry{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Playlist{}, "id = ?", id).Error
+	})
+}
+
+// ListByUser kullanıcının listelerini son güncellenen önce gelecek şekilde döner. publicOnly ile sadece
+// profilde gösterilen herkese açık listeler döner; sayaç ve yedek kapak viewerID'nin görebildiği parçalardan hesaplanır.
+func (r *playlistRepo) ListByUser(ownerID, viewerID uuid.UUID, publicOnly bool) ([]PlaylistSummary, error) {
+	var summaries []PlaylistSummary
+	session := r.db.Model(&models.Playlist{}).
+		Select(`playlists.*,
+			(SELECT COUNT(*) FROM playlist_entries AS pe JOIN musics AS m ON m.id = pe.music_id
+				WHERE pe.playlist_id = playlists.id AND `+visibleEntryFilter+`) AS entry_count,
+			(SELECT m.cover_art_path FROM playlist_entries AS pe JOIN musics AS m ON m.id = pe.music_id
+				WHERE pe.playlist_id = playlists.id AND `+visibleEntryFilter+` AND m.cover_art_path <> ''
+				ORDER BY pe.position LIMIT 1) AS first_cover`, viewerID, viewerID).
+		Where("playlists.user_id = ?", ownerID)
+	if publicOnly {
+		session = session.Where("playlists.visibility = ?", models.PlaylistPublic)
+	}
+	err := session.Order("playlists.updated_at desc").Scan(&summaries).Error
+	return summaries, err
+}
+
+// ListForPicker kullanıcının tüm listelerini, verilen parçanın her birinde olup olmadığıyla birlikte döner.
+func (r *playlistRepo) ListForPicker(userID, musicID uuid.UUID) ([]PlaylistPickerItem, error) {
+	var items []PlaylistPickerItem
+	err := r.db.Model(&models.Playlist{}).
+		Select(`playlists.id, playlists.title, playlists.visibility,
+			EXISTS (SELECT 1 FROM playlist_entries AS pe WHERE pe.playlist_id = playlists.id AND pe.music_id = ?) AS contains`, musicID).
+		Where("playlists.user_id = ?", userID).
+		Order("playlists.updated_at desc").
+		Scan(&items).Error
+	return items, err
+}
+
+// ListEntries listenin viewerID tarafından görülebilen parçalarını sırasıyla döner.
+func (r *playlistRepo) ListEntries(playlistID, viewerID uuid.UUID) ([]models.PlaylistEntry, error) {
+	var entries []models.PlaylistEntry
+	err := r.db.Joins("JOIN musics AS m ON m.id = playlist_entries.music_id").
+		Where("playlist_entries.playlist_id = ?", playlistID).
+		Where(visibleEntryFilter, viewerID).
+		Preload("Music.User").Preload("Music.MusicType").
+		Order("playlist_entries.position asc, playlist_entries.created_at asc").
+		Find(&entries).Error
+	return entries, err
+}
+
+// AddEntry parçayı listenin sonuna ekler. Parça listede zaten varsa ErrPlaylistEntryExists döner.
+func (r *playlistRepo) AddEntry(playlistID, musicID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Aynı listeye eşzamanlı eklemeler aynı sırayı almasın diye liste satırı kilitlenir
+		if err := tx.Exec("SELECT id FROM playlists WHERE id = ? FOR UPDATE", playlistID).Error; err != nil {
+			return err
+		}
+		var exists int64
+		if err := tx.Model(&models.PlaylistEntry{}).Where("playlist_id = ? AND music_id = ?", playlistID, musicID).Count(&exists).Error; err != nil {
+			return err
+		}
+		if exists > 0 {
+			return ErrPlaylistEntryExists
+		}
+		var next int
+		if err := tx.Model(&models.PlaylistEntry{}).Where("playlist_id = ?", playlistID).
+			Select("COALESCE(MAX(position) + 1, 0)").Row().Scan(&next); err != nil {
+			return err
+		}
+		entry := &models.PlaylistEntry{PlaylistID: playlistID, MusicID: musicID, Position: next}
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Playlist{}).Where("id = ?", playlistID).Update("updated_at", time.Now()).Error
+	})
+}
+
+// RemoveEntry parçayı listeden çıkarır; parça listede yoksa false döner.
+func (r *playlistRepo) RemoveEntry(playlistID, musicID uuid.UUID) (bool, error) {
+	result := r.db.Where("playlist_id = ? AND music_id = ?", playlistID, musicID).Delete(&models.PlaylistEntry{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		if err := r.db.Model(&models.Playlist{}).Where("id = ?", playlistID).Update("updated_at", time.Now()).Error; err != nil {
+			return true, err
+		}
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Reorder entryIDs sırasını uygular. Sahibin göremediği (ör. sonradan gizlenen) parçalar gönderilmez;
+// bunlar listedeki yerlerini korur, görünen parçalar ise aralarındaki boşlukları verilen sırayla doldurur.
+// Listeye ait olmayan ID'ler yok sayılır.
+func (r *playlistRepo) Reorder(playlistID uuid.UUID, entryIDs []uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current []uuid.UUID
+		if err := tx.Model(&models.PlaylistEntry{}).Where("playlist_id = ?", playlistID).
+			Order("position asc, created_at asc").Pluck("id", &current).Error; err != nil {
+			return err
+		}
+		belongs := make(map[uuid.UUID]bool, len(current))
+		for _, id := range current {
+			belongs[id] = true
+		}
+		moved := make([]uuid.UUID, 0, len(entryIDs))
+		movedSet := make(map[uuid.UUID]bool, len(entryIDs))
+		for _, id := range entryIDs {
+			if belongs[id] && !movedSet[id] {
+				moved = append(moved, id)
+				movedSet[id] = true
+			}
+		}
+
+		next := 0
+		for position, id := range current {
+			if movedSet[id] {
+				id = moved[next]
+				next++
+			}
+			if err := tx.Model(&models.PlaylistEntry{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Playlist{}).Where("id = ?", playlistID).Update("updated_at", time.Now()).Error
+	})
+}
+
+// ListCoverKeys artifact GC'nin silmemesi gereken liste kapaklarının anahtarlarını döner.
+func (r *playlistRepo) ListCoverKeys() ([]string, error) {
+	var keys []string
+	err := r.db.Model(&models.Playlist{}).Where("cover_key <> ''").Pluck("cover_key", &keys).Error
+	return keys, err
+}
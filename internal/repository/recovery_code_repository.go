@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository interface {
+	ReplaceForUser(userID uuid.UUID, codes []models.RecoveryCode) error
+	ListUnused(userID uuid.UUID) ([]models.RecoveryCode, error)
+	MarkUsed(id uuid.UUID, now time.Time) (bool, error)
+	CountUnused(userID uuid.UUID) (int64, error)
+	DeleteForUser(userID uuid.UUID) error
+}
+
+type recoveryCodeRepo struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeRepo{db: db}
+}
+
+// ReplaceForUser kullanıcının eski kodlarını silip yenilerini tek transaction'da yazar.
+func (r *recoveryCodeRepo) ReplaceForUser(userID uuid.UUID, codes []models.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *recoveryCodeRepo) ListUnused(userID uuid.UUID) ([]models.RecoveryCode, error) {
+	var codes []models.RecoveryCode
+	err := r.db.Where("user_id = ? AND used_at IS NULL", userID).Find(&codes).Error
+	return codes, err
+}
+
+// MarkUsed kodu kullanılmış olarak işaretler; eşzamanlı ikinci kullanımda false döner.
+func (r *recoveryCodeRepo) MarkUsed(id uuid.UUID, now time.Time) (bool, error) {
+	result := r.db.Model(&models.RecoveryCode{}).Where("id = ? AND used_at IS NULL", id).Update("used_at", now)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *recoveryCodeRepo) CountUnused(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *recoveryCodeRepo) DeleteForUser(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
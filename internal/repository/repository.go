@@ -8,6 +8,22 @@ type Repository struct {
 	MusicType MusicTypeRepository
 	ModelType ModelTypeRepository
 	UserLikes UserLikesRepository
+	Asset     MusicAssetRepository
+	Tag       TagRepository
+	Session   SessionRepository
+
+	Generation        GenerationRepository
+	Analytics         AnalyticsRepository
+	AuditLog          AuditLogRepository
+	PasswordReset     PasswordResetRepository
+	EmailVerification EmailVerificationRepository
+	RecoveryCode      RecoveryCodeRepository
+	Identity          IdentityRepository
+	AccessToken       AccessTokenRepository
+	DataExport        DataExportRepository
+	UserFollow        UserFollowRepository
+	Playlist          PlaylistRepository
+	Comment           CommentRepository
 }
 
 func NewRepository(db *gorm.DB) *Repository {
@@ -17,5 +33,21 @@ func NewRepository(db *gorm.DB) *Repository {
 		MusicType: NewMusicTypeRepository(db),
 		ModelType: NewModelTypeRepository(db),
 		UserLikes: NewUserLikesRepository(db),
+		Asset:     NewMusicAssetRepository(db),
+		Tag:       NewTagRepository(db),
+		Session:   NewSessionRepository(db),
+
+		Generation:        NewGenerationRepository(db),
+		Analytics:         NewAnalyticsRepository(db),
+		AuditLog:          NewAuditLogRepository(db),
+		PasswordReset:     NewPasswordResetRepository(db),
+		EmailVerification: NewEmailVerificationRepository(db),
+		RecoveryCode:      NewRecoveryCodeRepository(db),
+		Identity:          NewIdentityRepository(db),
+		AccessToken:       NewAccessTokenRepository(db),
+		DataExport:        NewDataExportRepository(db),
+		UserFollow:        NewUserFollowRepository(db),
+		Playlist:          NewPlaylistRepository(db),
+		Comment:           NewCommentRepository(db),
 	}
 }
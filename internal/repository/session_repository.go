@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(session *models.Session) error
+	GetByID(id uuid.UUID) (*models.Session, error)
+	Touch(id uuid.UUID, at time.Time, ip string) error
+	RotateRefreshHash(id uuid.UUID, oldHash, newHash string, expiresAt, now time.Time) (bool, error)
+	Revoke(id uuid.UUID) error
+	RevokeAllForUser(userID, exceptID uuid.UUID) (int64, error)
+	ListActiveForUser(userID uuid.UUID, now time.Time) ([]models.Session, error)
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type sessionRepo struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepo{db: db}
+}
+
+func (r *sessionRepo) Create(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepo) GetByID(id uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.First(&session, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Touch oturumun son görülme zamanını ve IP adresini günceller.
+func (r *sessionRepo) Touch(id uuid.UUID, at time.Time, ip string) error {
+	return r.db.Model(&models.Session{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"last_seen_at": at, "ip_address": ip}).Error
+}
+
+// RotateRefreshHash güncel refresh token özeti hâlâ oldHash ise onu newHash ile değiştirir ve oturumu uzatır.
+// Eşzamanlı bir döndürme önce davrandıysa false döner.
+func (r *sessionRepo) RotateRefreshHash(id uuid.UUID, oldHash, newHash string, expiresAt, now time.Time) (bool, error) {
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", id, oldHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash":    newHash,
+			"previous_refresh_hash": oldHash,
+			"refresh_rotated_at":    now,
+			"expires_at":            expiresAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Revoke oturumu iptal eder; zaten iptal edilmişse dokunmaz.
+func (r *sessionRepo) Revoke(id uuid.UUID) error {
+	return r.db.Model(&models.Session{}).Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUser kullanıcının exceptID dışındaki tüm aktif oturumlarını iptal eder.
+// exceptID uuid.Nil ise hepsi iptal edilir.
+func (r *sessionRepo) RevokeAllForUser(userID, exceptID uuid.UUID) (int64, error) {
+	result := r.db.Model(&models.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, exceptID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// ListActiveForUser kullanıcının aktif oturumlarını son etkinliğe göre sıralı döner.
+func (r *sessionRepo) ListActiveForUser(userID uuid.UUID, now time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at desc").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// DeleteExpired süresi dolmuş oturumları siler. Bu oturumların token'ları da süresi dolduğu için
+// artık reddedilir; iptal kaydını tutmaya gerek kalmaz.
+func (r *sessionRepo) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", now).Delete(&models.Session{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository interface {
+	FindOrCreateByNames(names []string) ([]models.Tag, error)
+	ListPopular(limit int) ([]TagUsage, error)
+}
+
+// TagUsage bir etiketin kaç herkese açık müzikte kullanıldığını taşır.
+type TagUsage struct {
+	Name  string
+	Count int64
+}
+
+type tagRepo struct {
+	*GenericRepository[models.Tag]
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepo{
+		GenericRepository: NewGenericRepository[models.Tag](db),
+		db:                db,
+	}
+}
+
+// FindOrCreateByNames normalize edilmiş isimler için etiketleri döner, olmayanları oluşturur.
+func (r *tagRepo) FindOrCreateByNames(names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return []models.Tag{}, nil
+	}
+	newTags := make([]models.Tag, len(names))
+	for i, name := range names {
+		newTags[i] = models.Tag{Name: name}
+	}
+	if err := r.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).Create(&newTags).Error; err != nil {
+		return nil, err
+	}
+	var tags []models.Tag
+	if err := r.db.Where("name IN ?", names).Order("name asc").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// ListPopular herkese açık müzikler arasında en çok kullanılan etiketleri döner.
+func (r *tagRepo) ListPopular(limit int) ([]TagUsage, error) {
+	var usage []TagUsage
+	err := r.db.Table("tags AS t").
+		Select("t.name AS name, COUNT(*) AS count").
+		Joins("JOIN music_tags AS mtg ON mtg.tag_id = t.id").
+		Joins("JOIN musics ON musics.id = mtg.music_id").
+		Where("musics.is_public = ? AND musics.deleted_at IS NULL", true).
+		Group("t.name").
+		Order("count desc, t.name asc").
+		Limit(limit).
+		Scan(&usage).Error
+	return usage, err
+}
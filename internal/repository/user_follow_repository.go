@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserFollowRepository interface {
+	IsFollowing(followerID, followeeID uuid.UUID) (bool, error)
+	Follow(follow *models.UserFollow) error
+	Unfollow(followerID, followeeID uuid.UUID) error
+}
+
+type userFollowRepo struct {
+	db *gorm.DB
+}
+
+func NewUserFollowRepository(db *gorm.DB) UserFollowRepository {
+	return &userFollowRepo{db: db}
+}
+
+func (r *userFollowRepo) IsFollowing(followerID, followeeID uuid.UUID) (bool, error) {
+	var follow models.UserFollow
+	err := r.db.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).First(&follow).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Follow takip kaydı ekler; aynı anda gelen iki istekte ikincisi sessizce yok sayılır.
+func (r *userFollowRepo) Follow(follow *models.UserFollow) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(follow).Error
+}
+
+func (r *userFollowRepo) Unfollow(followerID, followeeID uuid.UUID) error {
+	return r.db.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&models.UserFollow{}).Error
+}
@@ -1,6 +1,10 @@
 package repository
 
 import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/morgarakt/aurify/internal/models"
 	"gorm.io/gorm"
 )
@@ -10,6 +14,66 @@ type UserRepository interface {
 	Delete(user *models.User) error
 	GetByID(id any, user *models.User) error
 	GetByEmail(email string) (*models.User, error)
+	GetByUsername(username string) (*models.User, error)
+	GetProfileStats(userID uuid.UUID) (*ProfileStats, error)
+	UsernameExists(username string) (bool, error)
+	UpdatePasswordHash(userID uuid.UUID, passwordHash string) error
+	UpdateUsername(userID uuid.UUID, username string) error
+	ChangeEmail(userID uuid.UUID, email string) error
+	UpdateProfile(userID uuid.UUID, bio string, showLikes bool) error
+	SetAvatarKey(userID uuid.UUID, key string) error
+	ListAvatarKeys() ([]string, error)
+	MarkEmailVerified(userID uuid.UUID, email string, at time.Time) (bool, error)
+	SetTOTP(userID uuid.UUID, sealedSecret string, enabledAt *time.Time) error
+	AdvanceTOTPCounter(userID uuid.UUID, counter int64) (bool, error)
+	ReserveLoginAttempt(userID uuid.UUID, now time.Time, rules LoginThrottle) (*LoginFailureState, bool, error)
+	ResetLoginFailures(userID uuid.UUID) error
+	PromoteByEmails(emails []string, role string) (int64, error)
+	CountByRole() (map[string]int64, error)
+	Search(query string, page, perPage int) ([]models.User, int64, error)
+	GetStats(userID uuid.UUID, quotaSince time.Time) (*UserStats, error)
+	UpdateRole(userID uuid.UUID, role string) error
+	SetSuspended(userID uuid.UUID, suspendedAt *time.Time) error
+	ResetQuota(userID uuid.UUID, at time.Time) error
+	HardDelete(userID uuid.UUID) error
+	HardDeleteKeepingPublicMusic(userID uuid.UUID) error
+}
+
+// LoginFailureState hatalı bir giriş kaydedildikten sonraki sayaç ve kilit durumudur.
+type LoginFailureState struct {
+	FailedLoginCount int
+	LockedUntil      *time.Time
+}
+
+// LoginThrottle bir giriş denemesinin ne zaman reddedileceğini belirler: pencere içinde DelayAfter
+// hatadan sonra her deneme 1s, 2s, 4s... (en fazla MaxDelay) beklemelidir; Threshold'da hesap LockUntil'e kadar kilitlenir.
+type LoginThrottle struct {
+	WindowStart time.Time
+	Threshold   int
+	LockUntil   time.Time
+	DelayAfter  int
+	MaxDelay    time.Duration
+}
+
+// UserStats admin kullanıcı detay sayfasındaki sayaçlardır.
+type UserStats struct {
+	Tracks         int64
+	PublicTracks   int64
+	DeletedTracks  int64
+	LikesGiven     int64
+	LikesReceived  int64
+	Generations    int64
+	QuotaUsed      int64 // quotaSince'ten bu yana başarılı üretimler
+	ActiveSessions int64
+	LastSeenAt     *time.Time
+}
+
+// ProfileStats herkese açık profil sayfasındaki sayaçlardır; sadece yayındaki parçalar sayılır.
+type ProfileStats struct {
+	PublicTracks  int64
+	LikesReceived int64
+	Followers     int64
+	Following     int64
 }
 
 type userRepo struct {
@@ -24,10 +88,322 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	}
 }
 
+// GetByEmail kullanıcıyı büyük/küçük harf farkı gözetmeden e-posta adresiyle bulur; kimlik sağlayıcıları ve
+// kullanıcılar adresi farklı yazabilir. Yalnızca harf büyüklüğüyle ayrışan eski kayıtlarda en eski hesap döner.
 func (r *userRepo) GetByEmail(email string) (*models.User, error) {
 	var user models.User
-	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.db.Where("LOWER(email) = LOWER(?)", email).Order("created_at").First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
+
+// GetByUsername kullanıcıyı büyük/küçük harf farkı gözetmeden kullanıcı adıyla bulur. Eskiden yalnızca
+// harf büyüklüğüyle ayrışan adlar alınabildiği için birden fazla eşleşmede en eski hesap döner.
+func (r *userRepo) GetByUsername(username string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("LOWER(username) = LOWER(?)", username).Order("created_at").First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetProfileStats kullanıcının yayındaki parça sayısını, bu parçaların aldığı toplam beğeniyi ve
+// takipçi/takip edilen sayılarını döner.
+func (r *userRepo) GetProfileStats(userID uuid.UUID) (*ProfileStats, error) {
+	var stats ProfileStats
+	err := r.db.Model(&models.Music{}).
+		Where("user_id = ? AND is_public = ?", userID, true).
+		Select("COUNT(*), COALESCE(SUM(likes_count), 0)").
+		Row().Scan(&stats.PublicTracks, &stats.LikesReceived)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(&models.UserFollow{}).Where("followee_id = ?", userID).Count(&stats.Followers).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(&models.UserFollow{}).Where("follower_id = ?", userID).Count(&stats.Following).Error; err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// UsernameExists kullanıcı adının büyük/küçük harf farkı gözetmeden alınmış olup olmadığını bildirir.
+func (r *userRepo) UsernameExists(username string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("LOWER(username) = LOWER(?)", username).Count(&count).Error
+	return count > 0, err
+}
+
+// MarkEmailVerified e-posta adresi hâlâ email ise kullanıcıyı doğrulanmış olarak işaretler.
+// Adres bu arada değiştiyse false döner.
+func (r *userRepo) MarkEmailVerified(userID uuid.UUID, email string, at time.Time) (bool, error) {
+	result := r.db.Model(&models.User{}).Where("id = ? AND email = ?", userID, email).Update("email_verified_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+// SetTOTP TOTP secret'ını ve etkinleştirilme zamanını yazar; sayaç sıfırlanır.
+// Boş secret ve nil enabledAt iki adımlı doğrulamayı kapatır.
+func (r *userRepo) SetTOTP(userID uuid.UUID, sealedSecret string, enabledAt *time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"totp_secret":       sealedSecret,
+		"totp_enabled_at":   enabledAt,
+		"totp_last_counter": 0,
+	}).Error
+}
+
+// AdvanceTOTPCounter son kullanılan TOTP adımını ilerletir. Aynı ya da daha eski bir adım
+// (yani tekrar kullanılan bir kod) için false döner.
+func (r *userRepo) AdvanceTOTPCounter(userID uuid.UUID, counter int64) (bool, error) {
+	result := r.db.Model(&models.User{}).Where("id = ? AND totp_last_counter < ?", userID, counter).
+		Update("totp_last_counter", counter)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ReserveLoginAttempt, şifre kontrolünden önce denemeyi hatalı sayarak ayırır. Kilit ve bekleme kuralları aynı
+// UPDATE içinde değerlendirilir; böylece paralel denemeler kontrol ile sayım arasındaki boşluktan geçemez.
+// Son hata windowStart'tan eskiyse sayaç baştan başlar; sayaç threshold'a ulaşırsa hesap kilitlenir.
+// Deneme kurallara takıldıysa hiçbir şey değişmez ve false döner. Başarılı girişte ResetLoginFailures çağrılmalıdır.
+func (r *userRepo) ReserveLoginAttempt(userID uuid.UUID, now time.Time, rules LoginThrottle) (*LoginFailureState, bool, error) {
+	var states []LoginFailureState
+	err := r.db.Raw(`UPDATE users SET
+			failed_login_count = CASE WHEN last_failed_login_at IS NULL OR last_failed_login_at < @windowStart THEN 1 ELSE failed_login_count + 1 END,
+			last_failed_login_at = @now,
+			locked_until = CASE
+				WHEN (CASE WHEN last_failed_login_at IS NULL OR last_failed_login_at < @windowStart THEN 1 ELSE failed_login_count + 1 END) >= @threshold
+				THEN @lockUntil ELSE locked_until END
+		WHERE id = @id
+			AND (locked_until IS NULL OR locked_until <= @now)
+			AND NOT (last_failed_login_at IS NOT NULL AND last_failed_login_at >= @windowStart
+				AND failed_login_count >= @delayAfter
+				AND last_failed_login_at + LEAST(@maxDelay, power(2, failed_login_count - @delayAfter)) * interval '1 second' > @now)
+		RETURNING failed_login_count, locked_until`,
+		map[string]interface{}{
+			"id": userID, "now": now, "windowStart": rules.WindowStart, "threshold": rules.Threshold, "lockUntil": rules.LockUntil,
+			"delayAfter": rules.DelayAfter, "maxDelay": rules.MaxDelay.Seconds(),
+		},
+	).Scan(&states).Error
+	if err != nil || len(states) == 0 {
+		return nil, false, err
+	}
+	return &states[0], true, nil
+}
+
+// ResetLoginFailures başarılı girişte ya da admin kilidi kaldırdığında sayacı ve kilidi sıfırlar.
+func (r *userRepo) ResetLoginFailures(userID uuid.UUID) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"failed_login_count":   0,
+		"last_failed_login_at": nil,
+		"locked_until":         nil,
+	}).Error
+}
+
+func (r *userRepo) UpdatePasswordHash(userID uuid.UUID, passwordHash string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("password_hash", passwordHash).Error
+}
+
+func (r *userRepo) UpdateUsername(userID uuid.UUID, username string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("username", username).Error
+}
+
+// ChangeEmail adresi değiştirir ve doğrulamayı sıfırlar; yeni adres ayrıca doğrulanmalıdır.
+func (r *userRepo) ChangeEmail(userID uuid.UUID, email string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"email":             email,
+		"email_verified_at": nil,
+	}).Error
+}
+
+func (r *userRepo) UpdateProfile(userID uuid.UUID, bio string, showLikes bool) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"bio":        bio,
+		"show_likes": showLikes,
+	}).Error
+}
+
+// SetAvatarKey profil fotoğrafının storage anahtarını yazar; boş anahtar fotoğrafı kaldırır.
+func (r *userRepo) SetAvatarKey(userID uuid.UUID, key string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("avatar_key", key).Error
+}
+
+// ListAvatarKeys artifact GC'nin silmemesi gereken profil fotoğraflarının anahtarlarını döner.
+func (r *userRepo) ListAvatarKeys() ([]string, error) {
+	var keys []string
+	err := r.db.Model(&models.User{}).Where("avatar_key <> ''").Pluck("avatar_key", &keys).Error
+	return keys, err
+}
+
+// PromoteByEmails verilen e-posta adreslerine sahip, adresi doğrulanmış kullanıcıların rolünü role olarak ayarlar.
+// Adres ayarlardan değiştirilebildiği için doğrulanmamış bir adresle yetki alınamaz. Karşılaştırma
+// Config.IsAdminEmail gibi büyük/küçük harf duyarsızdır.
+func (r *userRepo) PromoteByEmails(emails []string, role string) (int64, error) {
+	if len(emails) == 0 {
+		return 0, nil
+	}
+	lowered := make([]string, 0, len(emails))
+	for _, email := range emails {
+		lowered = append(lowered, strings.ToLower(email))
+	}
+	result := r.db.Model(&models.User{}).Where("LOWER(email) IN ? AND role <> ? AND email_verified_at IS NOT NULL", lowered, role).Update("role", role)
+	return result.RowsAffected, result.Error
+}
+
+// CountByRole her roldeki kullanıcı sayısını döner.
+func (r *userRepo) CountByRole() (map[string]int64, error) {
+	var rows []struct {
+		Role  string
+		Count int64
+	}
+	if err := r.db.Model(&models.User{}).Select("role, count(*) as count").Group("role").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Role] = row.Count
+	}
+	return counts, nil
+}
+
+// Search kullanıcı adı veya e-postada geçen ifadeye göre kullanıcıları sayfalı olarak döner (en yeni önce).
+func (r *userRepo) Search(query string, page, perPage int) ([]models.User, int64, error) {
+	var users []models.User
+	var total int64
+	session := r.db.Model(&models.User{})
+	if query = strings.TrimSpace(query); query != "" {
+		pattern := "%" + strings.ToLower(query) + "%"
+		session = session.Where("LOWER(username) LIKE ? OR LOWER(email) LIKE ?", pattern, pattern)
+	}
+	if err := session.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := session.Order("created_at desc").Offset((page - 1) * perPage).Limit(perPage).Find(&users).Error
+	return users, total, err
+}
+
+func (r *userRepo) GetStats(userID uuid.UUID, quotaSince time.Time) (*UserStats, error) {
+	stats := &UserStats{}
+	counts := []struct {
+		dest  *int64
+		query *gorm.DB
+	}{
+		{&stats.Tracks, r.db.Model(&models.Music{}).Where("user_id = ?", userID)},
+		{&stats.PublicTracks, r.db.Model(&models.Music{}).Where("user_id = ? AND is_public = ?", userID, true)},
+		{&stats.DeletedTracks, r.db.Unscoped().Model(&models.Music{}).Where("user_id = ? AND deleted_at IS NOT NULL", userID)},
+		{&stats.LikesGiven, r.db.Model(&models.UserLikesMusic{}).Where("user_id = ?", userID)},
+		{&stats.Generations, r.db.Model(&models.Generation{}).Where("user_id = ?", userID)},
+		{&stats.QuotaUsed, r.db.Model(&models.Generation{}).Where("user_id = ? AND status = ? AND created_at >= ?", userID, models.GenerationSucceeded, quotaSince)},
+		{&stats.ActiveSessions, r.db.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now())},
+	}
+	for _, c := range counts {
+		if err := c.query.Count(c.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := r.db.Model(&models.Music{}).Where("user_id = ?", userID).
+		Select("COALESCE(SUM(likes_count), 0)").Row().Scan(&stats.LikesReceived); err != nil {
+		return nil, err
+	}
+	var lastSeen struct{ LastSeenAt *time.Time }
+	if err := r.db.Model(&models.Session{}).Where("user_id = ?", userID).
+		Select("MAX(last_seen_at) AS last_seen_at").Scan(&lastSeen).Error; err != nil {
+		return nil, err
+	}
+	stats.LastSeenAt = lastSeen.LastSeenAt
+	return stats, nil
+}
+
+func (r *userRepo) UpdateRole(userID uuid.UUID, role string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("role", role).Error
+}
+
+// SetSuspended hesabı askıya alır (suspendedAt dolu) veya askıyı kaldırır (nil).
+func (r *userRepo) SetSuspended(userID uuid.UUID, suspendedAt *time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("suspended_at", suspendedAt).Error
+}
+
+func (r *userRepo) ResetQuota(userID uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("quota_reset_at", at).Error
+}
+
+// HardDelete kullanıcıyı müzikleri (çöp kutusundakiler dahil), beğenileri ve oturumlarıyla birlikte kalıcı olarak siler.
+// Üretim kayıtları analiz için anonimleştirilerek tutulur. Sahipsiz kalan dosyaları artifact GC temizler.
+func (r *userRepo) HardDelete(userID uuid.UUID) error {
+	return r.hardDelete(userID, false)
+}
+
+// HardDeleteKeepingPublicMusic HardDelete ile aynıdır; ancak yayındaki (silinmemiş, herkese açık) parçalar
+// silinmek yerine sahipsiz bırakılır ve "Anonymous" olarak yayında kalır.
+func (r *userRepo) HardDeleteKeepingPublicMusic(userID uuid.UUID) error {
+	return r.hardDelete(userID, true)
+}
+
+func (r *userRepo) hardDelete(userID uuid.UUID, keepPublicMusic bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if keepPublicMusic {
+			if err := tx.Model(&models.Music{}).
+				Where("user_id = ? AND is_public = ?", userID, true).
+				Updates(map[string]interface{}{"user_id": nil, "anonymous_session_id": nil}).Error; err != nil {
+				return err
+			}
+		}
+		userMusic := tx.Unscoped().Model(&models.Music{}).Select("id").Where("user_id = ?", userID)
+
+		// Kullanıcının başkalarının parçalarına verdiği beğeniler sayaçlardan düşülür.
+		if err := tx.Exec(`UPDATE musics SET likes_count = likes_count - 1
+			WHERE likes_count > 0 AND id IN (SELECT music_id FROM user_likes_musics WHERE user_id = ?)`, userID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? OR music_id IN (?)", userID, userMusic).Delete(&models.UserLikesMusic{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("music_id IN (?)", userMusic).Delete(&models.MusicAsset{}).Error; err != nil {
+			return err
+		}
+		userPlaylists := tx.Model(&models.Playlist{}).Select("id").Where("user_id = ?", userID)
+		if err := tx.Where("playlist_id IN (?) OR music_id IN (?)", userPlaylists, userMusic).Delete(&models.PlaylistEntry{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.Playlist{}).Error; err != nil {
+			return err
+		}
+		// Kullanıcının yorumları, onlara gelen yanıtlar ve kullanıcının parçalarındaki tüm yorumlar silinir
+		userComments := tx.Model(&models.Comment{}).Select("id").Where("user_id = ?", userID)
+		if err := tx.Where("user_id = ? OR parent_id IN (?) OR music_id IN (?)", userID, userComments, userMusic).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM music_tags WHERE music_id IN (?)", userMusic).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.Music{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Generation{}).Where("user_id = ?", userID).Update("user_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.Session{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.EmailVerificationToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.Identity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.PersonalAccessToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.DataExport{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("follower_id = ? OR followee_id = ?", userID, userID).Delete(&models.UserFollow{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, "id = ?", userID).Error
+	})
+}
@@ -2,13 +2,15 @@ package router
 
 import (
 	// Partials için eklendi
+	"log"
 
 	"github.com/gin-gonic/gin"
 	"github.com/morgarakt/aurify/internal/config"                 // Projenizin config yolu
 	"github.com/morgarakt/aurify/internal/handlers"               // Projenizin handlers yolu
 	middleware "github.com/morgarakt/aurify/internal/middlewares" // Projenizin middlewares yolu
-	"github.com/morgarakt/aurify/internal/repository"             // Projenizin repository yolu
-	"github.com/morgarakt/aurify/internal/services"               // Projenizin services yolu
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository" // Projenizin repository yolu
+	"github.com/morgarakt/aurify/internal/services"   // Projenizin services yolu
 
 	swaggerFiles "github.com/swaggo/files"     // swagger embed files
 	ginSwagger "github.com/swaggo/gin-swagger" // gin-swagger middleware
@@ -19,14 +21,21 @@ type Router struct {
 	repository     *repository.Repository
 	config         *config.Config
 	rabbitmqClient *services.RabbitMQClient
+	services       *services.Services
 }
 
-func NewRouter(repo *repository.Repository, cfg *config.Config, rmqClient *services.RabbitMQClient) *Router {
+func NewRouter(repo *repository.Repository, cfg *config.Config, rmqClient *services.RabbitMQClient, svc *services.Services) *Router {
 	r := &Router{
 		engine:         gin.Default(),
 		repository:     repo,
 		config:         cfg,
 		rabbitmqClient: rmqClient,
+		services:       svc,
+	}
+	// Varsayılan olarak gin her proxy'ye güvenir; bu durumda ClientIP() istemcinin gönderdiği
+	// X-Forwarded-For ile taklit edilebilir. Liste boşsa (nil) yalnızca bağlantı adresi kullanılır.
+	if err := r.engine.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES %v: %v", cfg.TrustedProxies, err)
 	}
 	r.setupMiddlewares() // Önce middleware'ler ve template ayarları
 	r.setupRoutes()      // Sonra route'lar
@@ -44,19 +53,39 @@ func (r *Router) setupMiddlewares() {
 }
 
 func (r *Router) setupRoutes() {
-	authHandler := handlers.NewAuthHandler(r.repository, r.config)
-	musicHandler := handlers.NewMusicHandler(r.repository, r.config)
-	frontendHandler := handlers.NewFrontendHandler(r.repository, r.config, r.rabbitmqClient)
+	authHandler := handlers.NewAuthHandler(r.repository, r.config, r.services)
+	musicHandler := handlers.NewMusicHandler(r.repository, r.config, r.services)
+	frontendHandler := handlers.NewFrontendHandler(r.repository, r.config, r.rabbitmqClient, r.services)
+	settingsHandler := handlers.NewSettingsHandler(r.repository, r.config, r.services)
+	adminHandler := handlers.NewAdminHandler(r.repository, r.config, r.services)
+	playlistHandler := handlers.NewPlaylistHandler(r.repository, r.config, r.services)
 
 	r.engine.Static("/static", "./web/static")
-	r.engine.Static("/generated", "./generated")
+	r.engine.Static("/generated", r.config.GeneratedDir)
 	r.engine.NoRoute(frontendHandler.NotFoundPage)
 
 	r.engine.GET("/", frontendHandler.HomePage)
 	r.engine.GET("/login", frontendHandler.Login)
 	r.engine.GET("/register", frontendHandler.Register)
+	r.engine.GET("/forgot-password", frontendHandler.ForgotPassword)
+	r.engine.GET("/reset-password", frontendHandler.ResetPassword)
+	r.engine.GET("/verify-email", authHandler.VerifyEmail)
+	r.engine.GET("/login/2fa", frontendHandler.TwoFactorLoginPage)
+	r.engine.GET("/auth/oidc/login", authHandler.OIDCLogin)
+	r.engine.GET("/auth/oidc/callback", authHandler.OIDCCallback)
 	r.engine.GET("/library", middleware.AuthMiddleware(r.config.JWTSecret), frontendHandler.Library)
+	r.engine.GET("/library/deleted", middleware.AuthMiddleware(r.config.JWTSecret), frontendHandler.RecentlyDeleted)
 	r.engine.GET("/explore", frontendHandler.Explore)
+	r.engine.GET("/u/:username", frontendHandler.UserProfile)
+	r.engine.GET("/u/:username/likes", frontendHandler.UserProfileLikes)
+	r.engine.GET("/u/:username/playlists", frontendHandler.UserProfilePlaylists)
+	r.engine.GET("/playlists", middleware.AuthMiddleware(r.config.JWTSecret), playlistHandler.MyPlaylists)
+	r.engine.GET("/playlists/:id", playlistHandler.PlaylistPage)
+	r.engine.GET("/following", middleware.AuthMiddleware(r.config.JWTSecret), frontendHandler.Following)
+	r.engine.GET("/settings", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.Account)
+	r.engine.GET("/settings/sessions", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.Sessions)
+	r.engine.GET("/settings/tokens", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.AccessTokens)
+	r.engine.GET("/settings/privacy", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.Privacy)
 
 	// Müzik Detay Sayfası Route'u
 	r.engine.GET("/musics/:id", musicHandler.GetMusicPage) // musicHandler'a yönlendirildi
@@ -65,34 +94,117 @@ func (r *Router) setupRoutes() {
 	{
 		partials.GET("/edit-title-form", frontendHandler.GetEditTitleFormPartial)
 		partials.GET("/title-text", frontendHandler.GetTitleTextPartial)
+		partials.GET("/playlist-picker", middleware.AuthMiddleware(r.config.JWTSecret), playlistHandler.GetPlaylistPicker)
 		// Yeni partial'lar için (like butonu, visibility toggle) buraya eklenebilir veya handler'lar direkt HTML dönebilir
 		// partials.GET("/like-button/:id", musicHandler.GetLikeButtonPartial) // Örnek
 	}
 
-	admin := r.engine.Group("/admin")
+	// Admin alanı: önce oturum, sonra rol kontrolü
+	admin := r.engine.Group("/admin", middleware.AuthMiddleware(r.config.JWTSecret), middleware.RequireRole(models.RoleAdmin))
 	{
-		admin.GET("/", func(c *gin.Context) { /* ... */ })
+		admin.GET("", adminHandler.Dashboard)
+		admin.GET("/", adminHandler.Dashboard)
+		admin.GET("/api/analytics", adminHandler.Analytics)
+
+		admin.GET("/users", adminHandler.Users)
+		admin.GET("/users/:id", adminHandler.UserDetail)
+		admin.GET("/api/users", adminHandler.SearchUsers)
+		admin.GET("/api/users/:id", adminHandler.GetUser)
+		admin.POST("/api/users/:id/suspend", adminHandler.SuspendUser)
+		admin.POST("/api/users/:id/unsuspend", adminHandler.UnsuspendUser)
+		admin.POST("/api/users/:id/unlock", adminHandler.UnlockUser)
+		admin.PUT("/api/users/:id/role", adminHandler.UpdateUserRole)
+		admin.POST("/api/users/:id/reset-quota", adminHandler.ResetUserQuota)
+		admin.DELETE("/api/users/:id", adminHandler.DeleteUser)
+
+		admin.GET("/catalog", adminHandler.Catalog)
+		admin.GET("/api/catalog/:kind", adminHandler.ListCatalog)
+		admin.POST("/api/catalog/:kind", adminHandler.CreateCatalogEntry)
+		admin.PATCH("/api/catalog/:kind/:id", adminHandler.UpdateCatalogEntry)
+		admin.DELETE("/api/catalog/:kind/:id", adminHandler.DisableCatalogEntry)
+		admin.POST("/api/catalog/:kind/:id/enable", adminHandler.EnableCatalogEntry)
+
+		admin.GET("/audit", adminHandler.AuditLog)
+		admin.GET("/api/audit", adminHandler.ListAuditLog)
+		admin.GET("/api/audit/export", adminHandler.ExportAuditLog)
 	}
 
 	apiv1 := r.engine.Group("/api/v1")
 	{
 		apiv1.POST("/register", authHandler.RegisterHandler)
 		apiv1.POST("/login", authHandler.LoginHandler)
+		apiv1.POST("/login/2fa", authHandler.TwoFactorLoginHandler)
 		apiv1.POST("/logout", authHandler.LogoutHandler)
+		apiv1.POST("/token/refresh", authHandler.RefreshTokenHandler)
+		apiv1.POST("/password/forgot", authHandler.ForgotPasswordHandler)
+		apiv1.POST("/password/reset", authHandler.ResetPasswordHandler)
 
-		apiv1.POST("/generate-music", frontendHandler.GenerateMusicHandler)
-		apiv1.GET("/music", middleware.AuthMiddleware(r.config.JWTSecret), frontendHandler.GetMusicsLibrary)
+		apiv1.POST("/generate-music", middleware.AccessTokenAuth(models.ScopeGenerate), frontendHandler.GenerateMusicHandler)
+		apiv1.GET("/music", middleware.AccessTokenAuth(models.ScopeMusicRead), middleware.AuthMiddleware(r.config.JWTSecret), frontendHandler.GetMusicsLibrary)
 		apiv1.GET("/explore-music-data", frontendHandler.GetExploreMusicData)
+		apiv1.GET("/users/:username/music", frontendHandler.GetUserProfileMusic)
+		apiv1.GET("/users/:username/likes", frontendHandler.GetUserProfileLikes)
+		apiv1.POST("/users/:username/toggle-follow", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), frontendHandler.ToggleFollow)
+		apiv1.GET("/feed/following", middleware.AccessTokenAuth(models.ScopeMusicRead), middleware.AuthMiddleware(r.config.JWTSecret), frontendHandler.GetFollowingFeed)
+		apiv1.GET("/music/:id/waveform", musicHandler.GetMusicWaveform)
 
 		// Music Management API (frontend_handler.go'daki SaveGeneratedMusicHandler'a ek olarak veya yerine)
 		// Eğer SaveGeneratedMusicHandler genel bir "müziği güncelle" ise, onun PUT olması daha uygun olabilir.
 		// Şimdilik frontend_handler.go'daki POST /save-music kalıyor.
 		// apiv1.PUT("/music/:id/details", middleware.AuthMiddleware(r.config.JWTSecret), musicHandler.UpdateMusicDetailsHandler)
 
-		apiv1.PUT("/music/:id/title", middleware.AuthMiddleware(r.config.JWTSecret), musicHandler.UpdateMusicTitleHandler)
-
-		apiv1.POST("/music/:id/toggle-like", middleware.AuthMiddleware(r.config.JWTSecret), musicHandler.ToggleLikeMusic)
-		apiv1.PUT("/music/:id/visibility", middleware.AuthMiddleware(r.config.JWTSecret), musicHandler.ToggleMusicVisibility)
+		apiv1.PUT("/music/:id/title", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), musicHandler.UpdateMusicTitleHandler)
+
+		apiv1.POST("/music/:id/toggle-like", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), musicHandler.ToggleLikeMusic)
+		apiv1.PUT("/music/:id/visibility", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), musicHandler.ToggleMusicVisibility)
+
+		apiv1.PATCH("/music/:id", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), musicHandler.UpdateMusicMetadata)
+		apiv1.DELETE("/music/:id", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), musicHandler.DeleteMusic)
+		apiv1.POST("/music/:id/restore", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), musicHandler.RestoreMusic)
+		apiv1.DELETE("/music/:id/permanent", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), musicHandler.PurgeDeletedMusic)
+
+		// Yorumlar: okuma herkese açık (gizli parçalarda sadece sahibine), yazma/düzenleme/silme oturum gerektirir
+		apiv1.GET("/music/:id/comments", musicHandler.GetComments)
+		apiv1.POST("/music/:id/comments", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), musicHandler.PostComment)
+		apiv1.PATCH("/comments/:id", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), musicHandler.UpdateComment)
+		apiv1.DELETE("/comments/:id", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), musicHandler.DeleteComment)
+
+		// Çalma listeleri
+		apiv1.POST("/playlists", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), playlistHandler.CreatePlaylist)
+		apiv1.PATCH("/playlists/:id", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), playlistHandler.UpdatePlaylist)
+		apiv1.DELETE("/playlists/:id", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), playlistHandler.DeletePlaylist)
+		apiv1.POST("/playlists/:id/cover", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), playlistHandler.UploadPlaylistCover)
+		apiv1.DELETE("/playlists/:id/cover", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), playlistHandler.DeletePlaylistCover)
+		apiv1.POST("/playlists/:id/tracks", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), playlistHandler.AddPlaylistTrack)
+		apiv1.DELETE("/playlists/:id/tracks/:musicID", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), playlistHandler.RemovePlaylistTrack)
+		apiv1.PUT("/playlists/:id/order", middleware.AccessTokenAuth(models.ScopeMusicWrite), middleware.AuthMiddleware(r.config.JWTSecret), playlistHandler.ReorderPlaylist)
+
+		// Oturum ve hesap güvenliği
+		apiv1.DELETE("/sessions/:id", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.RevokeSession)
+		apiv1.POST("/sessions/revoke-others", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.RevokeOtherSessions)
+		apiv1.POST("/sessions/revoke-all", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.RevokeAllSessions)
+		apiv1.POST("/account/password", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.ChangePassword)
+		apiv1.POST("/account/username", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.ChangeUsername)
+		apiv1.POST("/account/email", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.ChangeEmail)
+		apiv1.POST("/account/profile", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.UpdateProfile)
+		apiv1.POST("/account/avatar", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.UploadAvatar)
+		apiv1.DELETE("/account/avatar", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.DeleteAvatar)
+		apiv1.POST("/account/verification/resend", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.ResendVerification)
+		apiv1.POST("/account/2fa/setup", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.SetupTwoFactor)
+		apiv1.POST("/account/2fa/confirm", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.ConfirmTwoFactor)
+		apiv1.POST("/account/2fa/recovery-codes", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.RegenerateRecoveryCodes)
+		apiv1.POST("/account/2fa/disable", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.DisableTwoFactor)
+
+		// Kişisel erişim token'ları sadece tarayıcı oturumuyla yönetilir; token ile token oluşturulamaz
+		apiv1.GET("/account/tokens", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.ListAccessTokens)
+		apiv1.POST("/account/tokens", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.CreateAccessToken)
+		apiv1.DELETE("/account/tokens/:id", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.RevokeAccessToken)
+
+		// Veri dışa aktarma ve hesap silme (GDPR); bunlar da sadece tarayıcı oturumuyla yapılır
+		apiv1.GET("/account/exports", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.ListDataExports)
+		apiv1.POST("/account/exports", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.RequestDataExport)
+		apiv1.GET("/account/exports/:id/download", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.DownloadDataExport)
+		apiv1.POST("/account/delete", middleware.AuthMiddleware(r.config.JWTSecret), settingsHandler.DeleteAccount)
 	}
 
 	r.engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
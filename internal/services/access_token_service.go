@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxAccessTokensPerUser limits how many active tokens a user can hold at once.
+	maxAccessTokensPerUser = 25
+	// accessTokenTouchInterval limits how often last_used_at is written for a busy token.
+	accessTokenTouchInterval = time.Minute
+)
+
+var (
+	ErrAccessTokenLimit   = errors.New("too many active access tokens")
+	ErrAccessTokenScopes  = errors.New("access token needs at least one valid scope")
+	ErrAccessTokenName    = errors.New("access token name is required")
+	ErrAccessTokenMissing = errors.New("access token not found")
+)
+
+// AccessTokenExpiryOptions are the lifetimes offered when creating a token; 0 means no expiry.
+var AccessTokenExpiryOptions = []time.Duration{7 * 24 * time.Hour, 30 * 24 * time.Hour, 90 * 24 * time.Hour, 365 * 24 * time.Hour, 0}
+
+// AccessTokenService manages personal access tokens and authenticates Bearer requests made with them.
+type AccessTokenService struct {
+	repo *repository.Repository
+}
+
+func NewAccessTokenService(repo *repository.Repository) *AccessTokenService {
+	return &AccessTokenService{repo: repo}
+}
+
+// Create issues a new token for the user and returns its secret value, which is never shown again.
+func (s *AccessTokenService) Create(userID uuid.UUID, name string, scopes []string, ttl time.Duration) (string, *models.PersonalAccessToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, ErrAccessTokenName
+	}
+	scopeList, err := normalizeScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	active, err := s.repo.AccessToken.CountActiveForUser(userID, now)
+	if err != nil {
+		return "", nil, err
+	}
+	if active >= maxAccessTokensPerUser {
+		return "", nil, ErrAccessTokenLimit
+	}
+
+	secret, hash, prefix, err := utils.NewAccessToken()
+	if err != nil {
+		return "", nil, err
+	}
+	token := &models.PersonalAccessToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      Truncate(name, 64),
+		TokenHash: hash,
+		Prefix:    prefix,
+		Scopes:    strings.Join(scopeList, " "),
+		CreatedAt: now,
+	}
+	if ttl > 0 {
+		expiresAt := now.Add(ttl)
+		token.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.AccessToken.Create(token); err != nil {
+		return "", nil, err
+	}
+	return secret, token, nil
+}
+
+// List returns the user's tokens that have not been revoked, including expired ones.
+func (s *AccessTokenService) List(userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	return s.repo.AccessToken.ListForUser(userID)
+}
+
+// Revoke disables one of the user's tokens immediately.
+func (s *AccessTokenService) Revoke(userID, tokenID uuid.UUID) error {
+	revoked, err := s.repo.AccessToken.Revoke(userID, tokenID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAccessTokenMissing
+	}
+	return nil
+}
+
+// AuthenticateAccessToken implements utils.AccessTokenStore. Tokens of suspended users are rejected;
+// the username and role are read from the user record so changes apply immediately.
+func (s *AccessTokenService) AuthenticateAccessToken(secret, ip string) (*utils.AccessTokenPrincipal, error) {
+	token, err := s.repo.AccessToken.GetByHash(utils.HashOpaqueToken(secret))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrAccessTokenInvalid
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if !token.IsActive(now) || token.User.IsSuspended() {
+		return nil, utils.ErrAccessTokenInvalid
+	}
+	if err := s.repo.AccessToken.Touch(token.ID, now, Truncate(ip, 64), accessTokenTouchInterval); err != nil {
+		// Son kullanım bilgisi yazılamasa da istek reddedilmez
+		log.Printf("Failed to update last use for access token %s: %v", token.ID, err)
+	}
+	return &utils.AccessTokenPrincipal{
+		TokenID:  token.ID,
+		UserID:   token.UserID,
+		Username: token.User.Username,
+		Role:     token.User.Role,
+		Scopes:   token.ScopeList(),
+	}, nil
+}
+
+// normalizeScopes validates the requested scopes and removes duplicates, keeping the canonical order.
+func normalizeScopes(scopes []string) ([]string, error) {
+	requested := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !models.IsValidScope(scope) {
+			return nil, ErrAccessTokenScopes
+		}
+		requested[scope] = true
+	}
+	var result []string
+	for _, scope := range models.AccessTokenScopes {
+		if requested[scope] {
+			result = append(result, scope)
+		}
+	}
+	if len(result) == 0 {
+		return nil, ErrAccessTokenScopes
+	}
+	return result, nil
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	usernameMinLength = 3
+	usernameMaxLength = 32
+	bioMaxLength      = 500
+)
+
+var (
+	ErrUsernameInvalid  = errors.New("username must be 3-32 letters, digits, '.', '-' or '_' and start with a letter or digit")
+	ErrUsernameReserved = errors.New("username is reserved")
+	ErrUsernameTaken    = errors.New("username is already taken")
+	ErrEmailTaken       = errors.New("email address is already in use")
+	ErrEmailUnchanged   = errors.New("email address is unchanged")
+	ErrBioTooLong       = errors.New("bio is too long")
+	ErrAvatarTooLarge   = errors.New("avatar file is too large")
+	ErrDeletePolicy     = errors.New("unknown account deletion policy")
+)
+
+// Hesap silinirken yayındaki parçalara ne olacağı
+const (
+	DeletePolicyRemove    = "delete"    // Tüm parçalar silinir
+	DeletePolicyAnonymize = "anonymize" // Yayındaki parçalar "Anonymous" olarak kalır, diğerleri silinir
+)
+
+var usernamePattern = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N}._-]*$`)
+
+// Sayfa yolları, sistem hesapları ve kullanıcıları yanıltabilecek adlar alınamaz.
+var reservedUsernames = map[string]struct{}{
+	"admin": {}, "administrator": {}, "root": {}, "system": {}, "support": {}, "help": {},
+	"aurify": {}, "staff": {}, "moderator": {}, "mod": {}, "security": {}, "official": {},
+	"api": {}, "auth": {}, "login": {}, "logout": {}, "register": {}, "settings": {},
+	"library": {}, "explore": {}, "generated": {}, "static": {}, "music": {}, "me": {},
+	"anonymous": {}, "null": {}, "undefined": {},
+}
+
+// ValidateUsername checks the format and reserved names; uniqueness is checked separately.
+func ValidateUsername(username string) error {
+	length := utf8.RuneCountInString(username)
+	if length < usernameMinLength || length > usernameMaxLength || !usernamePattern.MatchString(username) {
+		return ErrUsernameInvalid
+	}
+	if _, reserved := reservedUsernames[strings.ToLower(username)]; reserved {
+		return ErrUsernameReserved
+	}
+	return nil
+}
+
+// AccountService lets users change their own username, email address, bio and avatar.
+type AccountService struct {
+	repo           *repository.Repository
+	storage        *LocalStorage
+	mailer         Mailer
+	verification   *EmailVerificationService
+	avatarMaxBytes int
+}
+
+func NewAccountService(repo *repository.Repository, storage *LocalStorage, mailer Mailer, verification *EmailVerificationService, avatarMaxBytes int) *AccountService {
+	return &AccountService{
+		repo:           repo,
+		storage:        storage,
+		mailer:         mailer,
+		verification:   verification,
+		avatarMaxBytes: avatarMaxBytes,
+	}
+}
+
+// AvatarMaxBytes is the upload limit for avatar images.
+func (s *AccountService) AvatarMaxBytes() int {
+	return s.avatarMaxBytes
+}
+
+// AvatarURL returns the public URL of the user's avatar, or "" if none is set.
+func (s *AccountService) AvatarURL(user *models.User) string {
+	if user.AvatarKey == "" {
+		return ""
+	}
+	return s.storage.URLFor(user.AvatarKey)
+}
+
+// CheckUsernameAvailable validates username and makes sure no other account uses it.
+// Names differing only in letter case count as the same name.
+func (s *AccountService) CheckUsernameAvailable(username string) error {
+	if err := ValidateUsername(username); err != nil {
+		return err
+	}
+	exists, err := s.repo.User.UsernameExists(username)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrUsernameTaken
+	}
+	return nil
+}
+
+// ChangeUsername renames the user. Changing only the letter case of the current name is allowed.
+func (s *AccountService) ChangeUsername(user *models.User, username string) error {
+	if strings.EqualFold(username, user.Username) {
+		if err := ValidateUsername(username); err != nil {
+			return err
+		}
+	} else if err := s.CheckUsernameAvailable(username); err != nil {
+		return err
+	}
+	if err := s.repo.User.UpdateUsername(user.ID, username); err != nil {
+		return err
+	}
+	user.Username = username
+	return nil
+}
+
+// ChangeEmail moves the account to a new address. The new address has to be verified again and
+// the old address is told about the change, so a hijacked session cannot quietly take over the account.
+func (s *AccountService) ChangeEmail(user *models.User, email string) error {
+	if strings.EqualFold(email, user.Email) {
+		return ErrEmailUnchanged
+	}
+	_, err := s.repo.User.GetByEmail(email)
+	if err == nil {
+		return ErrEmailTaken
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err := s.repo.User.ChangeEmail(user.ID, email); err != nil {
+		return err
+	}
+
+	oldEmail := user.Email
+	user.Email = email
+	user.EmailVerifiedAt = nil
+	s.notifyEmailChanged(user, oldEmail)
+
+	// Doğrulama maili gönderilemese de değişiklik geçerlidir; kullanıcı ayarlardan yeniden isteyebilir
+	if err := s.verification.SendVerification(user); err != nil {
+		log.Printf("Account: sending verification to new address of user %s failed: %v", user.ID, err)
+	}
+	return nil
+}
+
+// UpdateProfile saves the profile bio and whether liked tracks are shown on the public profile.
+func (s *AccountService) UpdateProfile(user *models.User, bio string, showLikes bool) error {
+	bio = strings.TrimSpace(bio)
+	if utf8.RuneCountInString(bio) > bioMaxLength {
+		return ErrBioTooLong
+	}
+	if err := s.repo.User.UpdateProfile(user.ID, bio, showLikes); err != nil {
+		return err
+	}
+	user.Bio = bio
+	user.ShowLikes = showLikes
+	return nil
+}
+
+// SetAvatar stores a resized copy of the uploaded image and replaces the previous avatar.
+// Every upload gets a new key so browsers and proxies never show a cached old picture.
+func (s *AccountService) SetAvatar(user *models.User, data []byte) error {
+	if len(data) > s.avatarMaxBytes {
+		return ErrAvatarTooLarge
+	}
+	avatar, err := processAvatar(data)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("avatars/%s/%s.png", user.ID, randomHex(8))
+	if err := s.storage.Write(key, avatar); err != nil {
+		return err
+	}
+	if err := s.repo.User.SetAvatarKey(user.ID, key); err != nil {
+		_ = s.storage.Remove(key)
+		return err
+	}
+	s.removeAvatarFile(user.AvatarKey)
+	user.AvatarKey = key
+	return nil
+}
+
+// RemoveAvatar switches the user back to the default avatar.
+func (s *AccountService) RemoveAvatar(user *models.User) error {
+	if user.AvatarKey == "" {
+		return nil
+	}
+	if err := s.repo.User.SetAvatarKey(user.ID, ""); err != nil {
+		return err
+	}
+	s.removeAvatarFile(user.AvatarKey)
+	user.AvatarKey = ""
+	return nil
+}
+
+// DeleteAccount permanently deletes the user. With DeletePolicyAnonymize public tracks are kept
+// without an owner; everything else is removed. Sessions and export archives are cleaned up by the caller.
+func (s *AccountService) DeleteAccount(user *models.User, policy string) error {
+	var err error
+	switch policy {
+	case DeletePolicyRemove:
+		err = s.repo.User.HardDelete(user.ID)
+	case DeletePolicyAnonymize:
+		err = s.repo.User.HardDeleteKeepingPublicMusic(user.ID)
+	default:
+		return ErrDeletePolicy
+	}
+	if err != nil {
+		return err
+	}
+	// Parça dosyalarını artifact GC temizler; avatar hemen kaldırılır
+	s.removeAvatarFile(user.AvatarKey)
+	return nil
+}
+
+// removeAvatarFile deletes a replaced avatar. Failures are only logged; artifact GC removes the file later.
+func (s *AccountService) removeAvatarFile(key string) {
+	if key == "" {
+		return
+	}
+	if err := s.storage.Remove(key); err != nil {
+		log.Printf("Account: removing old avatar %s failed: %v", key, err)
+	}
+}
+
+func (s *AccountService) notifyEmailChanged(user *models.User, oldEmail string) {
+	msg := MailMessage{
+		To:      oldEmail,
+		Subject: "Your Aurify email address was changed",
+		Body: fmt.Sprintf(`Hi %s,
+
+The email address of your Aurify account was changed from %s to %s.
+
+If you made this change, you can ignore this email. If you didn't, someone else has access to
+your account: please contact support as soon as possible.
+`, user.Username, oldEmail, user.Email),
+	}
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			log.Printf("Account: sending email change notice to user %s failed: %v", user.ID, err)
+		}
+	}()
+}
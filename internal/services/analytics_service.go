@@ -0,0 +1,110 @@
+package services
+
+import (
+	"time"
+
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+)
+
+// AnalyticsReport is the platform summary shown on the admin dashboard and returned by its JSON endpoint.
+type AnalyticsReport struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"` // exclusive
+
+	Generations       GenerationStats                   `json:"generations"`
+	GenerationsPerDay []repository.DailyGenerationCount `json:"generations_per_day"`
+	DailyTotals       []repository.DailyCount           `json:"daily_totals"`
+
+	Signups         int64                   `json:"signups"`
+	SignupsPerDay   []repository.DailyCount `json:"signups_per_day"`
+	ActiveUsers     int64                   `json:"active_users"`
+	Likes           int64                   `json:"likes"`
+	PublicTracks    int64                   `json:"public_tracks"`
+	NewPublicTracks int64                   `json:"new_public_tracks"`
+}
+
+type GenerationStats struct {
+	Total           int64   `json:"total"`
+	Succeeded       int64   `json:"succeeded"`
+	Failed          int64   `json:"failed"`
+	TimedOut        int64   `json:"timed_out"`
+	SuccessRate     float64 `json:"success_rate"`
+	FailureRate     float64 `json:"failure_rate"`
+	TimeoutRate     float64 `json:"timeout_rate"`
+	MedianLatencyMs float64 `json:"median_latency_ms"`
+}
+
+type AnalyticsService struct {
+	repo *repository.Repository
+}
+
+func NewAnalyticsService(repo *repository.Repository) *AnalyticsService {
+	return &AnalyticsService{repo: repo}
+}
+
+// Report aggregates platform activity in [from, to).
+func (s *AnalyticsService) Report(from, to time.Time) (*AnalyticsReport, error) {
+	report := &AnalyticsReport{From: from, To: to}
+	var err error
+
+	if report.GenerationsPerDay, err = s.repo.Analytics.GenerationsPerDay(from, to); err != nil {
+		return nil, err
+	}
+	report.DailyTotals = dailyTotals(report.GenerationsPerDay)
+
+	outcomes, err := s.repo.Analytics.GenerationOutcomes(from, to)
+	if err != nil {
+		return nil, err
+	}
+	stats := &report.Generations
+	for _, o := range outcomes {
+		stats.Total += o.Count
+		switch o.Status {
+		case models.GenerationSucceeded:
+			stats.Succeeded = o.Count
+		case models.GenerationFailed:
+			stats.Failed = o.Count
+		case models.GenerationTimedOut:
+			stats.TimedOut = o.Count
+		}
+	}
+	if stats.Total > 0 {
+		stats.SuccessRate = float64(stats.Succeeded) / float64(stats.Total)
+		stats.FailureRate = float64(stats.Failed) / float64(stats.Total)
+		stats.TimeoutRate = float64(stats.TimedOut) / float64(stats.Total)
+	}
+	if stats.MedianLatencyMs, err = s.repo.Analytics.MedianGenerationLatency(from, to); err != nil {
+		return nil, err
+	}
+
+	if report.SignupsPerDay, err = s.repo.Analytics.SignupsPerDay(from, to); err != nil {
+		return nil, err
+	}
+	for _, d := range report.SignupsPerDay {
+		report.Signups += d.Count
+	}
+	if report.ActiveUsers, err = s.repo.Analytics.ActiveUsers(from, to); err != nil {
+		return nil, err
+	}
+	if report.Likes, err = s.repo.Analytics.LikesCount(from, to); err != nil {
+		return nil, err
+	}
+	if report.PublicTracks, report.NewPublicTracks, err = s.repo.Analytics.PublicTrackCounts(from, to); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// dailyTotals collapses the per-model/per-type rows (already ordered by day) into one count per day.
+func dailyTotals(rows []repository.DailyGenerationCount) []repository.DailyCount {
+	totals := make([]repository.DailyCount, 0)
+	for _, row := range rows {
+		if n := len(totals); n > 0 && totals[n-1].Day.Equal(row.Day) {
+			totals[n-1].Count += row.Count
+			continue
+		}
+		totals = append(totals, repository.DailyCount{Day: row.Day, Count: row.Count})
+	}
+	return totals
+}
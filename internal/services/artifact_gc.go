@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/morgarakt/aurify/internal/repository"
+)
+
+// OrphanedArtifact is a stored file that no musics/music_assets/users row references.
+type OrphanedArtifact struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// SweepReport summarizes a single garbage collection pass.
+type SweepReport struct {
+	DryRun     bool
+	Scanned    int
+	Referenced int
+	TooRecent  int
+	Orphaned   []OrphanedArtifact
+	Removed    int
+	FreedBytes int64
+	Failed     int
+}
+
+// ArtifactSweeper deletes generated files that are no longer referenced by the database,
+// e.g. outputs of generations whose auto-save failed or files of deleted tracks.
+type ArtifactSweeper struct {
+	repo        *repository.Repository
+	storage     *LocalStorage
+	gracePeriod time.Duration
+}
+
+func NewArtifactSweeper(repo *repository.Repository, storage *LocalStorage, gracePeriod time.Duration) *ArtifactSweeper {
+	return &ArtifactSweeper{repo: repo, storage: storage, gracePeriod: gracePeriod}
+}
+
+// referencedKeys collects the storage keys of every file the database still points to.
+func (s *ArtifactSweeper) referencedKeys() (map[string]struct{}, error) {
+	referenced := make(map[string]struct{})
+
+	urls, err := s.repo.Music.ListFileReferences()
+	if err != nil {
+		return nil, fmt.Errorf("listing music file references: %w", err)
+	}
+	for _, u := range urls {
+		if key, ok := s.storage.KeyFromURL(u); ok {
+			referenced[key] = struct{}{}
+		}
+	}
+
+	keys, err := s.repo.Asset.ListStorageKeys()
+	if err != nil {
+		return nil, fmt.Errorf("listing asset storage keys: %w", err)
+	}
+	for _, key := range keys {
+		referenced[key] = struct{}{}
+	}
+
+	avatars, err := s.repo.User.ListAvatarKeys()
+	if err != nil {
+		return nil, fmt.Errorf("listing avatar keys: %w", err)
+	}
+	for _, key := range avatars {
+		referenced[key] = struct{}{}
+	}
+
+	covers, err := s.repo.Playlist.ListCoverKeys()
+	if err != nil {
+		return nil, fmt.Errorf("listing playlist cover keys: %w", err)
+	}
+	for _, key := range covers {
+		referenced[key] = struct{}{}
+	}
+	return referenced, nil
+}
+
+// Sweep reconciles the storage against the database. Unreferenced files older than the
+// grace period are deleted, or only reported when dryRun is true.
+func (s *ArtifactSweeper) Sweep(ctx context.Context, dryRun bool) (*SweepReport, error) {
+	// References are loaded before walking so files created during the walk are
+	// protected by the grace period rather than by a racy lookup.
+	referenced, err := s.referencedKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SweepReport{DryRun: dryRun}
+	cutoff := time.Now().Add(-s.gracePeriod)
+	err = s.storage.Walk(func(key string, info fs.FileInfo) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		report.Scanned++
+		if _, ok := referenced[key]; ok {
+			report.Referenced++
+			return nil
+		}
+		if info.ModTime().After(cutoff) {
+			report.TooRecent++
+			return nil
+		}
+		report.Orphaned = append(report.Orphaned, OrphanedArtifact{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("walking storage: %w", err)
+	}
+
+	for _, artifact := range report.Orphaned {
+		if dryRun {
+			log.Printf("Artifact GC (dry-run): would remove %s (%d bytes, modified %s)", artifact.Key, artifact.Size, artifact.ModTime.Format(time.RFC3339))
+			continue
+		}
+		if err := s.storage.Remove(artifact.Key); err != nil {
+			report.Failed++
+			log.Printf("Artifact GC: failed to remove %s: %v", artifact.Key, err)
+			continue
+		}
+		report.Removed++
+		report.FreedBytes += artifact.Size
+		log.Printf("Artifact GC: removed %s (%d bytes, modified %s)", artifact.Key, artifact.Size, artifact.ModTime.Format(time.RFC3339))
+		s.removeEmptyParents(artifact.Key)
+	}
+	return report, nil
+}
+
+// Run is the periodic entry point used by the background scheduler.
+func (s *ArtifactSweeper) Run(ctx context.Context, dryRun bool) error {
+	report, err := s.Sweep(ctx, dryRun)
+	if err != nil {
+		return err
+	}
+	log.Printf("Artifact GC finished: dry_run=%t scanned=%d referenced=%d too_recent=%d orphaned=%d removed=%d failed=%d freed_bytes=%d",
+		report.DryRun, report.Scanned, report.Referenced, report.TooRecent, len(report.Orphaned), report.Removed, report.Failed, report.FreedBytes)
+	return nil
+}
+
+// removeEmptyParents cleans up directories such as waveforms/<music-id>/ once their last file is gone.
+func (s *ArtifactSweeper) removeEmptyParents(key string) {
+	for dir := path.Dir(key); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		p := filepath.Join(s.storage.Root(), filepath.FromSlash(dir))
+		if err := os.Remove(p); err != nil {
+			return // not empty (or already gone)
+		}
+	}
+}
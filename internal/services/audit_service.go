@@ -0,0 +1,79 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+)
+
+// AuditEvent describes one audited action. Handlers fill the request details (actor, IP, user agent).
+type AuditEvent struct {
+	ActorID    uuid.UUID // uuid.Nil for anonymous actors (e.g. a failed login)
+	ActorName  string
+	Action     string
+	TargetType string
+	TargetID   string
+	IPAddress  string
+	UserAgent  string
+	// Diff is stored as JSON; use AuditDiff for before/after changes or any map for extra details.
+	Diff interface{}
+}
+
+// AuditService records security- and content-relevant actions.
+// Recording never fails the request; errors are only logged.
+type AuditService struct {
+	repo *repository.Repository
+}
+
+func NewAuditService(repo *repository.Repository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+func (s *AuditService) Record(event AuditEvent) {
+	entry := &models.AuditLog{
+		ActorName:  Truncate(event.ActorName, 255),
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   Truncate(event.TargetID, 64),
+		IPAddress:  Truncate(event.IPAddress, 64),
+		UserAgent:  Truncate(event.UserAgent, 512),
+	}
+	if event.ActorID != uuid.Nil {
+		actorID := event.ActorID
+		entry.ActorID = &actorID
+	}
+	if event.Diff != nil {
+		diff, err := json.Marshal(event.Diff)
+		if err != nil {
+			log.Printf("Audit: could not encode diff for %s: %v", event.Action, err)
+		} else if string(diff) != "null" {
+			entry.Diff = diff
+		}
+	}
+	if err := s.repo.AuditLog.Create(entry); err != nil {
+		log.Printf("Audit: failed to record %s by %q on %s %s: %v", event.Action, event.ActorName, event.TargetType, event.TargetID, err)
+	}
+}
+
+// AuditChange is the before/after pair of a single field.
+type AuditChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// AuditDiff returns the fields whose values differ between before and after.
+// Fields only present in after are reported with a nil "from".
+func AuditDiff(before, after map[string]interface{}) map[string]AuditChange {
+	diff := make(map[string]AuditChange)
+	for key, to := range after {
+		from := before[key]
+		if !reflect.DeepEqual(from, to) {
+			diff[key] = AuditChange{From: from, To: to}
+		}
+	}
+	return diff
+}
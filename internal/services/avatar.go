@@ -0,0 +1,80 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif" // image.Decode için format kaydı
+	_ "image/jpeg"
+	"image/png"
+)
+
+const (
+	avatarSize         = 256
+	avatarMaxDimension = 4096
+)
+
+var ErrAvatarInvalid = errors.New("avatar must be a PNG, JPEG or GIF image")
+
+// processAvatar decodes an uploaded image, crops it to a centered square and scales it down to
+// avatarSize. Re-encoding as PNG drops metadata (e.g. EXIF location) and anything that is not pixels.
+func processAvatar(data []byte) ([]byte, error) {
+	return processSquareImage(data, avatarSize, ErrAvatarInvalid)
+}
+
+// processSquareImage is the shared implementation of avatar and playlist cover processing;
+// invalid is returned for anything that is not a decodable image of acceptable dimensions.
+func processSquareImage(data []byte, size int, invalid error) ([]byte, error) {
+	// Boyutlar tüm görüntü açılmadan kontrol edilir; küçük dosyada dev çözünürlük belleği tüketmesin
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, invalid
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > avatarMaxDimension || cfg.Height > avatarMaxDimension {
+		return nil, invalid
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, invalid
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, squareThumbnail(src, size)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// squareThumbnail crops src to its centered square and downsamples it with a box filter.
+// Images smaller than size keep their resolution.
+func squareThumbnail(src image.Image, size int) *image.RGBA {
+	b := src.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	if side < size {
+		size = side
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		sy0, sy1 := y0+y*side/size, y0+(y+1)*side/size
+		for x := 0; x < size; x++ {
+			sx0, sx1 := x0+x*side/size, x0+(x+1)*side/size
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(pr), g+uint64(pg), bl+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
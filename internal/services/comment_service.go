@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	commentMaxLength    = 2000
+	commentRateWindow   = 10 * time.Minute
+	commentMaxTimestamp = 24 * 60 * 60 // Parça süresi saklanmadığı için makul bir üst sınır
+)
+
+var (
+	ErrCommentEmpty       = errors.New("comment is empty")
+	ErrCommentTooLong     = errors.New("comment is too long")
+	ErrCommentTimestamp   = errors.New("timestamp must look like 0:42 or 1:02:03")
+	ErrCommentParent      = errors.New("the comment you are replying to no longer exists")
+	ErrCommentForbidden   = errors.New("you cannot change this comment")
+	ErrCommentRateLimited = errors.New("too many comments, please slow down")
+)
+
+// CommentService posts, edits and deletes comments on tracks. Whether the track itself may be
+// viewed (and so commented on) is checked by the caller.
+type CommentService struct {
+	repo    *repository.Repository
+	limiter *RateLimiter
+}
+
+func NewCommentService(repo *repository.Repository, rateLimit int) *CommentService {
+	return &CommentService{repo: repo, limiter: NewRateLimiter(rateLimit, commentRateWindow)}
+}
+
+// Post stores a new comment by authorID on music. parentID makes it a reply; replies to a reply are
+// attached to the same top-level comment so threads stay one level deep. timestamp is optional ("0:42").
+func (s *CommentService) Post(authorID uuid.UUID, music *models.Music, parentID *uuid.UUID, body, timestamp string) (*models.Comment, error) {
+	body, err := sanitizeCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	seconds, err := ParseCommentTimestamp(timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{MusicID: music.ID, UserID: authorID, Body: body, TimestampSeconds: seconds}
+	if parentID != nil {
+		parent, err := s.repo.Comment.GetByID(*parentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrCommentParent
+			}
+			return nil, err
+		}
+		if parent.MusicID != music.ID {
+			return nil, ErrCommentParent
+		}
+		if parent.ParentID != nil {
+			parentID = parent.ParentID
+		}
+		comment.ParentID = parentID
+	}
+
+	// Geçersiz istekler sınırdan düşmesin diye kontrol doğrulamadan sonra yapılır
+	if !s.limiter.Allow(authorID.String()) {
+		return nil, ErrCommentRateLimited
+	}
+	if err := s.repo.Comment.Create(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// Edit replaces the body and timestamp of the comment. Only the author may edit.
+func (s *CommentService) Edit(comment *models.Comment, editorID uuid.UUID, body, timestamp string) error {
+	if comment.UserID != editorID {
+		return ErrCommentForbidden
+	}
+	body, err := sanitizeCommentBody(body)
+	if err != nil {
+		return err
+	}
+	seconds, err := ParseCommentTimestamp(timestamp)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := s.repo.Comment.UpdateBody(comment.ID, body, seconds, now); err != nil {
+		return err
+	}
+	comment.Body, comment.TimestampSeconds, comment.EditedAt = body, seconds, &now
+	return nil
+}
+
+// CanDeleteComment reports whether the user may delete the comment: its author, the owner of the track
+// and moderators (or admins) may.
+func CanDeleteComment(comment *models.Comment, music *models.Music, userID uuid.UUID, role string) bool {
+	if userID == uuid.Nil {
+		return false
+	}
+	return comment.UserID == userID ||
+		(music.UserID != nil && *music.UserID == userID) ||
+		models.RoleAtLeast(role, models.RoleModerator)
+}
+
+// Delete removes the comment and, for a top-level comment, its replies.
+func (s *CommentService) Delete(comment *models.Comment, music *models.Music, userID uuid.UUID, role string) error {
+	if !CanDeleteComment(comment, music, userID, role) {
+		return ErrCommentForbidden
+	}
+	return s.repo.Comment.Delete(comment.ID)
+}
+
+var (
+	commentBlankLines = regexp.MustCompile(`\n{3,}`)
+	commentTimestamp  = regexp.MustCompile(`^(?:(\d{1,2}):)?(\d{1,3}):(\d{2})$`)
+)
+
+// sanitizeCommentBody yorumu düz metin olarak saklanacak hale getirir: geçersiz UTF-8'i, kontrol
+// karakterlerini ve metnin görünümünü değiştirebilen görünmez/yön karakterlerini atar, satır sonlarını
+// birleştirir ve uzunluğu doğrular. HTML escape işlemi görüntülerken template tarafından yapılır.
+func sanitizeCommentBody(body string) (string, error) {
+	body = strings.ToValidUTF8(body, "")
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	body = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n':
+			return r
+		case r == '\t':
+			return ' '
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r): // Cf: sıfır genişlikli ve bidi karakterleri
+			return -1
+		}
+		return r
+	}, body)
+
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	body = strings.TrimSpace(commentBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+
+	switch {
+	case body == "":
+		return "", ErrCommentEmpty
+	case utf8.RuneCountInString(body) > commentMaxLength:
+		return "", ErrCommentTooLong
+	}
+	return body, nil
+}
+
+// ParseCommentTimestamp "0:42" veya "1:02:03" biçimindeki parça anını saniyeye çevirir. Boş değer nil döner.
+func ParseCommentTimestamp(raw string) (*int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	match := commentTimestamp.FindStringSubmatch(raw)
+	if match == nil {
+		return nil, ErrCommentTimestamp
+	}
+	hours := 0
+	if match[1] != "" {
+		hours, _ = strconv.Atoi(match[1])
+	}
+	minutes, _ := strconv.Atoi(match[2])
+	seconds, _ := strconv.Atoi(match[3])
+	// Saat verildiyse dakika, her durumda da saniye 60'tan küçük olmalıdır
+	if seconds >= 60 || (match[1] != "" && minutes >= 60) {
+		return nil, ErrCommentTimestamp
+	}
+	total := hours*3600 + minutes*60 + seconds
+	if total > commentMaxTimestamp {
+		return nil, ErrCommentTimestamp
+	}
+	return &total, nil
+}
+
+// FormatCommentTimestamp saniyeyi "0:42" veya "1:02:03" biçiminde yazar.
+func FormatCommentTimestamp(seconds int) string {
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds%3600/60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
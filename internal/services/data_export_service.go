@@ -0,0 +1,413 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	dataExportBatchSize = 20
+	// Bu süreden uzun süredir "processing" durumunda kalan işler çökmüş sayılıp tekrar kuyruğa alınır
+	dataExportStaleAfter = time.Hour
+	dataExportListLimit  = 10
+)
+
+var (
+	ErrDataExportInProgress = errors.New("a data export is already being prepared")
+	ErrDataExportCooldown   = errors.New("a data export was requested recently")
+	ErrDataExportNotReady   = errors.New("data export is not available for download")
+)
+
+// DataExportCooldownError carries how long the user has to wait before requesting a new export.
+type DataExportCooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *DataExportCooldownError) Error() string {
+	return fmt.Sprintf("%v: retry after %s", ErrDataExportCooldown, e.RetryAfter)
+}
+
+func (e *DataExportCooldownError) Unwrap() error {
+	return ErrDataExportCooldown
+}
+
+// DataExportService builds zip archives with everything stored about a user (GDPR "right of access").
+// Archives are written outside the public generated directory and are only served to their owner.
+type DataExportService struct {
+	repo     *repository.Repository
+	storage  *LocalStorage
+	mailer   Mailer
+	dir      string
+	baseURL  string
+	ttl      time.Duration
+	cooldown time.Duration
+	wake     chan struct{}
+}
+
+func NewDataExportService(repo *repository.Repository, storage *LocalStorage, mailer Mailer, dir, baseURL string, ttl, cooldown time.Duration) (*DataExportService, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create data export directory %s: %w", dir, err)
+	}
+	return &DataExportService{
+		repo:     repo,
+		storage:  storage,
+		mailer:   mailer,
+		dir:      dir,
+		baseURL:  baseURL,
+		ttl:      ttl,
+		cooldown: cooldown,
+		wake:     make(chan struct{}, 1),
+	}, nil
+}
+
+// Request queues a new export for the user. Only one export can be in progress and new requests
+// are rate limited by the cooldown.
+func (s *DataExportService) Request(userID uuid.UUID, includeFiles bool) (*models.DataExport, error) {
+	now := time.Now()
+	latest, err := s.repo.DataExport.LatestForUser(userID)
+	switch {
+	case err == nil:
+		if latest.Status == models.DataExportPending || latest.Status == models.DataExportProcessing {
+			return nil, ErrDataExportInProgress
+		}
+		// Başarısız işler beklemeden tekrar denenebilir
+		if latest.Status != models.DataExportFailed {
+			if next := latest.CreatedAt.Add(s.cooldown); now.Before(next) {
+				return nil, &DataExportCooldownError{RetryAfter: next.Sub(now)}
+			}
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	export := &models.DataExport{
+		ID:           uuid.New(),
+		UserID:       userID,
+		Status:       models.DataExportPending,
+		IncludeFiles: includeFiles,
+		CreatedAt:    now,
+	}
+	if err := s.repo.DataExport.Create(export); err != nil {
+		return nil, err
+	}
+	s.signal()
+	return export, nil
+}
+
+// signal worker'ı uyandırır; zaten bir sinyal bekliyorsa yenisi düşer, kuyruktaki iş yine alınır.
+func (s *DataExportService) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// List returns the user's most recent exports.
+func (s *DataExportService) List(userID uuid.UUID) ([]models.DataExport, error) {
+	return s.repo.DataExport.ListForUser(userID, dataExportListLimit)
+}
+
+// Archive returns the export and the path of its archive if the user may download it.
+func (s *DataExportService) Archive(userID, exportID uuid.UUID) (*models.DataExport, string, error) {
+	export, err := s.repo.DataExport.GetForUser(userID, exportID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !export.IsDownloadable(time.Now()) || export.ArchiveName == "" {
+		return nil, "", ErrDataExportNotReady
+	}
+	return export, filepath.Join(s.dir, export.ArchiveName), nil
+}
+
+// DeleteForUser removes every archive of the user; used when the account is deleted.
+func (s *DataExportService) DeleteForUser(userID uuid.UUID) error {
+	names, err := s.repo.DataExport.ListArchivesForUser(userID)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		s.removeArchive(name)
+	}
+	return nil
+}
+
+// RunWorker processes queued exports until ctx is cancelled. It wakes up when a new export is
+// requested and otherwise polls once a minute, so exports queued before a restart are not lost.
+// Exports interrupted by a restart are re-queued by PruneExpired once they are considered stale.
+func (s *DataExportService) RunWorker(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		if err := s.processPending(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Data export: processing queue failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			log.Println("Data export worker stopped.")
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// PruneExpired deletes expired archives and old failed exports, and re-queues exports stuck in processing.
+func (s *DataExportService) PruneExpired(ctx context.Context) error {
+	now := time.Now()
+	if reset, err := s.repo.DataExport.ResetStale(now.Add(-dataExportStaleAfter)); err != nil {
+		return err
+	} else if reset > 0 {
+		log.Printf("Data export prune: re-queued %d interrupted export(s)", reset)
+		s.signal()
+	}
+	deleted := 0
+	for {
+		batch, err := s.repo.DataExport.ListExpired(now, dataExportBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for _, export := range batch {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			s.removeArchive(export.ArchiveName)
+			if err := s.repo.DataExport.Delete(export.ID); err != nil {
+				return err
+			}
+			deleted++
+		}
+	}
+	if deleted > 0 {
+		log.Printf("Data export prune: deleted %d export(s)", deleted)
+	}
+	return nil
+}
+
+func (s *DataExportService) processPending(ctx context.Context) error {
+	for {
+		batch, err := s.repo.DataExport.ListPending(dataExportBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		claimed := 0
+		for i := range batch {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if s.process(&batch[i]) {
+				claimed++
+			}
+		}
+		// Hiçbiri alınamadıysa (veritabanı hatası) aynı işler üzerinde dönüp durulmaz
+		if claimed == 0 {
+			return nil
+		}
+	}
+}
+
+// process builds one export and reports whether this worker claimed it.
+func (s *DataExportService) process(export *models.DataExport) bool {
+	claimed, err := s.repo.DataExport.Claim(export.ID, time.Now())
+	if err != nil {
+		log.Printf("Data export: claiming %s failed: %v", export.ID, err)
+		return false
+	}
+	if !claimed {
+		return false
+	}
+
+	started := time.Now()
+	name, size, user, err := s.build(export)
+	if err != nil {
+		log.Printf("Data export: building %s for user %s failed: %v", export.ID, export.UserID, err)
+		if markErr := s.repo.DataExport.MarkFailed(export.ID, "The archive could not be created.", time.Now()); markErr != nil {
+			log.Printf("Data export: marking %s as failed failed: %v", export.ID, markErr)
+		}
+		return true
+	}
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
+	if err := s.repo.DataExport.MarkReady(export.ID, name, size, now, expiresAt); err != nil {
+		log.Printf("Data export: marking %s as ready failed: %v", export.ID, err)
+		s.removeArchive(name)
+		return true
+	}
+	log.Printf("Data export %s for user %s ready (%d bytes, %s)", export.ID, export.UserID, size, time.Since(started).Round(time.Millisecond))
+	s.notifyReady(user, expiresAt)
+	return true
+}
+
+// build writes the archive to a temporary file and renames it into place once complete.
+func (s *DataExportService) build(export *models.DataExport) (string, int64, *models.User, error) {
+	snapshot, err := s.repo.DataExport.Snapshot(export.UserID)
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("reading user data: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.zip", export.ID, randomHex(8))
+	finalPath := filepath.Join(s.dir, name)
+	tmpPath := finalPath + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	defer os.Remove(tmpPath) // Başarılı durumda dosya zaten taşınmış olur
+
+	zw := zip.NewWriter(f)
+	if err := s.writeArchive(zw, snapshot, export); err != nil {
+		zw.Close()
+		f.Close()
+		return "", 0, nil, err
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return "", 0, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return "", 0, nil, err
+	}
+	if err := f.Close(); err != nil {
+		return "", 0, nil, err
+	}
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		return "", 0, nil, err
+	}
+	return name, info.Size(), &snapshot.User, nil
+}
+
+func (s *DataExportService) writeArchive(zw *zip.Writer, snapshot *repository.UserDataSnapshot, export *models.DataExport) error {
+	user := &snapshot.User
+	tracks := make([]exportTrack, 0, len(snapshot.Music))
+	var files []exportFile
+	for _, m := range snapshot.Music {
+		track := newExportTrack(&m)
+		if export.IncludeFiles {
+			for _, u := range []string{m.Mp3FilePath, m.MidiFilePath, m.CoverArtPath} {
+				if key, ok := s.storage.KeyFromURL(u); ok {
+					archivePath := path.Join("files", "tracks", m.ID.String(), path.Base(key))
+					files = append(files, exportFile{key: key, archivePath: archivePath})
+					track.Files = append(track.Files, archivePath)
+				}
+			}
+		}
+		tracks = append(tracks, track)
+	}
+	profile := newExportProfile(user, s.storage)
+	if export.IncludeFiles && user.AvatarKey != "" {
+		profile.AvatarFile = path.Join("files", "avatar"+path.Ext(user.AvatarKey))
+		files = append(files, exportFile{key: user.AvatarKey, archivePath: profile.AvatarFile})
+	}
+
+	documents := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", profile},
+		{"tracks.json", tracks},
+		{"likes.json", exportLikes(snapshot.Likes)},
+		{"following.json", exportFollowing(snapshot.Following)},
+		{"playlists.json", exportPlaylists(snapshot.Playlists)},
+		{"comments.json", exportComments(snapshot.Comments)},
+		{"generations.json", exportGenerations(snapshot.Generations)},
+		{"sessions.json", exportSessions(snapshot.Sessions)},
+		{"linked_accounts.json", exportIdentities(snapshot.Identities)},
+		{"access_tokens.json", exportAccessTokens(snapshot.AccessTokens)},
+		{"activity_log.json", exportAuditLogs(snapshot.AuditLogs)},
+	}
+	if err := writeZipFile(zw, "README.txt", []byte(exportReadme(user, export, time.Now()))); err != nil {
+		return err
+	}
+	for _, doc := range documents {
+		data, err := json.MarshalIndent(doc.data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding %s: %w", doc.name, err)
+		}
+		if err := writeZipFile(zw, doc.name, data); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range files {
+		if err := s.copyStoredFile(zw, file); err != nil {
+			// Kayıp tek bir dosya tüm arşivi bozmasın; eksik dosya loglanır
+			log.Printf("Data export %s: skipping %s: %v", export.ID, file.key, err)
+		}
+	}
+	return nil
+}
+
+func (s *DataExportService) copyStoredFile(zw *zip.Writer, file exportFile) error {
+	src, err := s.storage.Open(file.key)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	// Ses ve görüntü dosyaları zaten sıkıştırılmış; tekrar sıkıştırmak yalnızca CPU harcar
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: file.archivePath, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
+
+func (s *DataExportService) removeArchive(name string) {
+	if name == "" {
+		return
+	}
+	if err := os.Remove(filepath.Join(s.dir, filepath.Base(name))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Data export: removing archive %s failed: %v", name, err)
+	}
+}
+
+func (s *DataExportService) notifyReady(user *models.User, expiresAt time.Time) {
+	msg := MailMessage{
+		To:      user.Email,
+		Subject: "Your Aurify data export is ready",
+		Body: fmt.Sprintf(`Hi %s,
+
+The copy of your Aurify data you requested is ready. You can download it from your privacy settings
+until %s (UTC):
+
+%s/settings/privacy
+
+If you did not request this export, please change your password.
+`, user.Username, expiresAt.UTC().Format("02 Jan 2006 15:04"), s.baseURL),
+	}
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			log.Printf("Data export: sending ready notice to user %s failed: %v", user.ID, err)
+		}
+	}()
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/morgarakt/aurify/internal/models"
+)
+
+// Arşive yazılan belgeler. Modeller doğrudan serileştirilmez: şifre özeti, TOTP secret'ı ve token
+// özetleri gibi alanlar arşive hiçbir zaman girmemeli, yeni eklenen alanlar da bilinçli olarak eklenmeli.
+
+type exportFile struct {
+	key         string
+	archivePath string
+}
+
+type exportProfile struct {
+	ID               string     `json:"id"`
+	Username         string     `json:"username"`
+	Email            string     `json:"email"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	Role             string     `json:"role"`
+	Bio              string     `json:"bio"`
+	AvatarURL        string     `json:"avatar_url,omitempty"`
+	AvatarFile       string     `json:"avatar_file,omitempty"`
+	ShowLikes        bool       `json:"show_likes_on_profile"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	SuspendedAt      *time.Time `json:"suspended_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+func newExportProfile(user *models.User, storage *LocalStorage) exportProfile {
+	profile := exportProfile{
+		ID:               user.ID.String(),
+		Username:         user.Username,
+		Email:            user.Email,
+		EmailVerifiedAt:  user.EmailVerifiedAt,
+		Role:             user.Role,
+		Bio:              user.Bio,
+		ShowLikes:        user.ShowLikes,
+		TwoFactorEnabled: user.TwoFactorEnabled(),
+		SuspendedAt:      user.SuspendedAt,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+	}
+	if user.AvatarKey != "" {
+		profile.AvatarURL = storage.URLFor(user.AvatarKey)
+	}
+	return profile
+}
+
+type exportTrack struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Mood        string     `json:"mood,omitempty"`
+	License     string     `json:"license,omitempty"`
+	Tags        []string   `json:"tags"`
+	Genre       string     `json:"genre"`
+	Model       string     `json:"model"`
+	IsPublic    bool       `json:"is_public"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	LikesCount  int        `json:"likes_count"`
+	Mp3URL      string     `json:"mp3_url,omitempty"`
+	MidiURL     string     `json:"midi_url,omitempty"`
+	CoverURL    string     `json:"cover_url,omitempty"`
+	Files       []string   `json:"files,omitempty"` // Arşivdeki dosya yolları
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+func newExportTrack(m *models.Music) exportTrack {
+	track := exportTrack{
+		ID:          m.ID.String(),
+		Title:       m.Title,
+		Description: m.Description,
+		Mood:        m.Mood,
+		License:     m.License,
+		Tags:        make([]string, 0, len(m.Tags)),
+		Genre:       m.MusicType.Name,
+		Model:       m.ModelType.Name,
+		IsPublic:    m.IsPublic,
+		PublishedAt: m.PublishedAt,
+		LikesCount:  m.LikesCount,
+		Mp3URL:      m.Mp3FilePath,
+		MidiURL:     m.MidiFilePath,
+		CoverURL:    m.CoverArtPath,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+	for _, tag := range m.Tags {
+		track.Tags = append(track.Tags, tag.Name)
+	}
+	if m.DeletedAt.Valid {
+		deletedAt := m.DeletedAt.Time
+		track.DeletedAt = &deletedAt
+	}
+	return track
+}
+
+type exportLike struct {
+	MusicID string    `json:"music_id"`
+	Title   string    `json:"title"`
+	LikedAt time.Time `json:"liked_at"`
+}
+
+func exportLikes(likes []models.UserLikesMusic) []exportLike {
+	out := make([]exportLike, 0, len(likes))
+	for _, like := range likes {
+		out = append(out, exportLike{MusicID: like.MusicID.String(), Title: like.Music.Title, LikedAt: like.CreatedAt})
+	}
+	return out
+}
+
+type exportFollow struct {
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+func exportFollowing(follows []models.UserFollow) []exportFollow {
+	out := make([]exportFollow, 0, len(follows))
+	for _, f := range follows {
+		out = append(out, exportFollow{Username: f.Followee.Username, FollowedAt: f.CreatedAt})
+	}
+	return out
+}
+
+type exportPlaylist struct {
+	ID          string                `json:"id"`
+	Title       string                `json:"title"`
+	Description string                `json:"description,omitempty"`
+	Visibility  string                `json:"visibility"`
+	Tracks      []exportPlaylistTrack `json:"tracks"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+type exportPlaylistTrack struct {
+	MusicID string    `json:"music_id"`
+	Title   string    `json:"title"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+func exportPlaylists(playlists []models.Playlist) []exportPlaylist {
+	out := make([]exportPlaylist, 0, len(playlists))
+	for _, p := range playlists {
+		tracks := make([]exportPlaylistTrack, 0, len(p.Entries))
+		for _, e := range p.Entries {
+			tracks = append(tracks, exportPlaylistTrack{MusicID: e.MusicID.String(), Title: e.Music.Title, AddedAt: e.CreatedAt})
+		}
+		out = append(out, exportPlaylist{
+			ID:          p.ID.String(),
+			Title:       p.Title,
+			Description: p.Description,
+			Visibility:  p.Visibility,
+			Tracks:      tracks,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+		})
+	}
+	return out
+}
+
+type exportComment struct {
+	ID         string     `json:"id"`
+	MusicID    string     `json:"music_id"`
+	MusicTitle string     `json:"music_title"`
+	ReplyTo    string     `json:"reply_to,omitempty"`
+	Body       string     `json:"body"`
+	Timestamp  string     `json:"timestamp,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	EditedAt   *time.Time `json:"edited_at,omitempty"`
+}
+
+func exportComments(comments []models.Comment) []exportComment {
+	out := make([]exportComment, 0, len(comments))
+	for _, c := range comments {
+		comment := exportComment{
+			ID:         c.ID.String(),
+			MusicID:    c.MusicID.String(),
+			MusicTitle: c.Music.Title,
+			Body:       c.Body,
+			CreatedAt:  c.CreatedAt,
+			EditedAt:   c.EditedAt,
+		}
+		if c.ParentID != nil {
+			comment.ReplyTo = c.ParentID.String()
+		}
+		if c.TimestampSeconds != nil {
+			comment.Timestamp = FormatCommentTimestamp(*c.TimestampSeconds)
+		}
+		out = append(out, comment)
+	}
+	return out
+}
+
+type exportGeneration struct {
+	ID        string    `json:"id"`
+	MusicID   string    `json:"music_id,omitempty"`
+	Genre     string    `json:"genre"`
+	Model     string    `json:"model"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func exportGenerations(generations []models.Generation) []exportGeneration {
+	out := make([]exportGeneration, 0, len(generations))
+	for _, g := range generations {
+		row := exportGeneration{ID: g.ID.String(), Genre: g.MusicTypeName, Model: g.ModelTypeName, Status: g.Status, CreatedAt: g.CreatedAt}
+		if g.MusicID != nil {
+			row.MusicID = g.MusicID.String()
+		}
+		out = append(out, row)
+	}
+	return out
+}
+
+type exportSession struct {
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func exportSessions(sessions []models.Session) []exportSession {
+	out := make([]exportSession, 0, len(sessions))
+	for _, s := range sessions {
+		out = append(out, exportSession{
+			UserAgent:  s.UserAgent,
+			IPAddress:  s.IPAddress,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			RevokedAt:  s.RevokedAt,
+		})
+	}
+	return out
+}
+
+type exportIdentity struct {
+	Issuer      string     `json:"issuer"`
+	Subject     string     `json:"subject"`
+	Email       string     `json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func exportIdentities(identities []models.Identity) []exportIdentity {
+	out := make([]exportIdentity, 0, len(identities))
+	for _, i := range identities {
+		out = append(out, exportIdentity{Issuer: i.Issuer, Subject: i.Subject, Email: i.Email, LastLoginAt: i.LastLoginAt, CreatedAt: i.CreatedAt})
+	}
+	return out
+}
+
+type exportAccessToken struct {
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func exportAccessTokens(tokens []models.PersonalAccessToken) []exportAccessToken {
+	out := make([]exportAccessToken, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, exportAccessToken{
+			Name:       t.Name,
+			Prefix:     t.Prefix,
+			Scopes:     t.ScopeList(),
+			ExpiresAt:  t.ExpiresAt,
+			LastUsedAt: t.LastUsedAt,
+			LastUsedIP: t.LastUsedIP,
+			RevokedAt:  t.RevokedAt,
+			CreatedAt:  t.CreatedAt,
+		})
+	}
+	return out
+}
+
+type exportAuditLog struct {
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type,omitempty"`
+	TargetID   string          `json:"target_id,omitempty"`
+	IPAddress  string          `json:"ip_address"`
+	UserAgent  string          `json:"user_agent"`
+	Details    json.RawMessage `json:"details,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func exportAuditLogs(entries []models.AuditLog) []exportAuditLog {
+	out := make([]exportAuditLog, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, exportAuditLog{
+			Action:     e.Action,
+			TargetType: e.TargetType,
+			TargetID:   e.TargetID,
+			IPAddress:  e.IPAddress,
+			UserAgent:  e.UserAgent,
+			Details:    e.Diff,
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+	return out
+}
+
+func exportReadme(user *models.User, export *models.DataExport, now time.Time) string {
+	files := "Generated audio, MIDI and cover files were not requested and are not included."
+	if export.IncludeFiles {
+		files = "files/                 Your generated audio, MIDI and cover files, and your avatar."
+	}
+	return fmt.Sprintf(`Aurify data export for %s
+Created %s (UTC)
+
+profile.json           Your account details.
+tracks.json            Every track in your library, including recently deleted ones, with metadata.
+likes.json             Tracks you liked.
+following.json         Accounts you follow.
+playlists.json         Your playlists and the tracks in them, in order.
+comments.json          Comments and replies you wrote on tracks.
+generations.json       Your music generation requests.
+sessions.json          Devices that signed in to your account.
+linked_accounts.json   Single sign-on accounts linked to your account.
+access_tokens.json     Personal access tokens (the secret values are never stored).
+activity_log.json      Security-relevant actions performed with your account.
+
+%s
+`, user.Username, now.UTC().Format("02 Jan 2006 15:04"), files)
+}
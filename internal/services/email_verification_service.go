@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrVerificationRateLimited  = errors.New("too many verification emails requested")
+	ErrVerificationTokenInvalid = errors.New("verification link is invalid or has expired")
+	ErrEmailAlreadyVerified     = errors.New("email address is already verified")
+)
+
+// EmailVerificationService sends verification links after registration and marks addresses as verified.
+type EmailVerificationService struct {
+	repo    *repository.Repository
+	mailer  Mailer
+	baseURL string
+	ttl     time.Duration
+	limiter *RateLimiter
+	// ADMIN_EMAILS kontrolü; listedeki adres ancak doğrulandığında admin yetkisi verir
+	adminEmails func(email string) bool
+}
+
+// NewEmailVerificationService allows resendLimit verification emails per user per hour.
+func NewEmailVerificationService(repo *repository.Repository, mailer Mailer, baseURL string, ttl time.Duration, resendLimit int, adminEmails func(string) bool) *EmailVerificationService {
+	return &EmailVerificationService{
+		repo:        repo,
+		mailer:      mailer,
+		baseURL:     baseURL,
+		ttl:         ttl,
+		limiter:     NewRateLimiter(resendLimit, time.Hour),
+		adminEmails: adminEmails,
+	}
+}
+
+// SendVerification emails a fresh verification link to the user's current address.
+// Earlier links stop working so only the newest email can be used.
+func (s *EmailVerificationService) SendVerification(user *models.User) error {
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+	if !s.limiter.Allow(user.ID.String()) {
+		return ErrVerificationRateLimited
+	}
+
+	secret, hash, err := utils.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if _, err := s.repo.EmailVerification.InvalidateForUser(user.ID, now); err != nil {
+		return err
+	}
+	token := &models.EmailVerificationToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: hash,
+		ExpiresAt: now.Add(s.ttl),
+		CreatedAt: now,
+	}
+	if err := s.repo.EmailVerification.Create(token); err != nil {
+		return err
+	}
+
+	msg := MailMessage{
+		To:      user.Email,
+		Subject: "Verify your Aurify email address",
+		Body: fmt.Sprintf(`Hi %s,
+
+Welcome to Aurify! Please confirm that this is your email address by opening the link below.
+It expires in %s.
+
+%s
+
+Until your address is verified you cannot publish tracks and your daily generation limit is reduced.
+If you did not create an Aurify account, you can ignore this email.
+`, user.Username, formatTTL(s.ttl), s.verifyURL(secret)),
+	}
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			log.Printf("Email verification: sending mail to user %s failed: %v", user.ID, err)
+		}
+	}()
+	return nil
+}
+
+// Verify consumes the link and marks the address it was sent to as verified.
+// Links sent to an address the user has since changed are rejected.
+func (s *EmailVerificationService) Verify(secret string) (*models.User, error) {
+	if secret == "" {
+		return nil, ErrVerificationTokenInvalid
+	}
+	now := time.Now()
+	token, err := s.repo.EmailVerification.Consume(utils.HashOpaqueToken(secret), now)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVerificationTokenInvalid
+		}
+		return nil, err
+	}
+	verified, err := s.repo.User.MarkEmailVerified(token.UserID, token.Email, now)
+	if err != nil {
+		return nil, err
+	}
+	if !verified {
+		return nil, ErrVerificationTokenInvalid
+	}
+	var user models.User
+	if err := s.repo.User.GetByID(token.UserID, &user); err != nil {
+		return nil, err
+	}
+	log.Printf("Email address of user %s verified", user.ID)
+
+	// Kayıtta ADMIN_EMAILS adresine admin verilmez; adresin sahibi olduğu burada kanıtlanmış olur
+	if s.adminEmails != nil && s.adminEmails(user.Email) && user.Role != models.RoleAdmin {
+		if err := s.repo.User.UpdateRole(user.ID, models.RoleAdmin); err != nil {
+			return nil, err
+		}
+		user.Role = models.RoleAdmin
+		log.Printf("User %s promoted to admin from ADMIN_EMAILS after verifying %s", user.ID, user.Email)
+	}
+	return &user, nil
+}
+
+// PruneExpired deletes used and expired verification tokens.
+func (s *EmailVerificationService) PruneExpired(ctx context.Context) error {
+	deleted, err := s.repo.EmailVerification.DeleteExpired(time.Now())
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("Email verification prune: deleted %d token(s)", deleted)
+	}
+	return nil
+}
+
+func (s *EmailVerificationService) verifyURL(secret string) string {
+	return s.baseURL + "/verify-email?token=" + url.QueryEscape(secret)
+}
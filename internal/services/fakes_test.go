@@ -0,0 +1,299 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Testlerde veritabanı yerine kullanılan bellek içi repository'ler. Arayüz gömülü olduğu için
+// burada yazılmayan bir metot çağrılırsa test nil pointer ile düşer; bu kasıtlıdır.
+
+type memUserRepo struct {
+	repository.UserRepository
+
+	mu    sync.Mutex
+	users map[uuid.UUID]*models.User
+}
+
+func newMemUserRepo(users ...*models.User) *memUserRepo {
+	r := &memUserRepo{users: make(map[uuid.UUID]*models.User)}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+	return r
+}
+
+func (r *memUserRepo) Create(user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *memUserRepo) GetByID(id any, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	uid, ok := id.(uuid.UUID)
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	u, ok := r.users[uid]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	*user = *u
+	return nil
+}
+
+// GetByEmail, gerçek repository gibi LOWER(email) = LOWER(?) ile eşleşir ve en eski hesabı döner.
+func (r *memUserRepo) GetByEmail(email string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found *models.User
+	for _, u := range r.users {
+		if strings.ToLower(u.Email) == strings.ToLower(email) && (found == nil || u.CreatedAt.Before(found.CreatedAt)) {
+			found = u
+		}
+	}
+	if found == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *found
+	return &copied, nil
+}
+
+func (r *memUserRepo) UpdatePasswordHash(userID uuid.UUID, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u, ok := r.users[userID]; ok {
+		u.PasswordHash = passwordHash
+	}
+	return nil
+}
+
+func (r *memUserRepo) ResetLoginFailures(userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u, ok := r.users[userID]; ok {
+		u.FailedLoginCount, u.LastFailedLoginAt, u.LockedUntil = 0, nil, nil
+	}
+	return nil
+}
+
+// ReserveLoginAttempt gerçek repository'deki koşullu UPDATE'i aynı kilit altında taklit eder.
+func (r *memUserRepo) ReserveLoginAttempt(userID uuid.UUID, now time.Time, rules repository.LoginThrottle) (*repository.LoginFailureState, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[userID]
+	if !ok || (u.LockedUntil != nil && u.LockedUntil.After(now)) {
+		return nil, false, nil
+	}
+	inWindow := u.LastFailedLoginAt != nil && !u.LastFailedLoginAt.Before(rules.WindowStart)
+	if inWindow && u.FailedLoginCount >= rules.DelayAfter {
+		delay := time.Second << uint(u.FailedLoginCount-rules.DelayAfter)
+		if delay > rules.MaxDelay || delay <= 0 {
+			delay = rules.MaxDelay
+		}
+		if u.LastFailedLoginAt.Add(delay).After(now) {
+			return nil, false, nil
+		}
+	}
+	if inWindow {
+		u.FailedLoginCount++
+	} else {
+		u.FailedLoginCount = 1
+	}
+	last := now
+	u.LastFailedLoginAt = &last
+	if u.FailedLoginCount >= rules.Threshold {
+		lockUntil := rules.LockUntil
+		u.LockedUntil = &lockUntil
+	}
+	return &repository.LoginFailureState{FailedLoginCount: u.FailedLoginCount, LockedUntil: u.LockedUntil}, true, nil
+}
+
+func (r *memUserRepo) AdvanceTOTPCounter(userID uuid.UUID, counter int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[userID]
+	if !ok || counter <= u.TOTPLastCounter {
+		return false, nil
+	}
+	u.TOTPLastCounter = counter
+	return true, nil
+}
+
+func (r *memUserRepo) UsernameExists(username string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if strings.EqualFold(u.Username, username) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memUserRepo) HardDelete(userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.users, userID)
+	return nil
+}
+
+func (r *memUserRepo) user(id uuid.UUID) models.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.users[id]
+}
+
+// memPasswordResetRepo, gerçek repository ile aynı koşulları uygular: kullanılmamış ve süresi dolmamış.
+type memPasswordResetRepo struct {
+	mu     sync.Mutex
+	tokens []*models.PasswordResetToken
+}
+
+func (r *memPasswordResetRepo) Create(token *models.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *memPasswordResetRepo) find(tokenHash string, now time.Time) *models.PasswordResetToken {
+	for _, t := range r.tokens {
+		if t.TokenHash == tokenHash && t.UsedAt == nil && t.ExpiresAt.After(now) {
+			return t
+		}
+	}
+	return nil
+}
+
+func (r *memPasswordResetRepo) FindValid(tokenHash string, now time.Time) (*models.PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.find(tokenHash, now)
+	if t == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *t
+	return &copied, nil
+}
+
+func (r *memPasswordResetRepo) Consume(tokenHash string, now time.Time) (*models.PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.find(tokenHash, now)
+	if t == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	usedAt := now
+	t.UsedAt = &usedAt
+	copied := *t
+	return &copied, nil
+}
+
+func (r *memPasswordResetRepo) InvalidateForUser(userID uuid.UUID, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.UsedAt == nil {
+			usedAt := now
+			t.UsedAt = &usedAt
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *memPasswordResetRepo) DeleteExpired(now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.tokens[:0]
+	var n int64
+	for _, t := range r.tokens {
+		if t.ExpiresAt.Before(now) || t.UsedAt != nil {
+			n++
+			continue
+		}
+		kept = append(kept, t)
+	}
+	r.tokens = kept
+	return n, nil
+}
+
+type memIdentityRepo struct {
+	repository.IdentityRepository
+
+	mu         sync.Mutex
+	identities []*models.Identity
+}
+
+func (r *memIdentityRepo) Create(identity *models.Identity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *memIdentityRepo) FindBySubject(issuer, subject string) (*models.Identity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, identity := range r.identities {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			copied := *identity
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memIdentityRepo) TouchLogin(id uuid.UUID, email string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, identity := range r.identities {
+		if identity.ID == id {
+			identity.Email, identity.LastLoginAt = email, &at
+		}
+	}
+	return nil
+}
+
+// memSessionRepo yalnızca iptal edilen kullanıcıları kaydeder.
+type memSessionRepo struct {
+	repository.SessionRepository
+
+	mu      sync.Mutex
+	revoked []uuid.UUID
+}
+
+func (r *memSessionRepo) RevokeAllForUser(userID, exceptID uuid.UUID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revoked = append(r.revoked, userID)
+	return 1, nil
+}
+
+// captureMailer gönderilen e-postaları kanala yazar; RequestReset postayı arka planda gönderir.
+type captureMailer struct {
+	sent chan MailMessage
+}
+
+func newCaptureMailer() *captureMailer {
+	return &captureMailer{sent: make(chan MailMessage, 8)}
+}
+
+func (m *captureMailer) Send(msg MailMessage) error {
+	m.sent <- msg
+	return nil
+}
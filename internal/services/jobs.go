@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunPeriodic runs fn every interval until ctx is cancelled. The first run happens
+// after one interval so startup is not slowed down by housekeeping work.
+// A non-positive interval disables the job.
+func RunPeriodic(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	if interval <= 0 {
+		log.Printf("Background job %s is disabled.", name)
+		return
+	}
+	log.Printf("Background job %s scheduled every %s.", name, interval)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				log.Printf("Background job %s stopped.", name)
+				return
+			case <-ticker.C:
+				if err := fn(ctx); err != nil {
+					log.Printf("Background job %s failed: %v", name, err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/morgarakt/aurify/internal/config"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+)
+
+const (
+	// loginDelayAfter is the number of consecutive failures after which each further attempt must wait.
+	loginDelayAfter = 3
+	// loginMaxDelay caps the progressive wait between attempts.
+	loginMaxDelay = time.Minute
+	// loginGuardSweepSize is the number of tracked keys after which stale in-memory entries are swept.
+	loginGuardSweepSize = 10000
+)
+
+// LoginBlockedError is returned by Begin when an attempt is not allowed yet. RetryAfter is how long
+// the caller has to wait; Locked tells a lockout apart from a short progressive delay.
+type LoginBlockedError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginBlockedError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed sign-in attempts, locked for %s", e.RetryAfter)
+	}
+	return fmt.Sprintf("sign-in attempted too soon, retry after %s", e.RetryAfter)
+}
+
+// loginAttempts is the in-memory failure state of an IP address or an email without an account.
+type loginAttempts struct {
+	count       int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// LoginGuard slows down password guessing. Failures are counted per account (in the database, so
+// they survive restarts and are shared between instances) and per IP address (in memory).
+// After a few failures every further attempt has to wait progressively longer; at the threshold the
+// account is locked for a while and the owner is emailed.
+//
+// Emails without an account are tracked in memory with exactly the same rules, so responses never
+// reveal whether an account exists.
+type LoginGuard struct {
+	repo        *repository.Repository
+	mailer      Mailer
+	baseURL     string
+	maxAttempts int
+	window      time.Duration
+	lockout     time.Duration
+	ipLimit     int
+
+	mu      sync.Mutex
+	unknown map[string]*loginAttempts
+	ips     map[string]*loginAttempts
+}
+
+func NewLoginGuard(repo *repository.Repository, mailer Mailer, cfg *config.Config) *LoginGuard {
+	return &LoginGuard{
+		repo:        repo,
+		mailer:      mailer,
+		baseURL:     cfg.AppBaseURL,
+		maxAttempts: cfg.LoginMaxFailedAttempts,
+		window:      cfg.LoginFailureWindow,
+		lockout:     cfg.LoginLockoutDuration,
+		ipLimit:     cfg.LoginIPMaxFailedAttempts,
+		unknown:     make(map[string]*loginAttempts),
+		ips:         make(map[string]*loginAttempts),
+	}
+}
+
+// LoginAttempt is a sign-in attempt reserved by Begin. It is already counted as a failure; the
+// caller reports the outcome of the password check with Fail or Succeed.
+type LoginAttempt struct {
+	guard  *LoginGuard
+	user   *models.User
+	ip     string
+	locked bool
+}
+
+// Begin reserves a sign-in attempt for email (user is nil when no account has it) from ip before
+// the password is checked. The attempt is counted up front in one atomic step, so parallel requests
+// cannot all pass the check before any of them records its failure. It returns a *LoginBlockedError
+// when the attempt has to be rejected.
+func (g *LoginGuard) Begin(email string, user *models.User, ip string) (*LoginAttempt, error) {
+	now := time.Now()
+	g.mu.Lock()
+	g.sweep(now)
+	if state, ok := g.ips[ip]; ok && now.Before(state.lockedUntil) {
+		g.mu.Unlock()
+		return nil, &LoginBlockedError{RetryAfter: state.lockedUntil.Sub(now), Locked: true}
+	}
+	if user == nil {
+		key := emailKey(email)
+		if state, ok := g.unknown[key]; ok {
+			if err := g.blocked(state.count, state.lastFailure, state.lockedUntil, now); err != nil {
+				g.mu.Unlock()
+				return nil, err
+			}
+		}
+		g.failIP(ip, now)
+		g.fail(g.unknown, key, g.maxAttempts, now)
+		g.mu.Unlock()
+		return &LoginAttempt{guard: g, ip: ip}, nil
+	}
+	g.failIP(ip, now)
+	g.mu.Unlock()
+
+	state, ok, err := g.repo.User.ReserveLoginAttempt(user.ID, now, repository.LoginThrottle{
+		WindowStart: now.Add(-g.window),
+		Threshold:   g.maxAttempts,
+		LockUntil:   now.Add(g.lockout),
+		DelayAfter:  loginDelayAfter,
+		MaxDelay:    loginMaxDelay,
+	})
+	if err != nil || !ok {
+		g.releaseIP(ip)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// Başka bir istek sayacı bizden önce değiştirmiş olabilir; bekleme süresi güncel kayıttan hesaplanır
+		var current models.User
+		if err := g.repo.User.GetByID(user.ID, &current); err == nil {
+			user = &current
+		}
+		var lastFailure, lockedUntil time.Time
+		if user.LastFailedLoginAt != nil {
+			lastFailure = *user.LastFailedLoginAt
+		}
+		if user.LockedUntil != nil {
+			lockedUntil = *user.LockedUntil
+		}
+		if blocked := g.blocked(user.FailedLoginCount, lastFailure, lockedUntil, time.Now()); blocked != nil {
+			return nil, blocked
+		}
+		return nil, &LoginBlockedError{RetryAfter: time.Second}
+	}
+
+	user.FailedLoginCount = state.FailedLoginCount
+	user.LastFailedLoginAt = &now
+	user.LockedUntil = state.LockedUntil
+	return &LoginAttempt{guard: g, user: user, ip: ip, locked: user.IsLocked(now)}, nil
+}
+
+// Fail confirms the reserved attempt as a wrong password (or unknown email). When this attempt
+// locked the account the owner is notified by email. It reports whether the account was locked.
+func (a *LoginAttempt) Fail() bool {
+	if !a.locked {
+		return false
+	}
+	log.Printf("Login guard: locked user %s until %s after %d failed attempts", a.user.ID, a.user.LockedUntil.Format(time.RFC3339), a.user.FailedLoginCount)
+	a.guard.notifyLocked(a.user, a.user.FailedLoginCount)
+	return true
+}
+
+// Succeed clears the account's failure counter after a correct password and gives back the IP's
+// reserved attempt. Earlier IP failures are kept: one valid login must not hide guessing against
+// other accounts.
+func (a *LoginAttempt) Succeed() {
+	a.guard.releaseIP(a.ip)
+	if a.user == nil {
+		return
+	}
+	if err := a.guard.repo.User.ResetLoginFailures(a.user.ID); err != nil {
+		log.Printf("Login guard: resetting failures of user %s failed: %v", a.user.ID, err)
+		return
+	}
+	a.user.FailedLoginCount = 0
+	a.user.LastFailedLoginAt = nil
+	a.user.LockedUntil = nil
+}
+
+// Unlock lifts a lockout early (admin action).
+func (g *LoginGuard) Unlock(user *models.User) error {
+	return g.repo.User.ResetLoginFailures(user.ID)
+}
+
+// blocked applies the progressive delay and lockout rules to one failure counter.
+func (g *LoginGuard) blocked(count int, lastFailure, lockedUntil, now time.Time) error {
+	if now.Before(lockedUntil) {
+		return &LoginBlockedError{RetryAfter: lockedUntil.Sub(now), Locked: true}
+	}
+	if count < loginDelayAfter || now.Sub(lastFailure) > g.window {
+		return nil
+	}
+	delay := time.Second << uint(count-loginDelayAfter)
+	if delay > loginMaxDelay || delay <= 0 {
+		delay = loginMaxDelay
+	}
+	if wait := lastFailure.Add(delay).Sub(now); wait > 0 {
+		return &LoginBlockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// fail increments an in-memory counter, starting over when the last failure is outside the window.
+// Must be called with g.mu held.
+func (g *LoginGuard) fail(states map[string]*loginAttempts, key string, limit int, now time.Time) *loginAttempts {
+	state, ok := states[key]
+	if !ok || now.Sub(state.lastFailure) > g.window {
+		state = &loginAttempts{}
+		states[key] = state
+	}
+	state.count++
+	state.lastFailure = now
+	if limit > 0 && state.count >= limit {
+		state.lockedUntil = now.Add(g.lockout)
+	}
+	return state
+}
+
+// failIP counts an attempt against ip. Must be called with g.mu held.
+func (g *LoginGuard) failIP(ip string, now time.Time) {
+	if state := g.fail(g.ips, ip, g.ipLimit, now); !state.lockedUntil.IsZero() && state.count == g.ipLimit {
+		log.Printf("Login guard: blocking IP %s for %s after %d failed attempts", ip, g.lockout, state.count)
+	}
+}
+
+// releaseIP gives back an attempt reserved by failIP.
+func (g *LoginGuard) releaseIP(ip string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	state, ok := g.ips[ip]
+	if !ok || state.count == 0 {
+		return
+	}
+	state.count--
+	if state.count < g.ipLimit {
+		state.lockedUntil = time.Time{}
+	}
+}
+
+// sweep drops stale in-memory entries once the maps grow large. Must be called with g.mu held.
+func (g *LoginGuard) sweep(now time.Time) {
+	for _, states := range []map[string]*loginAttempts{g.ips, g.unknown} {
+		if len(states) <= loginGuardSweepSize {
+			continue
+		}
+		for key, state := range states {
+			if now.Sub(state.lastFailure) > g.window && !now.Before(state.lockedUntil) {
+				delete(states, key)
+			}
+		}
+	}
+}
+
+func (g *LoginGuard) notifyLocked(user *models.User, attempts int) {
+	msg := MailMessage{
+		To:      user.Email,
+		Subject: "Your Aurify account was temporarily locked",
+		Body: fmt.Sprintf(`Hi %s,
+
+We noticed %d failed sign-in attempts on your Aurify account, so we locked it for %s.
+You can sign in again after %s (UTC).
+
+If this was you, simply wait and try again. If it wasn't, someone may be trying to guess your
+password. We recommend choosing a new one:
+
+%s/forgot-password
+
+Resetting your password also lifts the lock immediately.
+`, user.Username, attempts, formatTTL(g.lockout), user.LockedUntil.UTC().Format("02 Jan 2006 15:04"), g.baseURL),
+	}
+	go func() {
+		if err := g.mailer.Send(msg); err != nil {
+			log.Printf("Login guard: sending lockout notice to user %s failed: %v", user.ID, err)
+		}
+	}()
+}
+
+func emailKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/config"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+)
+
+func newLoginGuardFixture(ipLimit int) (*LoginGuard, *memUserRepo, *models.User, *captureMailer) {
+	user := &models.User{ID: uuid.New(), Username: "listener", Email: "listener@example.com"}
+	users := newMemUserRepo(user)
+	mailer := newCaptureMailer()
+	guard := NewLoginGuard(&repository.Repository{User: users}, mailer, &config.Config{
+		AppBaseURL:               "https://aurify.example",
+		LoginMaxFailedAttempts:   3,
+		LoginFailureWindow:       15 * time.Minute,
+		LoginLockoutDuration:     15 * time.Minute,
+		LoginIPMaxFailedAttempts: ipLimit,
+	})
+	return guard, users, user, mailer
+}
+
+// parallelLogins n eş zamanlı yanlış şifre denemesi yapar ve Begin'i geçebilenleri sayar.
+func parallelLogins(t *testing.T, n int, begin func(i int) (*LoginAttempt, error)) (passed, locked int) {
+	t.Helper()
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		start = make(chan struct{})
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			attempt, err := begin(i)
+			if err != nil {
+				var blocked *LoginBlockedError
+				if !errors.As(err, &blocked) {
+					t.Errorf("Begin error = %v, want *LoginBlockedError", err)
+				}
+				return
+			}
+			// bcrypt karşılaştırmasının süresi
+			time.Sleep(10 * time.Millisecond)
+			wasLocked := attempt.Fail()
+			mu.Lock()
+			passed++
+			if wasLocked {
+				locked++
+			}
+			mu.Unlock()
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return passed, locked
+}
+
+func TestLoginGuardParallelWrongPasswordsLockAccount(t *testing.T) {
+	guard, users, user, mailer := newLoginGuardFixture(100)
+
+	passed, locked := parallelLogins(t, 20, func(i int) (*LoginAttempt, error) {
+		// Her istek kendi kopyasıyla gelir; handler kullanıcıyı GetByEmail ile okur
+		u, _ := users.GetByEmail(user.Email)
+		return guard.Begin(user.Email, u, fmt.Sprintf("10.0.0.%d", i))
+	})
+	if passed != 3 {
+		t.Errorf("%d attempts reached the password check, want 3", passed)
+	}
+	if locked != 1 {
+		t.Errorf("lockout reported %d times, want 1", locked)
+	}
+	if stored := users.user(user.ID); !stored.IsLocked(time.Now()) || stored.FailedLoginCount != 3 {
+		t.Errorf("account = %d failures, locked until %v; want locked after 3", stored.FailedLoginCount, stored.LockedUntil)
+	}
+	select {
+	case <-mailer.sent:
+	case <-time.After(time.Second):
+		t.Error("lockout notice was not sent")
+	}
+}
+
+func TestLoginGuardParallelUnknownEmailsLockIP(t *testing.T) {
+	guard, _, _, _ := newLoginGuardFixture(5)
+
+	passed, _ := parallelLogins(t, 30, func(i int) (*LoginAttempt, error) {
+		return guard.Begin(fmt.Sprintf("nobody%d@example.com", i), nil, "10.0.0.1")
+	})
+	if passed != 5 {
+		t.Errorf("%d attempts passed from one IP, want 5", passed)
+	}
+	_, err := guard.Begin("another@example.com", nil, "10.0.0.1")
+	var blocked *LoginBlockedError
+	if !errors.As(err, &blocked) || !blocked.Locked {
+		t.Errorf("Begin error = %v, want IP lockout", err)
+	}
+}
+
+func TestLoginGuardSucceedReleasesReservation(t *testing.T) {
+	guard, users, user, _ := newLoginGuardFixture(3)
+
+	for i := 0; i < 5; i++ {
+		u, _ := users.GetByEmail(user.Email)
+		attempt, err := guard.Begin(user.Email, u, "10.0.0.1")
+		if err != nil {
+			t.Fatalf("Begin #%d: %v", i+1, err)
+		}
+		attempt.Succeed()
+	}
+	if stored := users.user(user.ID); stored.FailedLoginCount != 0 || stored.LockedUntil != nil {
+		t.Errorf("account = %d failures, locked until %v after successful logins", stored.FailedLoginCount, stored.LockedUntil)
+	}
+}
+
+func TestLoginGuardDelaysAfterFailures(t *testing.T) {
+	guard, users, user, _ := newLoginGuardFixture(100)
+	guard.maxAttempts = 10
+
+	for i := 0; i < loginDelayAfter; i++ {
+		u, _ := users.GetByEmail(user.Email)
+		attempt, err := guard.Begin(user.Email, u, "10.0.0.1")
+		if err != nil {
+			t.Fatalf("Begin #%d: %v", i+1, err)
+		}
+		attempt.Fail()
+	}
+	u, _ := users.GetByEmail(user.Email)
+	_, err := guard.Begin(user.Email, u, "10.0.0.1")
+	var blocked *LoginBlockedError
+	if !errors.As(err, &blocked) || blocked.Locked || blocked.RetryAfter <= 0 || blocked.RetryAfter > time.Second {
+		t.Fatalf("Begin error = %v, want a short progressive delay", err)
+	}
+	if stored := users.user(user.ID); stored.FailedLoginCount != loginDelayAfter {
+		t.Errorf("rejected attempt was counted: %d failures", stored.FailedLoginCount)
+	}
+}
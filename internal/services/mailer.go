@@ -0,0 +1,177 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/morgarakt/aurify/internal/config"
+)
+
+// smtpTimeout bounds the whole SMTP conversation so a stuck server cannot pile up senders.
+const smtpTimeout = 15 * time.Second
+
+// MailMessage is a plain-text email.
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends transactional email. Implementations are selected with MAIL_DRIVER.
+type Mailer interface {
+	Send(msg MailMessage) error
+}
+
+// NewMailer returns the mailer configured by MAIL_DRIVER (smtp, file or log).
+func NewMailer(cfg *config.Config) (Mailer, error) {
+	from, err := mail.ParseAddress(cfg.MailFrom)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAIL_FROM %q: %w", cfg.MailFrom, err)
+	}
+	switch cfg.MailDriver {
+	case "smtp":
+		return &SMTPMailer{
+			addr:     net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort),
+			host:     cfg.SMTPHost,
+			username: cfg.SMTPUsername,
+			password: cfg.SMTPPassword,
+			from:     from,
+		}, nil
+	case "file":
+		if err := os.MkdirAll(cfg.MailDir, 0o755); err != nil {
+			return nil, fmt.Errorf("creating mail dir %s: %w", cfg.MailDir, err)
+		}
+		return &FileMailer{dir: cfg.MailDir, from: from}, nil
+	default:
+		return &LogMailer{from: from}, nil
+	}
+}
+
+// SMTPMailer delivers mail through an SMTP server. STARTTLS is used when the server offers it;
+// credentials are only sent when SMTP_USERNAME is set, so a local sink (MailHog, Mailpit) works without auth.
+type SMTPMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     *mail.Address
+}
+
+func (m *SMTPMailer) Send(msg MailMessage) error {
+	data, err := buildMessage(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", m.addr, smtpTimeout)
+	if err != nil {
+		return fmt.Errorf("smtp dial %s: %w", m.addr, err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(smtpTimeout))
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := client.Mail(m.from.Address); err != nil {
+		return fmt.Errorf("smtp MAIL FROM: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("smtp RCPT TO: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("smtp write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp DATA close: %w", err)
+	}
+	return client.Quit()
+}
+
+// FileMailer writes each message as an .eml file into dir. Useful in development and for inspecting mail by hand.
+type FileMailer struct {
+	dir  string
+	from *mail.Address
+}
+
+func (m *FileMailer) Send(msg MailMessage) error {
+	data, err := buildMessage(m.from, msg)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), randomHex(4))
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing mail file %s: %w", path, err)
+	}
+	log.Printf("Mailer: wrote %q for %s to %s", msg.Subject, msg.To, path)
+	return nil
+}
+
+// LogMailer only logs the message. It is the default so development setups need no mail server.
+type LogMailer struct {
+	from *mail.Address
+}
+
+func (m *LogMailer) Send(msg MailMessage) error {
+	log.Printf("Mailer (log): from=%s to=%s subject=%q\n%s", m.from.Address, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// buildMessage renders the RFC 5322 message with UTF-8 plain-text body.
+func buildMessage(from *mail.Address, msg MailMessage) ([]byte, error) {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(from.Address, "@"); at >= 0 {
+		domain = from.Address[at+1:]
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", randomHex(16), domain)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return buf.Bytes(), nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
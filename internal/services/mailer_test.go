@@ -0,0 +1,176 @@
+package services
+
+import (
+	"bufio"
+	"encoding/base64"
+	"mime"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+// smtpSink is a minimal in-process SMTP server that records one conversation.
+type smtpSink struct {
+	addr       string
+	advertise  []string // extra EHLO extensions, e.g. "AUTH PLAIN"
+	rejectRcpt bool
+
+	done     chan struct{}
+	auth     string
+	mailFrom string
+	rcptTo   []string
+	data     string
+}
+
+func startSMTPSink(t *testing.T, rejectRcpt bool, advertise ...string) *smtpSink {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	sink := &smtpSink{addr: ln.Addr().String(), advertise: advertise, rejectRcpt: rejectRcpt, done: make(chan struct{})}
+	go func() {
+		defer close(sink.done)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sink.serve(conn)
+	}()
+	return sink
+}
+
+func (s *smtpSink) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 sink ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			for _, ext := range s.advertise {
+				reply("250-" + ext)
+			}
+			reply("250 8BITMIME")
+		case "AUTH":
+			s.auth = line
+			reply("235 ok")
+		case "MAIL":
+			s.mailFrom = line
+			reply("250 ok")
+		case "RCPT":
+			if s.rejectRcpt {
+				reply("550 no such user")
+				continue
+			}
+			s.rcptTo = append(s.rcptTo, line)
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var body strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				body.WriteString(l)
+			}
+			s.data = body.String()
+			reply("250 queued")
+		case "RSET", "NOOP":
+			reply("250 ok")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func newTestSMTPMailer(t *testing.T, addr, username, password string) *SMTPMailer {
+	t.Helper()
+	from, err := mail.ParseAddress("Aurify <no-reply@aurify.test>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// PlainAuth yalnızca TLS ya da localhost üzerinde kimlik bilgisi gönderir
+	return &SMTPMailer{addr: addr, host: "localhost", username: username, password: password, from: from}
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	sink := startSMTPSink(t, false)
+	m := newTestSMTPMailer(t, sink.addr, "", "")
+
+	err := m.Send(MailMessage{To: "listener@example.com", Subject: "Şifre sıfırlama", Body: "line one\nline two"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	<-sink.done
+
+	if sink.auth != "" {
+		t.Errorf("AUTH sent without SMTP_USERNAME: %q", sink.auth)
+	}
+	if sink.mailFrom != "MAIL FROM:<no-reply@aurify.test> BODY=8BITMIME" && sink.mailFrom != "MAIL FROM:<no-reply@aurify.test>" {
+		t.Errorf("MAIL FROM = %q", sink.mailFrom)
+	}
+	if len(sink.rcptTo) != 1 || sink.rcptTo[0] != "RCPT TO:<listener@example.com>" {
+		t.Errorf("RCPT TO = %q", sink.rcptTo)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(sink.data))
+	if err != nil {
+		t.Fatalf("parsing delivered message: %v", err)
+	}
+	if got, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); got != "Şifre sıfırlama" {
+		t.Errorf("Subject = %q", got)
+	}
+	if got := msg.Header.Get("Content-Type"); got != "text/plain; charset=UTF-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if !strings.Contains(sink.data, "line one\r\nline two") {
+		t.Errorf("body line endings not normalized to CRLF: %q", sink.data)
+	}
+}
+
+func TestSMTPMailerAuth(t *testing.T) {
+	sink := startSMTPSink(t, false, "AUTH PLAIN")
+	m := newTestSMTPMailer(t, sink.addr, "mailer", "s3cret")
+
+	if err := m.Send(MailMessage{To: "listener@example.com", Subject: "hi", Body: "hi"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	<-sink.done
+
+	want := "AUTH PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00mailer\x00s3cret"))
+	if sink.auth != want {
+		t.Errorf("AUTH = %q, want %q", sink.auth, want)
+	}
+}
+
+func TestSMTPMailerRejectedRecipient(t *testing.T) {
+	sink := startSMTPSink(t, true)
+	m := newTestSMTPMailer(t, sink.addr, "", "")
+
+	err := m.Send(MailMessage{To: "nobody@example.com", Subject: "hi", Body: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "RCPT TO") {
+		t.Fatalf("Send error = %v, want RCPT TO failure", err)
+	}
+}
+
+func TestSMTPMailerInvalidRecipient(t *testing.T) {
+	m := newTestSMTPMailer(t, "127.0.0.1:1", "", "")
+	if err := m.Send(MailMessage{To: "not an address", Subject: "hi", Body: "hi"}); err == nil {
+		t.Fatal("Send accepted an invalid recipient")
+	}
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+)
+
+const purgeBatchSize = 100
+
+// MusicPurger permanently removes soft-deleted tracks once their restore window has passed.
+type MusicPurger struct {
+	repo      *repository.Repository
+	storage   *LocalStorage
+	retention time.Duration
+}
+
+func NewMusicPurger(repo *repository.Repository, storage *LocalStorage, retention time.Duration) *MusicPurger {
+	return &MusicPurger{repo: repo, storage: storage, retention: retention}
+}
+
+// Retention is how long a deleted track stays restorable.
+func (p *MusicPurger) Retention() time.Duration {
+	return p.retention
+}
+
+// RestorableSince returns the oldest deletion time that can still be restored.
+func (p *MusicPurger) RestorableSince() time.Time {
+	return time.Now().Add(-p.retention)
+}
+
+// PurgeMusic deletes the row, its likes and asset rows, then the files it referenced.
+// Files are removed after the database commit; anything left behind is picked up by the artifact GC.
+func (p *MusicPurger) PurgeMusic(music *models.Music) error {
+	keys := make([]string, 0, 8)
+	for _, u := range []string{music.Mp3FilePath, music.MidiFilePath, music.CoverArtPath} {
+		if key, ok := p.storage.KeyFromURL(u); ok {
+			keys = append(keys, key)
+		}
+	}
+	assets, err := p.repo.Asset.GetForMusic(music.ID, models.AssetKindWaveform)
+	if err != nil {
+		return fmt.Errorf("listing assets of music %s: %w", music.ID, err)
+	}
+	for _, a := range assets {
+		keys = append(keys, a.StorageKey)
+	}
+
+	if err := p.repo.Music.HardDelete(music.ID); err != nil {
+		return fmt.Errorf("deleting music %s: %w", music.ID, err)
+	}
+	for _, key := range keys {
+		if err := p.storage.Remove(key); err != nil {
+			log.Printf("Music purge: failed to remove %s of music %s: %v", key, music.ID, err)
+		}
+	}
+	return nil
+}
+
+// Run purges every track deleted before the retention window, in batches. A track that fails to purge
+// is logged and skipped for the rest of the run so it cannot block the tracks behind it; the failures
+// are returned together at the end.
+func (p *MusicPurger) Run(ctx context.Context) error {
+	cutoff := p.RestorableSince()
+	purged := 0
+	failed := make(map[uuid.UUID]bool)
+	var errs []error
+	for {
+		// Başarısız satırlar listenin başında kalmaya devam eder; limit onlar kadar büyütülür ki
+		// her turda yeni satırlara da sıra gelsin.
+		limit := purgeBatchSize + len(failed)
+		batch, err := p.repo.Music.ListDeletedBefore(cutoff, limit)
+		if err != nil {
+			return errors.Join(append(errs, fmt.Errorf("listing expired deleted music: %w", err))...)
+		}
+		attempted := 0
+		for i := range batch {
+			if err := ctx.Err(); err != nil {
+				return errors.Join(append(errs, err)...)
+			}
+			if failed[batch[i].ID] {
+				continue
+			}
+			attempted++
+			if err := p.PurgeMusic(&batch[i]); err != nil {
+				log.Printf("Music purge: skipping music %s for this run: %v", batch[i].ID, err)
+				failed[batch[i].ID] = true
+				errs = append(errs, err)
+				continue
+			}
+			purged++
+			log.Printf("Music purge: permanently deleted music %s (deleted at %s)", batch[i].ID, batch[i].DeletedAt.Time.Format(time.RFC3339))
+		}
+		if attempted == 0 || len(batch) < limit {
+			break
+		}
+	}
+	if purged > 0 || len(failed) > 0 {
+		log.Printf("Music purge finished: %d track(s) permanently deleted, %d failed.", purged, len(failed))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d track(s) could not be purged: %w", len(failed), errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,379 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	testOIDCClientID = "aurify-client"
+	testOIDCNonce    = "nonce-123"
+)
+
+var (
+	testRSAKeyOnce sync.Once
+	testRSAKey     *rsa.PrivateKey
+)
+
+// rsaTestKey her testte yeni 2048 bit anahtar üretmemek için paylaşılır.
+func rsaTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	testRSAKeyOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(err)
+		}
+		testRSAKey = key
+	})
+	return testRSAKey
+}
+
+// fakeIdP discovery, token ve JWKS endpoint'lerini sunan test kimlik sağlayıcısıdır.
+type fakeIdP struct {
+	server *httptest.Server
+
+	mu              sync.Mutex
+	discoveryIssuer string // boşsa sunucunun kendi adresi
+	keys            []jsonWebKey
+	jwksFetches     int
+	idToken         string
+	tokenForm       url.Values
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	t.Helper()
+	idp := &fakeIdP{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		idp.mu.Lock()
+		issuer := idp.discoveryIssuer
+		idp.mu.Unlock()
+		if issuer == "" {
+			issuer = idp.server.URL
+		}
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                issuer,
+			AuthorizationEndpoint: idp.server.URL + "/authorize",
+			TokenEndpoint:         idp.server.URL + "/token",
+			JWKSURI:               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+		idp.jwksFetches++
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": idp.keys})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+		idp.tokenForm = r.PostForm
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idp.idToken})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *fakeIdP) setKeys(keys ...jsonWebKey) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.keys = keys
+}
+
+func (idp *fakeIdP) fetches() int {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	return idp.jwksFetches
+}
+
+func (idp *fakeIdP) provider() *OIDCProvider {
+	return NewOIDCProvider(idp.server.URL, testOIDCClientID, "", "https://aurify.test/auth/oidc/callback", []string{"openid", "email"})
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) jsonWebKey {
+	return jsonWebKey{
+		Kid: kid,
+		Kty: "RSA",
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) jsonWebKey {
+	size := (key.Curve.Params().BitSize + 7) / 8
+	return jsonWebKey{
+		Kid: kid,
+		Kty: "EC",
+		Crv: key.Curve.Params().Name,
+		X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+	}
+}
+
+// validClaims geçerli bir ID token'ın claim'lerini döner; testler tek bir alanı bozar.
+func (idp *fakeIdP) validClaims() *idTokenClaims {
+	now := time.Now()
+	return &idTokenClaims{
+		Nonce:         testOIDCNonce,
+		Email:         "listener@example.com",
+		EmailVerified: json.RawMessage(`true`),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    idp.server.URL,
+			Subject:   "subject-1",
+			Audience:  jwt.ClaimStrings{testOIDCClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+	}
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, claims jwt.Claims, key interface{}) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("signing test token: %v", err)
+	}
+	return signed
+}
+
+func TestOIDCDiscoveryIssuerMismatch(t *testing.T) {
+	idp := newFakeIdP(t)
+	idp.discoveryIssuer = "https://evil.example"
+
+	_, err := idp.provider().AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	if err == nil || !strings.Contains(err.Error(), "issuer mismatch") {
+		t.Fatalf("AuthCodeURL error = %v, want issuer mismatch", err)
+	}
+}
+
+func TestOIDCDiscoveryAcceptsTrailingSlash(t *testing.T) {
+	idp := newFakeIdP(t)
+	idp.discoveryIssuer = idp.server.URL + "/"
+	p := NewOIDCProvider(idp.server.URL+"/", testOIDCClientID, "", "https://aurify.test/cb", []string{"openid"})
+
+	if _, err := p.AuthCodeURL(context.Background(), "state", "nonce", "verifier"); err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+}
+
+func TestOIDCAuthCodeURLUsesS256PKCE(t *testing.T) {
+	idp := newFakeIdP(t)
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	raw, err := idp.provider().AuthCodeURL(context.Background(), "state-1", testOIDCNonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != idp.server.URL+"/authorize" {
+		t.Errorf("endpoint = %q", got)
+	}
+	q := u.Query()
+	sum := sha256.Sum256([]byte(verifier))
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testOIDCClientID,
+		"state":                 "state-1",
+		"nonce":                 testOIDCNonce,
+		"scope":                 "openid email",
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(sum[:]),
+		"code_challenge_method": "S256",
+	}
+	for key, value := range want {
+		if q.Get(key) != value {
+			t.Errorf("%s = %q, want %q", key, q.Get(key), value)
+		}
+	}
+	// RFC 7636 Appendix B örnek değeri
+	if q.Get("code_challenge") != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" {
+		t.Errorf("code_challenge = %q does not match the RFC 7636 example", q.Get("code_challenge"))
+	}
+	if q.Has("code_verifier") {
+		t.Error("code_verifier leaked into the authorization URL")
+	}
+}
+
+func TestOIDCExchange(t *testing.T) {
+	idp := newFakeIdP(t)
+	key := rsaTestKey(t)
+	idp.setKeys(rsaJWK("k1", &key.PublicKey))
+	idp.idToken = signToken(t, jwt.SigningMethodRS256, "k1", idp.validClaims(), key)
+
+	claims, err := idp.provider().Exchange(context.Background(), "code-1", "verifier-1", testOIDCNonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if claims.Subject != "subject-1" || claims.Email != "listener@example.com" || !claims.EmailVerified {
+		t.Errorf("claims = %+v", claims)
+	}
+	if idp.tokenForm.Get("code") != "code-1" || idp.tokenForm.Get("code_verifier") != "verifier-1" {
+		t.Errorf("token request form = %v", idp.tokenForm)
+	}
+}
+
+func TestOIDCExchangeNonceMismatch(t *testing.T) {
+	idp := newFakeIdP(t)
+	key := rsaTestKey(t)
+	idp.setKeys(rsaJWK("k1", &key.PublicKey))
+	idp.idToken = signToken(t, jwt.SigningMethodRS256, "k1", idp.validClaims(), key)
+
+	_, err := idp.provider().Exchange(context.Background(), "code-1", "verifier-1", "another-nonce")
+	if !errors.Is(err, ErrIDTokenInvalid) {
+		t.Fatalf("Exchange error = %v, want ErrIDTokenInvalid", err)
+	}
+}
+
+func TestOIDCVerifyIDTokenRejects(t *testing.T) {
+	idp := newFakeIdP(t)
+	key := rsaTestKey(t)
+	idp.setKeys(rsaJWK("k1", &key.PublicKey))
+
+	rs256 := func(mutate func(c *idTokenClaims)) func(t *testing.T) string {
+		return func(t *testing.T) string {
+			claims := idp.validClaims()
+			mutate(claims)
+			return signToken(t, jwt.SigningMethodRS256, "k1", claims, key)
+		}
+	}
+	cases := []struct {
+		name  string
+		token func(t *testing.T) string
+	}{
+		{"wrong issuer", rs256(func(c *idTokenClaims) { c.Issuer = "https://evil.example" })},
+		{"wrong audience", rs256(func(c *idTokenClaims) { c.Audience = jwt.ClaimStrings{"someone-else"} })},
+		{"extra audience without azp", rs256(func(c *idTokenClaims) {
+			c.Audience = jwt.ClaimStrings{testOIDCClientID, "someone-else"}
+		})},
+		{"extra audience with foreign azp", rs256(func(c *idTokenClaims) {
+			c.Audience = jwt.ClaimStrings{testOIDCClientID, "someone-else"}
+			c.AuthorizedParty = "someone-else"
+		})},
+		{"expired", rs256(func(c *idTokenClaims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) })},
+		{"missing exp", rs256(func(c *idTokenClaims) { c.ExpiresAt = nil })},
+		{"missing sub", rs256(func(c *idTokenClaims) { c.Subject = "" })},
+		{"nonce mismatch", rs256(func(c *idTokenClaims) { c.Nonce = "replayed" })},
+		{"alg none", func(t *testing.T) string {
+			return signToken(t, jwt.SigningMethodNone, "k1", idp.validClaims(), jwt.UnsafeAllowNoneSignatureType)
+		}},
+		{"HS256 with public key as secret", func(t *testing.T) string {
+			return signToken(t, jwt.SigningMethodHS256, "k1", idp.validClaims(), key.PublicKey.N.Bytes())
+		}},
+		{"signed by another key", func(t *testing.T) string {
+			other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return signToken(t, jwt.SigningMethodES256, "k1", idp.validClaims(), other)
+		}},
+	}
+	p := idp.provider()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := p.VerifyIDToken(context.Background(), tc.token(t), testOIDCNonce)
+			if !errors.Is(err, ErrIDTokenInvalid) {
+				t.Fatalf("VerifyIDToken error = %v, want ErrIDTokenInvalid", err)
+			}
+		})
+	}
+
+	// Aynı sağlayıcı geçerli token'ı kabul etmeli; yukarıdaki retler anahtar sorunu değil
+	if _, err := p.VerifyIDToken(context.Background(), rs256(func(*idTokenClaims) {})(t), testOIDCNonce); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+}
+
+func TestOIDCVerifyIDTokenAcceptsAzpForMultipleAudiences(t *testing.T) {
+	idp := newFakeIdP(t)
+	key := rsaTestKey(t)
+	idp.setKeys(rsaJWK("k1", &key.PublicKey))
+	claims := idp.validClaims()
+	claims.Audience = jwt.ClaimStrings{testOIDCClientID, "someone-else"}
+	claims.AuthorizedParty = testOIDCClientID
+
+	if _, err := idp.provider().VerifyIDToken(context.Background(), signToken(t, jwt.SigningMethodRS256, "k1", claims, key), testOIDCNonce); err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+}
+
+func TestOIDCUnknownKidRefreshesJWKS(t *testing.T) {
+	idp := newFakeIdP(t)
+	rsaKey := rsaTestKey(t)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp.setKeys(rsaJWK("old", &rsaKey.PublicKey))
+	p := idp.provider()
+	ctx := context.Background()
+
+	if _, err := p.VerifyIDToken(ctx, signToken(t, jwt.SigningMethodRS256, "old", idp.validClaims(), rsaKey), testOIDCNonce); err != nil {
+		t.Fatalf("VerifyIDToken(old): %v", err)
+	}
+	if _, err := p.VerifyIDToken(ctx, signToken(t, jwt.SigningMethodRS256, "old", idp.validClaims(), rsaKey), testOIDCNonce); err != nil {
+		t.Fatalf("VerifyIDToken(old, cached): %v", err)
+	}
+	if n := idp.fetches(); n != 1 {
+		t.Fatalf("JWKS fetched %d times for a known kid, want 1", n)
+	}
+
+	// Sağlayıcı anahtarını döndürdü
+	idp.setKeys(rsaJWK("old", &rsaKey.PublicKey), ecJWK("new", &ecKey.PublicKey))
+	rotated := signToken(t, jwt.SigningMethodES256, "new", idp.validClaims(), ecKey)
+
+	// Son çekimden bu yana oidcJWKSRefreshMin geçmediyse sağlayıcıya tekrar gidilmez
+	if _, err := p.VerifyIDToken(ctx, rotated, testOIDCNonce); !errors.Is(err, ErrIDTokenInvalid) {
+		t.Fatalf("VerifyIDToken(new) within refresh interval = %v, want ErrIDTokenInvalid", err)
+	}
+	if n := idp.fetches(); n != 1 {
+		t.Fatalf("JWKS refetched within the refresh interval (%d fetches)", n)
+	}
+
+	p.mu.Lock()
+	p.keysFetchedAt = time.Now().Add(-oidcJWKSRefreshMin - time.Second)
+	p.mu.Unlock()
+	if _, err := p.VerifyIDToken(ctx, rotated, testOIDCNonce); err != nil {
+		t.Fatalf("VerifyIDToken(new) after refresh: %v", err)
+	}
+	if n := idp.fetches(); n != 2 {
+		t.Fatalf("JWKS fetched %d times, want 2", n)
+	}
+}
+
+func TestParseEmailVerified(t *testing.T) {
+	cases := map[string]bool{`true`: true, `false`: false, `"true"`: true, `"TRUE"`: true, `"false"`: false, `1`: false, ``: false}
+	for raw, want := range cases {
+		if got := parseEmailVerified(json.RawMessage(raw)); got != want {
+			t.Errorf("parseEmailVerified(%s) = %v, want %v", raw, got, want)
+		}
+	}
+}
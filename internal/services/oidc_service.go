@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/config"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const oidcUsernameMaxLength = 32
+
+var (
+	ErrOIDCEmailMissing      = errors.New("identity provider did not return an email address")
+	ErrOIDCEmailUnverified   = errors.New("identity provider email is not verified")
+	ErrOIDCAccountUnverified = errors.New("existing account email is not verified")
+)
+
+// How ResolveUser matched the identity to a local account.
+const (
+	OIDCUserExisting = "existing"
+	OIDCUserLinked   = "linked"
+	OIDCUserCreated  = "created"
+)
+
+// OIDCService signs users in through an OpenID Connect provider and maps provider accounts
+// (issuer + subject) to local users, linking or creating accounts on first sign-in.
+type OIDCService struct {
+	repo         *repository.Repository
+	provider     *OIDCProvider
+	providerName string
+	adminEmails  func(email string) bool
+}
+
+// NewOIDCService returns nil when OIDC is not configured.
+func NewOIDCService(repo *repository.Repository, cfg *config.Config) *OIDCService {
+	if !cfg.OIDCEnabled() {
+		return nil
+	}
+	return &OIDCService{
+		repo:         repo,
+		provider:     NewOIDCProvider(cfg.OIDCIssuer, cfg.OIDCClientID, cfg.OIDCClientSecret, cfg.OIDCRedirectURL, cfg.OIDCScopes),
+		providerName: cfg.OIDCProviderName,
+		adminEmails:  cfg.IsAdminEmail,
+	}
+}
+
+// ProviderName is the label shown on the sign-in button.
+func (s *OIDCService) ProviderName() string {
+	return s.providerName
+}
+
+// Issuer identifies the configured provider in identity records and audit logs.
+func (s *OIDCService) Issuer() string {
+	return s.provider.Issuer()
+}
+
+// AuthCodeURL builds the provider URL the browser is redirected to.
+func (s *OIDCService) AuthCodeURL(ctx context.Context, state *utils.OIDCLoginState) (string, error) {
+	return s.provider.AuthCodeURL(ctx, state.State, state.Nonce, state.CodeVerifier)
+}
+
+// Exchange redeems the authorization code and returns the verified ID token claims.
+func (s *OIDCService) Exchange(ctx context.Context, code string, state *utils.OIDCLoginState) (*OIDCClaims, error) {
+	return s.provider.Exchange(ctx, code, state.CodeVerifier, state.Nonce)
+}
+
+// ResolveUser finds the local user for the provider account. An unknown account is linked to the
+// user with the same email only when both the provider and the local account have verified that
+// address; otherwise someone could take over an account by registering its email elsewhere.
+// If no user has the email, a new account is created.
+func (s *OIDCService) ResolveUser(claims *OIDCClaims) (*models.User, string, error) {
+	now := time.Now()
+	identity, err := s.repo.Identity.FindBySubject(claims.Issuer, claims.Subject)
+	if err == nil {
+		var user models.User
+		if err := s.repo.User.GetByID(identity.UserID, &user); err != nil {
+			return nil, "", err
+		}
+		if err := s.repo.Identity.TouchLogin(identity.ID, claims.Email, now); err != nil {
+			return nil, "", err
+		}
+		return &user, OIDCUserExisting, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", err
+	}
+
+	if claims.Email == "" {
+		return nil, "", ErrOIDCEmailMissing
+	}
+	user, err := s.repo.User.GetByEmail(claims.Email)
+	switch {
+	case err == nil:
+		if !claims.EmailVerified {
+			return nil, "", ErrOIDCEmailUnverified
+		}
+		if !user.IsEmailVerified() {
+			return nil, "", ErrOIDCAccountUnverified
+		}
+		if err := s.createIdentity(user.ID, claims, now); err != nil {
+			return nil, "", err
+		}
+		return user, OIDCUserLinked, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		user, err := s.createUser(claims, now)
+		if err != nil {
+			return nil, "", err
+		}
+		return user, OIDCUserCreated, nil
+	default:
+		return nil, "", err
+	}
+}
+
+// createUser creates an account for a first-time provider sign-in. The account gets a random
+// password nobody knows; the user can set one later through the password reset flow.
+func (s *OIDCService) createUser(claims *OIDCClaims, now time.Time) (*models.User, error) {
+	username, err := s.availableUsername(claims)
+	if err != nil {
+		return nil, err
+	}
+	password, _, err := utils.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		ID:           uuid.New(),
+		Username:     username,
+		Email:        claims.Email,
+		PasswordHash: string(passwordHash),
+		Role:         models.RoleUser,
+	}
+	if claims.EmailVerified {
+		user.EmailVerifiedAt = &now
+		// Doğrulanmamış bir adresle admin yetkisi alınamasın
+		if s.adminEmails(user.Email) {
+			user.Role = models.RoleAdmin
+		}
+	}
+	if err := s.repo.User.Create(user); err != nil {
+		return nil, err
+	}
+	if err := s.createIdentity(user.ID, claims, now); err != nil {
+		// Kimlik bağlanamadıysa yarım kalan hesap bırakılmaz
+		_ = s.repo.User.HardDelete(user.ID)
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *OIDCService) createIdentity(userID uuid.UUID, claims *OIDCClaims, now time.Time) error {
+	return s.repo.Identity.Create(&models.Identity{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Issuer:      claims.Issuer,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: &now,
+		CreatedAt:   now,
+	})
+}
+
+// availableUsername derives a username from the provider profile and adds a random suffix when it is taken.
+func (s *OIDCService) availableUsername(claims *OIDCClaims) (string, error) {
+	base := sanitizeUsername(claims.PreferredUsername)
+	if base == "" {
+		base = sanitizeUsername(claims.Name)
+	}
+	if base == "" {
+		base = sanitizeUsername(strings.SplitN(claims.Email, "@", 2)[0])
+	}
+	// Ayrılmış ya da kurallara uymayan adlar (örn. tek harf) yerine genel bir ad kullanılır
+	if ValidateUsername(base) != nil {
+		base = "user"
+	}
+
+	candidate := base
+	for attempt := 0; attempt < 5; attempt++ {
+		exists, err := s.repo.User.UsernameExists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+		candidate = Truncate(base, oidcUsernameMaxLength-5) + "-" + randomHex(2)
+	}
+	return "", fmt.Errorf("could not find a free username for %q", base)
+}
+
+// sanitizeUsername harf, rakam, nokta, tire ve alt çizgi dışındaki karakterleri atar.
+func sanitizeUsername(value string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(value) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune('_')
+		}
+	}
+	return Truncate(strings.Trim(b.String(), "._-"), oidcUsernameMaxLength)
+}
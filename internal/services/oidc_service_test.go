@@ -0,0 +1,161 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+)
+
+const testIssuer = "https://idp.example"
+
+func newTestOIDCService(users ...*models.User) (*OIDCService, *memUserRepo, *memIdentityRepo) {
+	userRepo := newMemUserRepo(users...)
+	identities := &memIdentityRepo{}
+	svc := &OIDCService{
+		repo:        &repository.Repository{User: userRepo, Identity: identities},
+		adminEmails: func(email string) bool { return email == "boss@example.com" },
+	}
+	return svc, userRepo, identities
+}
+
+func localUser(email string, verified bool) *models.User {
+	user := &models.User{ID: uuid.New(), Username: "local", Email: email, Role: models.RoleUser}
+	if verified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	return user
+}
+
+func TestResolveUserExistingIdentity(t *testing.T) {
+	user := localUser("listener@example.com", false)
+	svc, _, identities := newTestOIDCService(user)
+	identities.Create(&models.Identity{ID: uuid.New(), UserID: user.ID, Issuer: testIssuer, Subject: "sub-1"})
+
+	// Bağlı kimlik, e-posta doğrulama durumundan bağımsız olarak aynı kullanıcıya gider
+	got, how, err := svc.ResolveUser(&OIDCClaims{Issuer: testIssuer, Subject: "sub-1", Email: "changed@example.com"})
+	if err != nil {
+		t.Fatalf("ResolveUser: %v", err)
+	}
+	if how != OIDCUserExisting || got.ID != user.ID {
+		t.Errorf("ResolveUser = %s %s, want existing %s", how, got.ID, user.ID)
+	}
+	if identities.identities[0].LastLoginAt == nil || identities.identities[0].Email != "changed@example.com" {
+		t.Error("identity login was not recorded")
+	}
+}
+
+func TestResolveUserLinksVerifiedAccounts(t *testing.T) {
+	user := localUser("listener@example.com", true)
+	svc, _, identities := newTestOIDCService(user)
+
+	got, how, err := svc.ResolveUser(&OIDCClaims{Issuer: testIssuer, Subject: "sub-1", Email: "Listener@example.com", EmailVerified: true})
+	if err != nil {
+		t.Fatalf("ResolveUser: %v", err)
+	}
+	if how != OIDCUserLinked || got.ID != user.ID {
+		t.Errorf("ResolveUser = %s %s, want linked %s", how, got.ID, user.ID)
+	}
+	if len(identities.identities) != 1 || identities.identities[0].UserID != user.ID {
+		t.Errorf("identities = %+v", identities.identities)
+	}
+}
+
+func TestResolveUserLinksEmailCaseInsensitively(t *testing.T) {
+	user := localUser("alice@example.com", true)
+	svc, users, identities := newTestOIDCService(user)
+
+	got, how, err := svc.ResolveUser(&OIDCClaims{Issuer: testIssuer, Subject: "sub-1", Email: "Alice@Example.COM", EmailVerified: true})
+	if err != nil {
+		t.Fatalf("ResolveUser: %v", err)
+	}
+	if how != OIDCUserLinked || got.ID != user.ID {
+		t.Errorf("ResolveUser = %s %s, want linked %s", how, got.ID, user.ID)
+	}
+	if len(users.users) != 1 || len(identities.identities) != 1 {
+		t.Errorf("duplicate account created for a differently cased email")
+	}
+}
+
+func TestResolveUserDoesNotLinkUnverified(t *testing.T) {
+	cases := []struct {
+		name          string
+		localVerified bool
+		idpVerified   bool
+		want          error
+	}{
+		{"provider email unverified", true, false, ErrOIDCEmailUnverified},
+		{"local account unverified", false, true, ErrOIDCAccountUnverified},
+		{"both unverified", false, false, ErrOIDCEmailUnverified},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			user := localUser("listener@example.com", tc.localVerified)
+			svc, users, identities := newTestOIDCService(user)
+
+			_, _, err := svc.ResolveUser(&OIDCClaims{Issuer: testIssuer, Subject: "sub-1", Email: user.Email, EmailVerified: tc.idpVerified})
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("ResolveUser error = %v, want %v", err, tc.want)
+			}
+			if len(identities.identities) != 0 {
+				t.Errorf("identity linked despite unverified email: %+v", identities.identities)
+			}
+			if len(users.users) != 1 {
+				t.Errorf("a second account was created for the same email")
+			}
+		})
+	}
+}
+
+func TestResolveUserRequiresEmail(t *testing.T) {
+	svc, _, _ := newTestOIDCService()
+	if _, _, err := svc.ResolveUser(&OIDCClaims{Issuer: testIssuer, Subject: "sub-1"}); !errors.Is(err, ErrOIDCEmailMissing) {
+		t.Fatalf("ResolveUser error = %v, want ErrOIDCEmailMissing", err)
+	}
+}
+
+func TestResolveUserCreatesAccount(t *testing.T) {
+	taken := localUser("other@example.com", true)
+	taken.Username = "listener"
+	svc, users, identities := newTestOIDCService(taken)
+
+	got, how, err := svc.ResolveUser(&OIDCClaims{
+		Issuer: testIssuer, Subject: "sub-1", Email: "listener@example.com", EmailVerified: true, PreferredUsername: "listener",
+	})
+	if err != nil {
+		t.Fatalf("ResolveUser: %v", err)
+	}
+	if how != OIDCUserCreated {
+		t.Fatalf("ResolveUser = %s, want created", how)
+	}
+	if got.Username == "listener" || len(got.Username) <= len("listener") {
+		t.Errorf("taken username reused: %q", got.Username)
+	}
+	if !got.IsEmailVerified() || got.Role != models.RoleUser {
+		t.Errorf("created user = verified %v role %s", got.IsEmailVerified(), got.Role)
+	}
+	if _, ok := users.users[got.ID]; !ok || len(identities.identities) != 1 {
+		t.Error("user or identity was not stored")
+	}
+}
+
+func TestResolveUserAdminEmailNeedsVerification(t *testing.T) {
+	for _, verified := range []bool{false, true} {
+		svc, _, _ := newTestOIDCService()
+		got, _, err := svc.ResolveUser(&OIDCClaims{Issuer: testIssuer, Subject: "sub-1", Email: "boss@example.com", EmailVerified: verified})
+		if err != nil {
+			t.Fatalf("ResolveUser: %v", err)
+		}
+		want := models.RoleUser
+		if verified {
+			want = models.RoleAdmin
+		}
+		if got.Role != want {
+			t.Errorf("email_verified=%v: role = %s, want %s", verified, got.Role, want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrResetRateLimited  = errors.New("too many password reset requests")
+	ErrResetTokenInvalid = errors.New("password reset link is invalid or has expired")
+)
+
+// PasswordResetService issues single-use reset links by email and applies the new password.
+type PasswordResetService struct {
+	repo     *repository.Repository
+	sessions *SessionService
+	mailer   Mailer
+	baseURL  string
+	ttl      time.Duration
+	limiter  *RateLimiter
+}
+
+// NewPasswordResetService allows rateLimit requests per email address per hour.
+func NewPasswordResetService(repo *repository.Repository, sessions *SessionService, mailer Mailer, baseURL string, ttl time.Duration, rateLimit int) *PasswordResetService {
+	return &PasswordResetService{
+		repo:     repo,
+		sessions: sessions,
+		mailer:   mailer,
+		baseURL:  baseURL,
+		ttl:      ttl,
+		limiter:  NewRateLimiter(rateLimit, time.Hour),
+	}
+}
+
+// RequestReset emails a reset link if an account with this address exists.
+// It returns the user the link was sent to, or nil when there is no (active) account; callers must not
+// reveal the difference to the client. The limit applies to unknown addresses too, so it leaks nothing either.
+func (s *PasswordResetService) RequestReset(email, ip string) (*models.User, error) {
+	email = strings.TrimSpace(email)
+	if !s.limiter.Allow(strings.ToLower(email)) {
+		return nil, ErrResetRateLimited
+	}
+
+	user, err := s.repo.User.GetByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Password reset requested for unknown email from %s", ip)
+			return nil, nil
+		}
+		return nil, err
+	}
+	if user.IsSuspended() {
+		log.Printf("Password reset requested for suspended user %s; ignoring", user.ID)
+		return nil, nil
+	}
+
+	secret, hash, err := utils.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	token := &models.PasswordResetToken{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		TokenHash:   hash,
+		ExpiresAt:   now.Add(s.ttl),
+		RequestedIP: Truncate(ip, 64),
+		CreatedAt:   now,
+	}
+	if err := s.repo.PasswordReset.Create(token); err != nil {
+		return nil, err
+	}
+
+	msg := MailMessage{
+		To:      user.Email,
+		Subject: "Reset your Aurify password",
+		Body: fmt.Sprintf(`Hi %s,
+
+Someone (hopefully you) asked to reset the password of your Aurify account.
+Open the link below to choose a new password. It can be used once and expires in %s.
+
+%s
+
+If you did not request this, you can ignore this email; your password stays the same.
+`, user.Username, formatTTL(s.ttl), s.resetURL(secret)),
+	}
+	// Gönderim arka planda yapılır: SMTP gecikmesi hesabın var olup olmadığını ele vermesin.
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			log.Printf("Password reset: sending mail to user %s failed: %v", user.ID, err)
+		}
+	}()
+	return user, nil
+}
+
+// ValidateToken reports whether the link can still be used, without consuming it.
+func (s *PasswordResetService) ValidateToken(secret string) error {
+	if secret == "" {
+		return ErrResetTokenInvalid
+	}
+	if _, err := s.repo.PasswordReset.FindValid(utils.HashOpaqueToken(secret), time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrResetTokenInvalid
+		}
+		return err
+	}
+	return nil
+}
+
+// ResetPassword consumes the link, stores the new password, invalidates other pending links and
+// signs the user out everywhere.
+func (s *PasswordResetService) ResetPassword(secret, newPassword string) (*models.User, error) {
+	if secret == "" {
+		return nil, ErrResetTokenInvalid
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	token, err := s.repo.PasswordReset.Consume(utils.HashOpaqueToken(secret), now)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrResetTokenInvalid
+		}
+		return nil, err
+	}
+	var user models.User
+	if err := s.repo.User.GetByID(token.UserID, &user); err != nil {
+		return nil, err
+	}
+	if user.IsSuspended() {
+		return nil, ErrResetTokenInvalid
+	}
+	if err := s.repo.User.UpdatePasswordHash(user.ID, string(hashedPassword)); err != nil {
+		return nil, err
+	}
+	// E-postaya erişimini kanıtlayan kullanıcı hatalı giriş kilidinden de kurtulur
+	if err := s.repo.User.ResetLoginFailures(user.ID); err != nil {
+		log.Printf("Password reset: clearing login lockout of user %s failed: %v", user.ID, err)
+	}
+	if _, err := s.repo.PasswordReset.InvalidateForUser(user.ID, now); err != nil {
+		log.Printf("Password reset: invalidating other links of user %s failed: %v", user.ID, err)
+	}
+	revoked, err := s.sessions.RevokeAllForUser(user.ID, uuid.Nil)
+	if err != nil {
+		log.Printf("Password reset: revoking sessions of user %s failed: %v", user.ID, err)
+	}
+	log.Printf("Password reset completed for user %s, %d session(s) revoked", user.ID, revoked)
+	return &user, nil
+}
+
+// PruneExpired deletes used and expired reset tokens.
+func (s *PasswordResetService) PruneExpired(ctx context.Context) error {
+	deleted, err := s.repo.PasswordReset.DeleteExpired(time.Now())
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("Password reset prune: deleted %d token(s)", deleted)
+	}
+	return nil
+}
+
+func (s *PasswordResetService) resetURL(secret string) string {
+	return s.baseURL + "/reset-password?token=" + url.QueryEscape(secret)
+}
+
+// formatTTL renders durations like 1h0m0s as "1 hour" / "30 minutes" for email text.
+func formatTTL(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		if h := int(d / time.Hour); h > 1 {
+			return fmt.Sprintf("%d hours", h)
+		}
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d minutes", int(d/time.Minute))
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type passwordResetFixture struct {
+	svc      *PasswordResetService
+	users    *memUserRepo
+	resets   *memPasswordResetRepo
+	sessions *memSessionRepo
+	mailer   *captureMailer
+	user     *models.User
+}
+
+func newPasswordResetFixture(t *testing.T, ttl time.Duration) *passwordResetFixture {
+	t.Helper()
+	user := &models.User{ID: uuid.New(), Username: "listener", Email: "listener@example.com", FailedLoginCount: 3}
+	f := &passwordResetFixture{
+		users:    newMemUserRepo(user),
+		resets:   &memPasswordResetRepo{},
+		sessions: &memSessionRepo{},
+		mailer:   newCaptureMailer(),
+		user:     user,
+	}
+	repo := &repository.Repository{User: f.users, PasswordReset: f.resets, Session: f.sessions}
+	f.svc = NewPasswordResetService(repo, NewSessionService(repo, time.Minute), f.mailer, "https://aurify.test", ttl, 5)
+	return f
+}
+
+var resetLink = regexp.MustCompile(`https://aurify\.test/reset-password\?token=(\S+)`)
+
+// requestSecret, gönderilen e-postadaki linkten gizli değeri çıkarır.
+func (f *passwordResetFixture) requestSecret(t *testing.T) string {
+	t.Helper()
+	if _, err := f.svc.RequestReset(f.user.Email, "203.0.113.7"); err != nil {
+		t.Fatalf("RequestReset: %v", err)
+	}
+	var msg MailMessage
+	select {
+	case msg = <-f.mailer.sent:
+	case <-time.After(2 * time.Second):
+		t.Fatal("reset mail was not sent")
+	}
+	if msg.To != f.user.Email {
+		t.Fatalf("mail sent to %q, want %q", msg.To, f.user.Email)
+	}
+	match := resetLink.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("no reset link in mail body:\n%s", msg.Body)
+	}
+	secret, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return secret
+}
+
+func TestPasswordResetConsumesTokenOnce(t *testing.T) {
+	f := newPasswordResetFixture(t, time.Hour)
+	secret := f.requestSecret(t)
+
+	if err := f.svc.ValidateToken(secret); err != nil {
+		t.Fatalf("ValidateToken on a fresh link: %v", err)
+	}
+	if _, err := f.svc.ResetPassword(secret, "new-password-1"); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+
+	stored := f.users.user(f.user.ID)
+	if bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte("new-password-1")) != nil {
+		t.Error("new password was not stored")
+	}
+	if stored.FailedLoginCount != 0 {
+		t.Errorf("login failures not reset: %d", stored.FailedLoginCount)
+	}
+	if len(f.sessions.revoked) != 1 || f.sessions.revoked[0] != f.user.ID {
+		t.Errorf("sessions not revoked for user: %v", f.sessions.revoked)
+	}
+
+	if err := f.svc.ValidateToken(secret); !errors.Is(err, ErrResetTokenInvalid) {
+		t.Errorf("ValidateToken after use = %v, want ErrResetTokenInvalid", err)
+	}
+	if _, err := f.svc.ResetPassword(secret, "new-password-2"); !errors.Is(err, ErrResetTokenInvalid) {
+		t.Fatalf("second ResetPassword = %v, want ErrResetTokenInvalid", err)
+	}
+	stored = f.users.user(f.user.ID)
+	if bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte("new-password-2")) == nil {
+		t.Error("a used link changed the password again")
+	}
+}
+
+func TestPasswordResetInvalidatesOtherLinks(t *testing.T) {
+	f := newPasswordResetFixture(t, time.Hour)
+	first := f.requestSecret(t)
+	second := f.requestSecret(t)
+
+	if _, err := f.svc.ResetPassword(second, "new-password-1"); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if _, err := f.svc.ResetPassword(first, "new-password-2"); !errors.Is(err, ErrResetTokenInvalid) {
+		t.Errorf("older link after reset = %v, want ErrResetTokenInvalid", err)
+	}
+}
+
+func TestPasswordResetExpiredToken(t *testing.T) {
+	f := newPasswordResetFixture(t, time.Hour)
+	secret, hash, err := utils.NewOpaqueToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	f.resets.Create(&models.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    f.user.ID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(-time.Second),
+		CreatedAt: now.Add(-time.Hour),
+	})
+
+	if err := f.svc.ValidateToken(secret); !errors.Is(err, ErrResetTokenInvalid) {
+		t.Errorf("ValidateToken on expired link = %v, want ErrResetTokenInvalid", err)
+	}
+	if _, err := f.svc.ResetPassword(secret, "new-password-1"); !errors.Is(err, ErrResetTokenInvalid) {
+		t.Errorf("ResetPassword on expired link = %v, want ErrResetTokenInvalid", err)
+	}
+	if f.users.user(f.user.ID).PasswordHash != "" {
+		t.Error("expired link changed the password")
+	}
+}
+
+func TestPasswordResetRejectsUnknownAndEmptyToken(t *testing.T) {
+	f := newPasswordResetFixture(t, time.Hour)
+	for _, secret := range []string{"", "not-a-real-token"} {
+		if _, err := f.svc.ResetPassword(secret, "new-password-1"); !errors.Is(err, ErrResetTokenInvalid) {
+			t.Errorf("ResetPassword(%q) = %v, want ErrResetTokenInvalid", secret, err)
+		}
+	}
+}
+
+func TestPasswordResetSuspendedUser(t *testing.T) {
+	f := newPasswordResetFixture(t, time.Hour)
+	secret := f.requestSecret(t)
+	suspendedAt := time.Now()
+	f.users.users[f.user.ID].SuspendedAt = &suspendedAt
+
+	if _, err := f.svc.ResetPassword(secret, "new-password-1"); !errors.Is(err, ErrResetTokenInvalid) {
+		t.Errorf("ResetPassword for suspended user = %v, want ErrResetTokenInvalid", err)
+	}
+}
+
+func TestPasswordResetUnknownEmail(t *testing.T) {
+	f := newPasswordResetFixture(t, time.Hour)
+	user, err := f.svc.RequestReset("nobody@example.com", "203.0.113.7")
+	if err != nil || user != nil {
+		t.Fatalf("RequestReset(unknown) = %v, %v; want nil, nil", user, err)
+	}
+	select {
+	case msg := <-f.mailer.sent:
+		t.Errorf("mail sent for unknown address: %+v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+)
+
+const (
+	playlistTitleMaxLength       = 100
+	playlistDescriptionMaxLength = 1000
+	playlistCoverSize            = 512
+)
+
+var (
+	ErrPlaylistTitleRequired      = errors.New("playlist title is required")
+	ErrPlaylistTitleTooLong       = errors.New("playlist title is too long")
+	ErrPlaylistDescriptionTooLong = errors.New("playlist description is too long")
+	ErrPlaylistVisibility         = errors.New("unknown playlist visibility")
+	ErrPlaylistCoverTooLarge      = errors.New("cover image is too large")
+	ErrPlaylistCoverInvalid       = errors.New("cover must be a PNG, JPEG or GIF image")
+	// Parça eklenemez: silinmiş, ya da başkasına ait ve gizli
+	ErrPlaylistTrackUnavailable = errors.New("track cannot be added to a playlist")
+)
+
+// PlaylistDetails kullanıcıdan gelen, doğrulanmamış liste bilgileridir.
+type PlaylistDetails struct {
+	Title       string
+	Description string
+	Visibility  string
+}
+
+// normalize boşlukları kırpar ve alanları doğrular. Boş görünürlük "private" sayılır.
+func (d *PlaylistDetails) normalize() error {
+	d.Title = strings.TrimSpace(d.Title)
+	d.Description = strings.TrimSpace(d.Description)
+	d.Visibility = strings.ToLower(strings.TrimSpace(d.Visibility))
+	if d.Visibility == "" {
+		d.Visibility = models.PlaylistPrivate
+	}
+	switch {
+	case d.Title == "":
+		return ErrPlaylistTitleRequired
+	case utf8.RuneCountInString(d.Title) > playlistTitleMaxLength:
+		return ErrPlaylistTitleTooLong
+	case utf8.RuneCountInString(d.Description) > playlistDescriptionMaxLength:
+		return ErrPlaylistDescriptionTooLong
+	case !slices.Contains(models.PlaylistVisibilities, d.Visibility):
+		return ErrPlaylistVisibility
+	}
+	return nil
+}
+
+// PlaylistService creates and edits playlists and manages their cover images.
+type PlaylistService struct {
+	repo          *repository.Repository
+	storage       *LocalStorage
+	coverMaxBytes int
+}
+
+func NewPlaylistService(repo *repository.Repository, storage *LocalStorage, coverMaxBytes int) *PlaylistService {
+	return &PlaylistService{repo: repo, storage: storage, coverMaxBytes: coverMaxBytes}
+}
+
+// CoverMaxBytes is the upload limit for cover images; it is the same as the avatar limit.
+func (s *PlaylistService) CoverMaxBytes() int {
+	return s.coverMaxBytes
+}
+
+// CoverURL returns the URL of the playlist's own cover, or "" if none is set.
+func (s *PlaylistService) CoverURL(playlist *models.Playlist) string {
+	if playlist.CoverKey == "" {
+		return ""
+	}
+	return s.storage.URLFor(playlist.CoverKey)
+}
+
+// Create validates the details and stores a new playlist owned by ownerID.
+func (s *PlaylistService) Create(ownerID uuid.UUID, details PlaylistDetails) (*models.Playlist, error) {
+	if err := details.normalize(); err != nil {
+		return nil, err
+	}
+	playlist := &models.Playlist{
+		UserID:      ownerID,
+		Title:       details.Title,
+		Description: details.Description,
+		Visibility:  details.Visibility,
+	}
+	if err := s.repo.Playlist.Create(playlist); err != nil {
+		return nil, err
+	}
+	return playlist, nil
+}
+
+// Update replaces the title, description and visibility of the playlist.
+func (s *PlaylistService) Update(playlist *models.Playlist, details PlaylistDetails) error {
+	if err := details.normalize(); err != nil {
+		return err
+	}
+	err := s.repo.Playlist.UpdateDetails(playlist.ID, map[string]interface{}{
+		"title":       details.Title,
+		"description": details.Description,
+		"visibility":  details.Visibility,
+	})
+	if err != nil {
+		return err
+	}
+	playlist.Title, playlist.Description, playlist.Visibility = details.Title, details.Description, details.Visibility
+	return nil
+}
+
+// AddTrack appends a track to the playlist. Only tracks the owner can see may be added: public
+// tracks and the owner's own tracks. repository.ErrPlaylistEntryExists is returned for duplicates.
+func (s *PlaylistService) AddTrack(playlist *models.Playlist, musicID uuid.UUID) error {
+	var music models.Music
+	if err := s.repo.Music.GetByID(musicID, &music); err != nil {
+		return err
+	}
+	if !music.IsPublic && (music.UserID == nil || *music.UserID != playlist.UserID) {
+		return ErrPlaylistTrackUnavailable
+	}
+	return s.repo.Playlist.AddEntry(playlist.ID, musicID)
+}
+
+// SetCover stores a square copy of the uploaded image and replaces the previous cover.
+func (s *PlaylistService) SetCover(playlist *models.Playlist, data []byte) error {
+	if len(data) > s.coverMaxBytes {
+		return ErrPlaylistCoverTooLarge
+	}
+	cover, err := processSquareImage(data, playlistCoverSize, ErrPlaylistCoverInvalid)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("playlists/%s/%s.png", playlist.ID, randomHex(8))
+	if err := s.storage.Write(key, cover); err != nil {
+		return err
+	}
+	if err := s.repo.Playlist.SetCoverKey(playlist.ID, key); err != nil {
+		_ = s.storage.Remove(key)
+		return err
+	}
+	s.removeCoverFile(playlist.CoverKey)
+	playlist.CoverKey = key
+	return nil
+}
+
+// RemoveCover switches the playlist back to the cover of its first track.
+func (s *PlaylistService) RemoveCover(playlist *models.Playlist) error {
+	if playlist.CoverKey == "" {
+		return nil
+	}
+	if err := s.repo.Playlist.SetCoverKey(playlist.ID, ""); err != nil {
+		return err
+	}
+	s.removeCoverFile(playlist.CoverKey)
+	playlist.CoverKey = ""
+	return nil
+}
+
+// Delete removes the playlist with its entries and cover image.
+func (s *PlaylistService) Delete(playlist *models.Playlist) error {
+	if err := s.repo.Playlist.Delete(playlist.ID); err != nil {
+		return err
+	}
+	s.removeCoverFile(playlist.CoverKey)
+	return nil
+}
+
+// removeCoverFile deletes a replaced cover. Failures are only logged; artifact GC removes the file later.
+func (s *PlaylistService) removeCoverFile(key string) {
+	if key == "" {
+		return
+	}
+	if err := s.storage.Remove(key); err != nil {
+		log.Printf("Playlists: removing cover %s failed: %v", key, err)
+	}
+}
@@ -119,6 +119,9 @@ func (c *RabbitMQClient) handleReplies() {
 	// This indicates the connection/channel likely closed. Trigger cleanup/reconnection if implemented.
 }
 
+// ErrRPCTimeout is returned by Call when no reply arrives within the timeout.
+var ErrRPCTimeout = errors.New("timeout waiting for response")
+
 // Call performs an RPC request.
 // It publishes a message to the specified queue and waits for a response on the reply queue.
 func (c *RabbitMQClient) Call(requestQueue string, requestBody []byte, timeout time.Duration) ([]byte, error) {
@@ -171,7 +174,7 @@ func (c *RabbitMQClient) Call(requestQueue string, requestBody []byte, timeout t
 		return response, nil
 	case <-time.After(timeout):
 		log.Printf("Timeout waiting for reply (CorrelationID: %s)", correlationID)
-		return nil, errors.New("timeout waiting for response")
+		return nil, ErrRPCTimeout
 		// Consider context cancellation as well for more complex scenarios
 		// case <-ctx.Done():
 		//  log.Printf("Context cancelled waiting for reply (CorrelationID: %s)", correlationID)
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiterSweepSize is the number of tracked keys after which stale keys are swept on the next call.
+const rateLimiterSweepSize = 10000
+
+// RateLimiter is an in-memory sliding-window limiter keyed by an arbitrary string (e-mail, IP, ...).
+// Limits are per instance; they are meant to slow down abuse, not to be exact across replicas.
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu   sync.Mutex
+	hits map[string][]time.Time
+}
+
+// NewRateLimiter allows limit events per key within window. A limit <= 0 disables limiting.
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{limit: limit, window: window, hits: make(map[string][]time.Time)}
+}
+
+// Allow records an event for key and reports whether it is within the limit.
+// Rejected events are not recorded, so a blocked caller regains access once the window passes.
+func (l *RateLimiter) Allow(key string) bool {
+	if l.limit <= 0 {
+		return true
+	}
+	now := time.Now()
+	cutoff := now.Add(-l.window)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.hits) > rateLimiterSweepSize {
+		for k, times := range l.hits {
+			if len(times) == 0 || !times[len(times)-1].After(cutoff) {
+				delete(l.hits, k)
+			}
+		}
+	}
+
+	times := l.hits[key]
+	kept := times[:0]
+	for _, t := range times {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) >= l.limit {
+		l.hits[key] = kept
+		return false
+	}
+	l.hits[key] = append(kept, now)
+	return true
+}
+
+// Reset forgets the recorded events of key, e.g. after a successful attempt when only failures should count.
+func (l *RateLimiter) Reset(key string) {
+	l.mu.Lock()
+	delete(l.hits, key)
+	l.mu.Unlock()
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter(3, time.Minute)
+	for i := 0; i < 3; i++ {
+		if !l.Allow("a") {
+			t.Fatalf("event %d rejected within the limit", i+1)
+		}
+	}
+	if l.Allow("a") {
+		t.Fatal("event over the limit allowed")
+	}
+	if !l.Allow("b") {
+		t.Fatal("limit is not per key")
+	}
+
+	l.Reset("a")
+	if !l.Allow("a") {
+		t.Fatal("event rejected after Reset")
+	}
+}
+
+func TestRateLimiterWindow(t *testing.T) {
+	l := NewRateLimiter(1, 20*time.Millisecond)
+	if !l.Allow("a") || l.Allow("a") {
+		t.Fatal("limit of 1 not applied")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if !l.Allow("a") {
+		t.Fatal("event rejected after the window passed")
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	l := NewRateLimiter(0, time.Minute)
+	for i := 0; i < 100; i++ {
+		if !l.Allow("a") {
+			t.Fatal("disabled limiter rejected an event")
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+
+	"github.com/morgarakt/aurify/internal/config"
+	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/utils"
+)
+
+// Services groups the application services shared by the router and background jobs.
+type Services struct {
+	Storage           *LocalStorage
+	Waveform          *WaveformService
+	ArtifactGC        *ArtifactSweeper
+	Purger            *MusicPurger
+	Sessions          *SessionService
+	Analytics         *AnalyticsService
+	Audit             *AuditService
+	Mailer            Mailer
+	PasswordReset     *PasswordResetService
+	EmailVerification *EmailVerificationService
+	TwoFactor         *TwoFactorService
+	OIDC              *OIDCService // OIDC yapılandırılmamışsa nil
+	AccessTokens      *AccessTokenService
+	LoginGuard        *LoginGuard
+	Account           *AccountService
+	DataExports       *DataExportService
+	Playlists         *PlaylistService
+	Comments          *CommentService
+}
+
+func NewServices(repo *repository.Repository, cfg *config.Config) (*Services, error) {
+	storage, err := NewLocalStorage(cfg.GeneratedDir)
+	if err != nil {
+		return nil, err
+	}
+	sessions := NewSessionService(repo, cfg.SessionCacheTTL)
+	// Token doğrulama utils paketinde yapıldığı için oturum deposu oraya kaydedilir.
+	utils.SetSessionStore(sessions)
+	utils.SetTokenLifetimes(cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+	accessTokens := NewAccessTokenService(repo)
+	utils.SetAccessTokenStore(accessTokens)
+	mailer, err := NewMailer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	totpBox, err := utils.NewSecretBox(cfg.TOTPEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	emailVerification := NewEmailVerificationService(repo, mailer, cfg.AppBaseURL, cfg.EmailVerificationTTL, cfg.EmailVerificationResendLimit, cfg.IsAdminEmail)
+	dataExports, err := NewDataExportService(repo, storage, mailer, cfg.DataExportDir, cfg.AppBaseURL, cfg.DataExportTTL, cfg.DataExportCooldown)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Services{
+		Storage:           storage,
+		Waveform:          NewWaveformService(repo, storage),
+		ArtifactGC:        NewArtifactSweeper(repo, storage, cfg.ArtifactGCGracePeriod),
+		Purger:            NewMusicPurger(repo, storage, cfg.MusicDeleteRetention),
+		Sessions:          sessions,
+		Analytics:         NewAnalyticsService(repo),
+		Audit:             NewAuditService(repo),
+		Mailer:            mailer,
+		PasswordReset:     NewPasswordResetService(repo, sessions, mailer, cfg.AppBaseURL, cfg.PasswordResetTTL, cfg.PasswordResetRateLimit),
+		EmailVerification: emailVerification,
+		TwoFactor:         NewTwoFactorService(repo, totpBox, cfg.TOTPIssuer),
+		OIDC:              NewOIDCService(repo, cfg),
+		AccessTokens:      accessTokens,
+		LoginGuard:        NewLoginGuard(repo, mailer, cfg),
+		Account:           NewAccountService(repo, storage, mailer, emailVerification, cfg.AvatarMaxBytes),
+		DataExports:       dataExports,
+		Playlists:         NewPlaylistService(repo, storage, cfg.AvatarMaxBytes),
+		Comments:          NewCommentService(repo, cfg.CommentRateLimit),
+	}, nil
+}
+
+// StartBackgroundJobs schedules the periodic housekeeping jobs. They stop when ctx is cancelled.
+func (s *Services) StartBackgroundJobs(ctx context.Context, cfg *config.Config) {
+	RunPeriodic(ctx, "artifact-gc", cfg.ArtifactGCInterval, func(ctx context.Context) error {
+		return s.ArtifactGC.Run(ctx, cfg.ArtifactGCDryRun)
+	})
+	RunPeriodic(ctx, "music-purge", cfg.MusicPurgeInterval, s.Purger.Run)
+	RunPeriodic(ctx, "session-prune", cfg.SessionPruneInterval, s.Sessions.PruneExpired)
+	RunPeriodic(ctx, "password-reset-prune", cfg.SessionPruneInterval, s.PasswordReset.PruneExpired)
+	RunPeriodic(ctx, "email-verification-prune", cfg.SessionPruneInterval, s.EmailVerification.PruneExpired)
+	RunPeriodic(ctx, "data-export-prune", cfg.SessionPruneInterval, s.DataExports.PruneExpired)
+	go s.DataExports.RunWorker(ctx)
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/utils"
+	"gorm.io/gorm"
+)
+
+// sessionTouchInterval limits how often last_seen_at is written for an active session.
+const sessionTouchInterval = time.Minute
+
+// refreshReuseGrace is how long the previous refresh token stays acceptable after a rotation,
+// so parallel requests that all carried the old cookie are not mistaken for token theft.
+const refreshReuseGrace = 30 * time.Second
+
+type cachedSession struct {
+	userID    uuid.UUID
+	active    bool
+	expiresAt time.Time
+	checkedAt time.Time
+	touchedAt time.Time
+}
+
+// SessionService implements utils.SessionStore on top of the sessions table.
+// Validation results are cached for cacheTTL so most requests do not hit the database;
+// revocations made on another instance therefore take effect within cacheTTL.
+type SessionService struct {
+	repo     *repository.Repository
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]*cachedSession
+}
+
+var _ utils.SessionStore = (*SessionService)(nil)
+
+func NewSessionService(repo *repository.Repository, cacheTTL time.Duration) *SessionService {
+	return &SessionService{
+		repo:     repo,
+		cacheTTL: cacheTTL,
+		cache:    make(map[uuid.UUID]*cachedSession),
+	}
+}
+
+func (s *SessionService) CreateSession(userID uuid.UUID, userAgent, ip, refreshHash string, expiresAt time.Time) (uuid.UUID, error) {
+	now := time.Now()
+	session := &models.Session{
+		ID:               uuid.New(),
+		UserID:           userID,
+		UserAgent:        Truncate(userAgent, 512),
+		IPAddress:        Truncate(ip, 64),
+		CreatedAt:        now,
+		LastSeenAt:       now,
+		ExpiresAt:        expiresAt,
+		RefreshTokenHash: refreshHash,
+	}
+	if err := s.repo.Session.Create(session); err != nil {
+		return uuid.Nil, err
+	}
+	s.mu.Lock()
+	s.cache[session.ID] = &cachedSession{userID: userID, active: true, expiresAt: expiresAt, checkedAt: now, touchedAt: now}
+	s.mu.Unlock()
+	return session.ID, nil
+}
+
+func (s *SessionService) ValidateSession(sessionID, userID uuid.UUID, ip string) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.cache[sessionID]
+	if ok && now.Sub(entry.checkedAt) > s.cacheTTL {
+		ok = false
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		session, err := s.repo.Session.GetByID(sessionID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		entry = &cachedSession{checkedAt: now}
+		if session != nil {
+			entry.userID = session.UserID
+			entry.active = session.IsActive(now)
+			entry.expiresAt = session.ExpiresAt
+			entry.touchedAt = session.LastSeenAt
+		}
+		s.mu.Lock()
+		s.cache[sessionID] = entry
+		s.mu.Unlock()
+	}
+
+	if !entry.active || entry.userID != userID || !now.Before(entry.expiresAt) {
+		return utils.ErrSessionInactive
+	}
+
+	s.mu.Lock()
+	shouldTouch := now.Sub(entry.touchedAt) >= sessionTouchInterval
+	if shouldTouch {
+		entry.touchedAt = now
+	}
+	s.mu.Unlock()
+	if shouldTouch {
+		if err := s.repo.Session.Touch(sessionID, now, Truncate(ip, 64)); err != nil {
+			log.Printf("Failed to update last seen for session %s: %v", sessionID, err)
+		}
+	}
+	return nil
+}
+
+// RotateRefreshToken exchanges the presented refresh token for newHash. Presenting a token that is
+// neither current nor the just-rotated previous one revokes the whole session (token family).
+func (s *SessionService) RotateRefreshToken(sessionID uuid.UUID, presentedHash, newHash string, expiresAt time.Time) (*utils.RefreshResult, error) {
+	now := time.Now()
+	session, err := s.repo.Session.GetByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+	if !session.IsActive(now) {
+		return nil, utils.ErrSessionInactive
+	}
+
+	rotated := false
+	if session.RefreshTokenHash != "" && session.RefreshTokenHash == presentedHash {
+		rotated, err = s.repo.Session.RotateRefreshHash(sessionID, presentedHash, newHash, expiresAt, now)
+		if err != nil {
+			return nil, err
+		}
+		if !rotated {
+			// Başka bir istek aynı token'ı az önce döndürdü; güncel durumu tekrar oku.
+			if session, err = s.repo.Session.GetByID(sessionID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !rotated && !s.withinReuseGrace(session, presentedHash, now) {
+		log.Printf("Refresh token reuse detected for session %s (user %s); revoking session", sessionID, session.UserID)
+		if err := s.RevokeSession(sessionID); err != nil {
+			log.Printf("Failed to revoke session %s after refresh token reuse: %v", sessionID, err)
+		}
+		return nil, utils.ErrRefreshTokenReused
+	}
+
+	var user models.User
+	if err := s.repo.User.GetByID(session.UserID, &user); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = s.RevokeSession(sessionID)
+			return nil, utils.ErrSessionInactive
+		}
+		return nil, err
+	}
+	if user.IsSuspended() {
+		_ = s.RevokeSession(sessionID)
+		return nil, utils.ErrSessionInactive
+	}
+
+	s.mu.Lock()
+	if entry, ok := s.cache[sessionID]; ok && rotated {
+		entry.expiresAt = expiresAt
+	}
+	s.mu.Unlock()
+
+	return &utils.RefreshResult{UserID: user.ID, Username: user.Username, Role: user.Role, Rotated: rotated}, nil
+}
+
+func (s *SessionService) withinReuseGrace(session *models.Session, presentedHash string, now time.Time) bool {
+	return session.PreviousRefreshHash != "" &&
+		session.PreviousRefreshHash == presentedHash &&
+		session.RefreshRotatedAt != nil &&
+		now.Sub(*session.RefreshRotatedAt) <= refreshReuseGrace
+}
+
+func (s *SessionService) RevokeSession(sessionID uuid.UUID) error {
+	if err := s.repo.Session.Revoke(sessionID); err != nil {
+		return err
+	}
+	s.forget(sessionID)
+	return nil
+}
+
+// ListActive returns the user's active sessions, most recently used first.
+func (s *SessionService) ListActive(userID uuid.UUID) ([]models.Session, error) {
+	return s.repo.Session.ListActiveForUser(userID, time.Now())
+}
+
+// RevokeUserSession revokes one of the user's own sessions.
+// It returns gorm.ErrRecordNotFound if the session does not belong to the user.
+func (s *SessionService) RevokeUserSession(userID, sessionID uuid.UUID) error {
+	session, err := s.repo.Session.GetByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return gorm.ErrRecordNotFound
+	}
+	return s.RevokeSession(sessionID)
+}
+
+// RevokeAllForUser revokes every session of the user except exceptID (uuid.Nil revokes all).
+func (s *SessionService) RevokeAllForUser(userID, exceptID uuid.UUID) (int64, error) {
+	revoked, err := s.repo.Session.RevokeAllForUser(userID, exceptID)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	for id, entry := range s.cache {
+		if entry.userID == userID && id != exceptID {
+			delete(s.cache, id)
+		}
+	}
+	s.mu.Unlock()
+	return revoked, nil
+}
+
+// PruneExpired deletes expired sessions and drops stale cache entries.
+func (s *SessionService) PruneExpired(ctx context.Context) error {
+	now := time.Now()
+	deleted, err := s.repo.Session.DeleteExpired(now)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	for id, entry := range s.cache {
+		if !now.Before(entry.expiresAt) || now.Sub(entry.checkedAt) > s.cacheTTL {
+			delete(s.cache, id)
+		}
+	}
+	s.mu.Unlock()
+
+	if deleted > 0 {
+		log.Printf("Session prune: removed %d expired session(s).", deleted)
+	}
+	return nil
+}
+
+func (s *SessionService) forget(sessionID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.cache, sessionID)
+	s.mu.Unlock()
+}
+
+// Truncate v'yi en fazla max karaktere kısaltır. Postgres varchar(n) uzunluğu karakter olarak saydığı için
+// kesim rune sınırında yapılır; geçersiz UTF-8 baytları (ör. ham User-Agent başlığında) insert'i bozmasın diye atılır.
+func Truncate(v string, max int) string {
+	v = strings.ToValidUTF8(v, "")
+	if utf8.RuneCountInString(v) <= max {
+		return v
+	}
+	return string([]rune(v)[:max])
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"truncated", 5, "trunc"},
+		{"şarkı söyle", 5, "şarkı"},
+		{"🎵🎶🎸", 2, "🎵🎶"},
+		{"bad\xffutf8", 6, "badutf"},
+	}
+	for _, tc := range cases {
+		got := Truncate(tc.in, tc.max)
+		if got != tc.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tc.in, tc.max, got, tc.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("Truncate(%q, %d) returned invalid UTF-8", tc.in, tc.max)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GeneratedURLPrefix is the public URL prefix the router serves the generated directory under.
+const GeneratedURLPrefix = "/generated/"
+
+var ErrInvalidStorageKey = errors.New("invalid storage key")
+
+// LocalStorage stores generated artifacts on the local filesystem.
+// Files are addressed by a storage key: the slash separated path relative to the root,
+// which is also what follows GeneratedURLPrefix in the public URL.
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage creates the root directory if needed and returns a storage rooted there.
+func NewLocalStorage(root string) (*LocalStorage, error) {
+	if root == "" {
+		return nil, errors.New("storage root cannot be empty")
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage root %s: %w", root, err)
+	}
+	return &LocalStorage{root: root}, nil
+}
+
+// Root returns the directory the storage is rooted at.
+func (s *LocalStorage) Root() string {
+	return s.root
+}
+
+// KeyFromURL converts a public /generated/... URL (as returned by the worker) into a storage key.
+func (s *LocalStorage) KeyFromURL(u string) (string, bool) {
+	if !strings.HasPrefix(u, GeneratedURLPrefix) {
+		return "", false
+	}
+	key, err := cleanKey(strings.TrimPrefix(u, GeneratedURLPrefix))
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// URLFor returns the public URL of a storage key.
+func (s *LocalStorage) URLFor(key string) string {
+	return GeneratedURLPrefix + key
+}
+
+// Path returns the filesystem path of a storage key.
+func (s *LocalStorage) Path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Write stores data under key, creating intermediate directories.
+func (s *LocalStorage) Write(key string, data []byte) error {
+	p, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+// Read returns the content stored under key.
+func (s *LocalStorage) Read(key string) ([]byte, error) {
+	p, err := s.Path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(p)
+}
+
+// Open opens the file stored under key for reading.
+func (s *LocalStorage) Open(key string) (*os.File, error) {
+	p, err := s.Path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+// Remove deletes the file stored under key. Missing files are not an error.
+func (s *LocalStorage) Remove(key string) error {
+	p, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Walk calls fn for every regular file in the storage with its key.
+func (s *LocalStorage) Walk(fn func(key string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel), info)
+	})
+}
+
+// cleanKey normalizes a key and rejects keys escaping the storage root.
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(path.Clean("/"+strings.TrimSpace(key)), "/")
+	if key == "" || key == "." || strings.HasPrefix(key, "../") {
+		return "", ErrInvalidStorageKey
+	}
+	return key, nil
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/utils"
+	qrcode "github.com/skip2/go-qrcode"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789" // karışabilecek 0/o, 1/l/i yok
+	qrCodeSize           = 256
+)
+
+// Second-factor methods reported by Verify.
+const (
+	TwoFactorMethodTOTP         = "totp"
+	TwoFactorMethodRecoveryCode = "recovery_code"
+)
+
+var (
+	ErrTwoFactorInvalidCode     = errors.New("invalid two-factor code")
+	ErrTwoFactorNotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNoPendingSetup  = errors.New("no pending two-factor setup")
+	ErrTwoFactorTooManyAttempts = errors.New("too many two-factor attempts")
+)
+
+// TwoFactorEnrollment is what the user needs to add the account to an authenticator app.
+type TwoFactorEnrollment struct {
+	Secret    string
+	URI       string
+	QRCodePNG []byte
+}
+
+// TwoFactorService manages TOTP enrollment, verification and recovery codes.
+// Secrets are stored sealed with the configured key; failed code attempts are rate limited per user.
+type TwoFactorService struct {
+	repo     *repository.Repository
+	box      *utils.SecretBox
+	issuer   string
+	attempts *RateLimiter
+}
+
+func NewTwoFactorService(repo *repository.Repository, box *utils.SecretBox, issuer string) *TwoFactorService {
+	return &TwoFactorService{
+		repo:     repo,
+		box:      box,
+		issuer:   issuer,
+		attempts: NewRateLimiter(5, 5*time.Minute),
+	}
+}
+
+// BeginEnrollment stores a new pending secret and returns its provisioning data.
+// Starting again replaces a pending secret that was never confirmed.
+func (s *TwoFactorService) BeginEnrollment(user *models.User) (*TwoFactorEnrollment, error) {
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.box.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.User.SetTOTP(user.ID, sealed, nil); err != nil {
+		return nil, err
+	}
+	uri := utils.TOTPProvisioningURI(s.issuer, user.Email, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		return nil, fmt.Errorf("rendering qr code: %w", err)
+	}
+	return &TwoFactorEnrollment{Secret: secret, URI: uri, QRCodePNG: png}, nil
+}
+
+// ConfirmEnrollment enables 2FA once the user proves the authenticator works,
+// and returns the recovery codes. They are shown only this once.
+func (s *TwoFactorService) ConfirmEnrollment(user *models.User, code string) ([]string, error) {
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNoPendingSetup
+	}
+	if !s.attempts.Allow(user.ID.String()) {
+		return nil, ErrTwoFactorTooManyAttempts
+	}
+	secret, err := s.box.Open(user.TOTPSecret)
+	if err != nil {
+		return nil, err
+	}
+	counter, ok := utils.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return nil, ErrTwoFactorInvalidCode
+	}
+	s.attempts.Reset(user.ID.String())
+	now := time.Now()
+	if err := s.repo.User.SetTOTP(user.ID, user.TOTPSecret, &now); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.User.AdvanceTOTPCounter(user.ID, counter); err != nil {
+		log.Printf("2FA: storing counter for user %s failed: %v", user.ID, err)
+	}
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+// Verify checks a TOTP code or, failing that, an unused recovery code, and returns the method that matched.
+// TOTP codes cannot be replayed and recovery codes are consumed.
+func (s *TwoFactorService) Verify(user *models.User, code string) (string, error) {
+	if !user.TwoFactorEnabled() {
+		return "", ErrTwoFactorNotEnabled
+	}
+	// Deneme kod kontrolünden önce ayrılır, böylece paralel istekler sınırı aşamaz; doğru kod sayacı
+	// sıfırlar, yani sınıra yalnızca hatalı kodlar takılır.
+	if !s.attempts.Allow(user.ID.String()) {
+		return "", ErrTwoFactorTooManyAttempts
+	}
+	method, err := s.checkCode(user, code)
+	if err == nil {
+		s.attempts.Reset(user.ID.String())
+	}
+	return method, err
+}
+
+func (s *TwoFactorService) checkCode(user *models.User, code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if isDigits(code) {
+		secret, err := s.box.Open(user.TOTPSecret)
+		if err != nil {
+			return "", err
+		}
+		counter, ok := utils.ValidateTOTP(secret, code, time.Now())
+		if !ok {
+			return "", ErrTwoFactorInvalidCode
+		}
+		advanced, err := s.repo.User.AdvanceTOTPCounter(user.ID, counter)
+		if err != nil {
+			return "", err
+		}
+		if !advanced {
+			return "", ErrTwoFactorInvalidCode
+		}
+		return TwoFactorMethodTOTP, nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeLength {
+		return "", ErrTwoFactorInvalidCode
+	}
+	codes, err := s.repo.RecoveryCode.ListUnused(user.ID)
+	if err != nil {
+		return "", err
+	}
+	for _, rc := range codes {
+		if bcrypt.CompareHashAndPassword([]byte(rc.CodeHash), []byte(normalized)) != nil {
+			continue
+		}
+		used, err := s.repo.RecoveryCode.MarkUsed(rc.ID, time.Now())
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			break
+		}
+		return TwoFactorMethodRecoveryCode, nil
+	}
+	return "", ErrTwoFactorInvalidCode
+}
+
+// Disable turns 2FA off after verifying a current code and removes the recovery codes.
+func (s *TwoFactorService) Disable(user *models.User, code string) error {
+	if _, err := s.Verify(user, code); err != nil {
+		return err
+	}
+	if err := s.repo.User.SetTOTP(user.ID, "", nil); err != nil {
+		return err
+	}
+	return s.repo.RecoveryCode.DeleteForUser(user.ID)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after verifying a current code.
+func (s *TwoFactorService) RegenerateRecoveryCodes(user *models.User, code string) ([]string, error) {
+	if _, err := s.Verify(user, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+// RemainingRecoveryCodes returns how many recovery codes are still unused.
+func (s *TwoFactorService) RemainingRecoveryCodes(userID uuid.UUID) (int64, error) {
+	return s.repo.RecoveryCode.CountUnused(userID)
+}
+
+func (s *TwoFactorService) replaceRecoveryCodes(userID uuid.UUID) ([]string, error) {
+	plain := make([]string, 0, recoveryCodeCount)
+	rows := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	now := time.Now()
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := randomRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, models.RecoveryCode{ID: uuid.New(), UserID: userID, CodeHash: string(hash), CreatedAt: now})
+		plain = append(plain, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+	}
+	if err := s.repo.RecoveryCode.ReplaceForUser(userID, rows); err != nil {
+		return nil, err
+	}
+	return plain, nil
+}
+
+func randomRecoveryCode() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := 0; i < recoveryCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("generating recovery code: %w", err)
+		}
+		b.WriteByte(recoveryCodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// normalizeRecoveryCode kullanıcının girdiği kodu (büyük harf, tire, boşluk) saklanan biçime çevirir.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range strings.ReplaceAll(s, " ", "") {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+	"github.com/morgarakt/aurify/internal/utils"
+)
+
+// totpCode RFC 6238 kodunu (SHA1, 6 hane, 30 sn) bağımsız olarak hesaplar.
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+func newTwoFactorFixture(t *testing.T) (*TwoFactorService, *memUserRepo, *models.User, string) {
+	t.Helper()
+	box, err := utils.NewSecretBox("test-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := box.Seal(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enabledAt := time.Now()
+	user := &models.User{ID: uuid.New(), Email: "listener@example.com", TOTPSecret: sealed, TOTPEnabledAt: &enabledAt}
+	users := newMemUserRepo(user)
+	svc := NewTwoFactorService(&repository.Repository{User: users}, box, "Aurify")
+	return svc, users, user, secret
+}
+
+func TestTwoFactorVerifyCountsOnlyFailures(t *testing.T) {
+	svc, users, user, secret := newTwoFactorFixture(t)
+
+	// Arka arkaya başarılı girişler sınıra takılmamalı
+	for i := 0; i < 8; i++ {
+		users.users[user.ID].TOTPLastCounter = 0 // bir sonraki 30 saniyelik adım
+		if _, err := svc.Verify(user, totpCode(t, secret, time.Now())); err != nil {
+			t.Fatalf("successful login %d: %v", i+1, err)
+		}
+	}
+
+	// Başarılı bir kod önceki hataları da siler
+	for i := 0; i < 4; i++ {
+		if _, err := svc.Verify(user, "000000x"); !errors.Is(err, ErrTwoFactorInvalidCode) {
+			t.Fatalf("wrong code %d: %v", i+1, err)
+		}
+	}
+	users.users[user.ID].TOTPLastCounter = 0
+	if _, err := svc.Verify(user, totpCode(t, secret, time.Now())); err != nil {
+		t.Fatalf("correct code after 4 failures: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := svc.Verify(user, "000000x"); !errors.Is(err, ErrTwoFactorInvalidCode) {
+			t.Fatalf("wrong code %d: %v", i+1, err)
+		}
+	}
+	users.users[user.ID].TOTPLastCounter = 0
+	if _, err := svc.Verify(user, totpCode(t, secret, time.Now())); !errors.Is(err, ErrTwoFactorTooManyAttempts) {
+		t.Fatalf("Verify after 5 failures = %v, want ErrTwoFactorTooManyAttempts", err)
+	}
+}
+
+func TestTwoFactorVerifyRejectsReplay(t *testing.T) {
+	svc, _, user, secret := newTwoFactorFixture(t)
+	code := totpCode(t, secret, time.Now())
+	if method, err := svc.Verify(user, code); err != nil || method != TwoFactorMethodTOTP {
+		t.Fatalf("Verify = %q, %v", method, err)
+	}
+	if _, err := svc.Verify(user, code); !errors.Is(err, ErrTwoFactorInvalidCode) {
+		t.Fatalf("replayed code = %v, want ErrTwoFactorInvalidCode", err)
+	}
+}
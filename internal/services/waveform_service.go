@@ -0,0 +1,317 @@
+package services
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/morgarakt/aurify/internal/models"
+	"github.com/morgarakt/aurify/internal/repository"
+)
+
+// WaveformResolutions are the peak counts precomputed for every track.
+// Cards use the smallest one, the player the larger ones.
+var WaveformResolutions = []int{64, 256, 1024}
+
+var ErrUnsupportedAudio = errors.New("unsupported audio format")
+
+// WaveformPeaks is the JSON document stored for each resolution.
+type WaveformPeaks struct {
+	Version    int       `json:"version"`
+	Resolution int       `json:"resolution"`
+	SampleRate int       `json:"sample_rate"`
+	Duration   float64   `json:"duration"`
+	Peaks      []float32 `json:"peaks"`
+}
+
+// WaveformService precomputes peaks from the WAV render the worker returns with each generation.
+// The WAV is not referenced by the track afterwards and the artifact sweeper removes it, so tracks
+// generated before peaks were introduced have none and are not backfilled; the player draws no
+// waveform for them (GET /api/v1/music/:id/waveform answers 404).
+type WaveformService struct {
+	repo    *repository.Repository
+	storage *LocalStorage
+}
+
+func NewWaveformService(repo *repository.Repository, storage *LocalStorage) *WaveformService {
+	return &WaveformService{repo: repo, storage: storage}
+}
+
+// GenerateForMusic decodes the WAV file the worker produced for a track and stores
+// peak data in JSON and binary form for every resolution in WaveformResolutions.
+func (s *WaveformService) GenerateForMusic(musicID uuid.UUID, wavURL string) error {
+	key, ok := s.storage.KeyFromURL(wavURL)
+	if !ok {
+		return fmt.Errorf("wav url %q is not served from generated storage", wavURL)
+	}
+	f, err := s.storage.Open(key)
+	if err != nil {
+		return fmt.Errorf("opening wav for music %s: %w", musicID, err)
+	}
+	defer f.Close()
+
+	peaks, err := ComputeWAVPeaks(bufio.NewReader(f), WaveformResolutions)
+	if err != nil {
+		return fmt.Errorf("computing peaks for music %s: %w", musicID, err)
+	}
+
+	for _, p := range peaks {
+		jsonData, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		if err := s.storeAsset(musicID, p.Resolution, models.AssetFormatJSON, jsonData); err != nil {
+			return err
+		}
+		if err := s.storeAsset(musicID, p.Resolution, models.AssetFormatBinary, EncodePeaksBinary(p.Peaks)); err != nil {
+			return err
+		}
+	}
+	log.Printf("Waveform peaks stored for music %s (%d resolutions)", musicID, len(peaks))
+	return nil
+}
+
+func (s *WaveformService) storeAsset(musicID uuid.UUID, resolution int, format string, data []byte) error {
+	key := fmt.Sprintf("waveforms/%s/%d.%s", musicID, resolution, format)
+	if err := s.storage.Write(key, data); err != nil {
+		return fmt.Errorf("writing %s: %w", key, err)
+	}
+	return s.repo.Asset.Upsert(&models.MusicAsset{
+		MusicID:    musicID,
+		Kind:       models.AssetKindWaveform,
+		Format:     format,
+		Resolution: resolution,
+		StorageKey: key,
+		SizeBytes:  int64(len(data)),
+		CreatedAt:  time.Now(),
+	})
+}
+
+// FindWaveform returns the stored waveform asset closest to the requested resolution,
+// preferring the smallest one that is at least as detailed.
+func (s *WaveformService) FindWaveform(musicID uuid.UUID, resolution int, format string) (*models.MusicAsset, error) {
+	assets, err := s.repo.Asset.GetForMusic(musicID, models.AssetKindWaveform)
+	if err != nil {
+		return nil, err
+	}
+	var best *models.MusicAsset
+	for i := range assets {
+		a := &assets[i]
+		if a.Format != format {
+			continue
+		}
+		if best == nil || (best.Resolution < resolution && a.Resolution > best.Resolution) {
+			best = a
+		}
+		if a.Resolution >= resolution {
+			break
+		}
+	}
+	return best, nil
+}
+
+// ReadAsset returns the stored bytes of an asset.
+func (s *WaveformService) ReadAsset(asset *models.MusicAsset) ([]byte, error) {
+	return s.storage.Read(asset.StorageKey)
+}
+
+// EncodePeaksBinary packs normalized peaks into one unsigned byte per bucket (0-255).
+func EncodePeaksBinary(peaks []float32) []byte {
+	out := make([]byte, len(peaks))
+	for i, p := range peaks {
+		out[i] = uint8(math.Round(float64(clamp01(p)) * 255))
+	}
+	return out
+}
+
+type wavFormat struct {
+	audioFormat   uint16
+	channels      int
+	sampleRate    int
+	bitsPerSample int
+	blockAlign    int
+}
+
+const (
+	// A fmt chunk is 16, 18 or 40 (WAVE_FORMAT_EXTENSIBLE) bytes; larger declared sizes are
+	// rejected before allocating, since the size comes straight from the file.
+	wavMaxFmtChunkSize = 64
+
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// ComputeWAVPeaks streams a RIFF/WAVE file once and returns the normalized absolute
+// peak of each bucket for every requested resolution. Channels are mixed by taking
+// the loudest sample of a frame.
+func ComputeWAVPeaks(r io.Reader, resolutions []int) ([]WaveformPeaks, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("reading wav header: %w", err)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, ErrUnsupportedAudio
+	}
+
+	var format *wavFormat
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, fmt.Errorf("wav data chunk not found: %w", err)
+		}
+		id := string(chunk[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+
+		switch id {
+		case "fmt ":
+			if size > wavMaxFmtChunkSize {
+				return nil, ErrUnsupportedAudio
+			}
+			buf := make([]byte, size)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return nil, err
+			}
+			f, err := parseWAVFormat(buf)
+			if err != nil {
+				return nil, err
+			}
+			format = f
+		case "data":
+			if format == nil {
+				return nil, errors.New("wav data chunk before fmt chunk")
+			}
+			return readWAVPeaks(io.LimitReader(r, size), size, format, resolutions)
+		default:
+			if _, err := io.CopyN(io.Discard, r, size); err != nil {
+				return nil, err
+			}
+		}
+		if size%2 == 1 { // RIFF chunks are word aligned
+			if _, err := io.CopyN(io.Discard, r, 1); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func parseWAVFormat(buf []byte) (*wavFormat, error) {
+	if len(buf) < 16 {
+		return nil, ErrUnsupportedAudio
+	}
+	f := &wavFormat{
+		audioFormat:   binary.LittleEndian.Uint16(buf[0:2]),
+		channels:      int(binary.LittleEndian.Uint16(buf[2:4])),
+		sampleRate:    int(binary.LittleEndian.Uint32(buf[4:8])),
+		blockAlign:    int(binary.LittleEndian.Uint16(buf[12:14])),
+		bitsPerSample: int(binary.LittleEndian.Uint16(buf[14:16])),
+	}
+	if f.audioFormat == wavFormatExtensible && len(buf) >= 26 {
+		// The first two bytes of the sub-format GUID carry the actual format code.
+		f.audioFormat = binary.LittleEndian.Uint16(buf[24:26])
+	}
+	switch {
+	case f.channels < 1 || f.sampleRate < 1:
+		return nil, ErrUnsupportedAudio
+	case f.audioFormat == wavFormatPCM && (f.bitsPerSample == 8 || f.bitsPerSample == 16 || f.bitsPerSample == 24 || f.bitsPerSample == 32):
+	case f.audioFormat == wavFormatFloat && f.bitsPerSample == 32:
+	default:
+		return nil, ErrUnsupportedAudio
+	}
+	if f.blockAlign != f.channels*f.bitsPerSample/8 {
+		return nil, ErrUnsupportedAudio
+	}
+	return f, nil
+}
+
+func readWAVPeaks(r io.Reader, dataSize int64, f *wavFormat, resolutions []int) ([]WaveformPeaks, error) {
+	totalFrames := dataSize / int64(f.blockAlign)
+	if totalFrames == 0 {
+		return nil, errors.New("wav file contains no audio frames")
+	}
+
+	results := make([]WaveformPeaks, len(resolutions))
+	for i, res := range resolutions {
+		results[i] = WaveformPeaks{
+			Version:    1,
+			Resolution: res,
+			SampleRate: f.sampleRate,
+			Duration:   float64(totalFrames) / float64(f.sampleRate),
+			Peaks:      make([]float32, res),
+		}
+	}
+
+	bytesPerSample := f.bitsPerSample / 8
+	frame := make([]byte, f.blockAlign)
+	var maxPeak float32
+	for n := int64(0); n < totalFrames; n++ {
+		if _, err := io.ReadFull(r, frame); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+				break // truncated file, keep what we have
+			}
+			return nil, err
+		}
+		var amp float32
+		for ch := 0; ch < f.channels; ch++ {
+			v := decodeSample(frame[ch*bytesPerSample:(ch+1)*bytesPerSample], f)
+			if v < 0 {
+				v = -v
+			}
+			if v > amp {
+				amp = v
+			}
+		}
+		if amp > maxPeak {
+			maxPeak = amp
+		}
+		for i := range results {
+			bucket := int(n * int64(results[i].Resolution) / totalFrames)
+			if amp > results[i].Peaks[bucket] {
+				results[i].Peaks[bucket] = amp
+			}
+		}
+	}
+
+	// Normalize so quiet renders still produce a readable waveform.
+	if maxPeak > 0 {
+		for i := range results {
+			for j, p := range results[i].Peaks {
+				results[i].Peaks[j] = float32(math.Round(float64(clamp01(p/maxPeak))*1000) / 1000)
+			}
+		}
+	}
+	return results, nil
+}
+
+func decodeSample(b []byte, f *wavFormat) float32 {
+	switch {
+	case f.audioFormat == wavFormatFloat:
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	case f.bitsPerSample == 8:
+		return (float32(b[0]) - 128) / 128
+	case f.bitsPerSample == 16:
+		return float32(int16(binary.LittleEndian.Uint16(b))) / 32768
+	case f.bitsPerSample == 24:
+		v := int32(b[0]) | int32(b[1])<<8 | int32(int8(b[2]))<<16
+		return float32(v) / 8388608
+	default:
+		return float32(int32(binary.LittleEndian.Uint32(b))) / 2147483648
+	}
+}
+
+func clamp01(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+type wavChunk struct {
+	id   string
+	size uint32 // 0 ise len(data) kullanılır
+	data []byte
+}
+
+// buildWAV verilen chunk'lardan bir RIFF/WAVE dosyası üretir.
+func buildWAV(chunks ...wavChunk) []byte {
+	var body bytes.Buffer
+	body.WriteString("WAVE")
+	for _, c := range chunks {
+		size := c.size
+		if size == 0 {
+			size = uint32(len(c.data))
+		}
+		body.WriteString(c.id)
+		binary.Write(&body, binary.LittleEndian, size)
+		body.Write(c.data)
+		if len(c.data)%2 == 1 {
+			body.WriteByte(0)
+		}
+	}
+	var out bytes.Buffer
+	out.WriteString("RIFF")
+	binary.Write(&out, binary.LittleEndian, uint32(body.Len()))
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+func fmtChunk(audioFormat uint16, channels, sampleRate, bitsPerSample int) wavChunk {
+	var b bytes.Buffer
+	blockAlign := channels * bitsPerSample / 8
+	binary.Write(&b, binary.LittleEndian, audioFormat)
+	binary.Write(&b, binary.LittleEndian, uint16(channels))
+	binary.Write(&b, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(&b, binary.LittleEndian, uint32(sampleRate*blockAlign))
+	binary.Write(&b, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(&b, binary.LittleEndian, uint16(bitsPerSample))
+	return wavChunk{id: "fmt ", data: b.Bytes()}
+}
+
+// extensibleFmtChunk 40 baytlık WAVE_FORMAT_EXTENSIBLE fmt chunk'ıdır.
+func extensibleFmtChunk(subFormat uint16, channels, sampleRate, bitsPerSample int) wavChunk {
+	c := fmtChunk(wavFormatExtensible, channels, sampleRate, bitsPerSample)
+	ext := make([]byte, 24)
+	binary.LittleEndian.PutUint16(ext[0:2], 22)
+	binary.LittleEndian.PutUint16(ext[2:4], uint16(bitsPerSample))
+	binary.LittleEndian.PutUint16(ext[8:10], subFormat)
+	c.data = append(c.data, ext...)
+	return c
+}
+
+func pcm16(samples ...int16) []byte {
+	var b bytes.Buffer
+	for _, s := range samples {
+		binary.Write(&b, binary.LittleEndian, s)
+	}
+	return b.Bytes()
+}
+
+func float32Samples(samples ...float32) []byte {
+	var b bytes.Buffer
+	for _, s := range samples {
+		binary.Write(&b, binary.LittleEndian, math.Float32bits(s))
+	}
+	return b.Bytes()
+}
+
+func TestComputeWAVPeaks(t *testing.T) {
+	cases := []struct {
+		name     string
+		wav      []byte
+		res      int
+		want     []float32
+		duration float64
+	}{
+		{
+			name:     "16-bit mono",
+			wav:      buildWAV(fmtChunk(wavFormatPCM, 1, 4, 16), wavChunk{id: "data", data: pcm16(1000, -2000, 500, 16000)}),
+			res:      2,
+			want:     []float32{0.125, 1},
+			duration: 1,
+		},
+		{
+			name:     "16-bit stereo takes the louder channel",
+			wav:      buildWAV(fmtChunk(wavFormatPCM, 2, 2, 16), wavChunk{id: "data", data: pcm16(100, -8000, 16000, 0)}),
+			res:      2,
+			want:     []float32{0.5, 1},
+			duration: 1,
+		},
+		{
+			name:     "8-bit unsigned",
+			wav:      buildWAV(fmtChunk(wavFormatPCM, 1, 2, 8), wavChunk{id: "data", data: []byte{128, 0}}),
+			res:      2,
+			want:     []float32{0, 1},
+			duration: 1,
+		},
+		{
+			name:     "32-bit float",
+			wav:      buildWAV(fmtChunk(wavFormatFloat, 1, 2, 32), wavChunk{id: "data", data: float32Samples(-0.25, 0.5)}),
+			res:      2,
+			want:     []float32{0.5, 1},
+			duration: 1,
+		},
+		{
+			name:     "extensible PCM",
+			wav:      buildWAV(extensibleFmtChunk(wavFormatPCM, 1, 2, 16), wavChunk{id: "data", data: pcm16(8000, 16000)}),
+			res:      2,
+			want:     []float32{0.5, 1},
+			duration: 1,
+		},
+		{
+			name: "unknown chunks are skipped, odd sizes padded",
+			wav: buildWAV(wavChunk{id: "LIST", data: []byte("abc")}, fmtChunk(wavFormatPCM, 1, 2, 16),
+				wavChunk{id: "data", data: pcm16(16000, 8000)}),
+			res:      2,
+			want:     []float32{1, 0.5},
+			duration: 1,
+		},
+		{
+			name: "truncated data keeps the frames that were read",
+			wav: buildWAV(fmtChunk(wavFormatPCM, 1, 4, 16),
+				wavChunk{id: "data", size: 8, data: pcm16(16000, 8000)}),
+			res:      2,
+			want:     []float32{1, 0},
+			duration: 1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			peaks, err := ComputeWAVPeaks(bytes.NewReader(tc.wav), []int{tc.res})
+			if err != nil {
+				t.Fatalf("ComputeWAVPeaks: %v", err)
+			}
+			got := peaks[0]
+			if got.Resolution != tc.res || got.Duration != tc.duration {
+				t.Errorf("resolution %d duration %v, want %d %v", got.Resolution, got.Duration, tc.res, tc.duration)
+			}
+			if len(got.Peaks) != len(tc.want) {
+				t.Fatalf("peaks = %v, want %v", got.Peaks, tc.want)
+			}
+			for i := range tc.want {
+				if math.Abs(float64(got.Peaks[i]-tc.want[i])) > 0.001 {
+					t.Errorf("peaks = %v, want %v", got.Peaks, tc.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestComputeWAVPeaksRejects(t *testing.T) {
+	valid := buildWAV(fmtChunk(wavFormatPCM, 1, 2, 16), wavChunk{id: "data", data: pcm16(1, 2)})
+	cases := []struct {
+		name        string
+		wav         []byte
+		unsupported bool // ErrUnsupportedAudio beklenir
+	}{
+		{"not RIFF", append([]byte("RIFX"), valid[4:]...), true},
+		{"truncated header", valid[:10], false},
+		{"truncated fmt chunk", valid[:12+8+8], false},
+		{"no data chunk", buildWAV(fmtChunk(wavFormatPCM, 1, 2, 16)), false},
+		{"data before fmt", buildWAV(wavChunk{id: "data", data: pcm16(1, 2)}, fmtChunk(wavFormatPCM, 1, 2, 16)), false},
+		{"ADPCM", buildWAV(fmtChunk(2, 1, 2, 4), wavChunk{id: "data", data: []byte{1, 2}}), true},
+		{"extensible non-PCM", buildWAV(extensibleFmtChunk(2, 1, 2, 16), wavChunk{id: "data", data: pcm16(1, 2)}), true},
+		{"12-bit PCM", buildWAV(fmtChunk(wavFormatPCM, 1, 2, 12), wavChunk{id: "data", data: pcm16(1, 2)}), true},
+		{"zero channels", buildWAV(fmtChunk(wavFormatPCM, 0, 2, 16), wavChunk{id: "data", data: pcm16(1, 2)}), true},
+		{"short fmt chunk", buildWAV(wavChunk{id: "fmt ", data: make([]byte, 14)}, wavChunk{id: "data", data: pcm16(1, 2)}), true},
+		// Chunk boyutu dosyadan okunur; 4 GiB'lik bir boyut ayırmadan önce reddedilmeli
+		{"oversized fmt chunk", buildWAV(wavChunk{id: "fmt ", size: math.MaxUint32, data: fmtChunk(wavFormatPCM, 1, 2, 16).data}), true},
+		{"empty data", buildWAV(fmtChunk(wavFormatPCM, 1, 2, 16), wavChunk{id: "data"}), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ComputeWAVPeaks(bytes.NewReader(tc.wav), []int{4})
+			if err == nil {
+				t.Fatal("ComputeWAVPeaks accepted invalid input")
+			}
+			if tc.unsupported && !errors.Is(err, ErrUnsupportedAudio) {
+				t.Errorf("error = %v, want ErrUnsupportedAudio", err)
+			}
+		})
+	}
+}
+
+func TestEncodePeaksBinary(t *testing.T) {
+	got := EncodePeaksBinary([]float32{0, 0.5, 1, 1.5, -1})
+	want := []byte{0, 128, 255, 255, 0}
+	if !bytes.Equal(got, want) {
+		t.Errorf("EncodePeaksBinary = %v, want %v", got, want)
+	}
+}
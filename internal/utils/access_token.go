@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AccessTokenPrefix kişisel erişim token'larının başına eklenir; böylece sızan token'lar
+// (örn. kod deposunda) kolayca tanınır ve JWT'lerle karışmaz.
+const AccessTokenPrefix = "aur_pat_"
+
+var ErrAccessTokenInvalid = errors.New("access token is invalid, revoked or expired")
+
+// AccessTokenPrincipal geçerli bir kişisel erişim token'ının sahibi ve yetkileridir.
+type AccessTokenPrincipal struct {
+	TokenID  uuid.UUID
+	UserID   uuid.UUID
+	Username string
+	Role     string
+	Scopes   []string
+}
+
+// HasScope token'ın verilen alana erişimi olup olmadığını bildirir.
+func (p *AccessTokenPrincipal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessTokenStore kişisel erişim token'larını doğrular. Geçersiz token'lar için ErrAccessTokenInvalid döner.
+type AccessTokenStore interface {
+	AuthenticateAccessToken(token, ip string) (*AccessTokenPrincipal, error)
+}
+
+var accessTokenStore AccessTokenStore
+
+// SetAccessTokenStore uygulama açılışında bir kez çağrılır.
+func SetAccessTokenStore(store AccessTokenStore) {
+	accessTokenStore = store
+}
+
+// NewAccessToken yeni bir kişisel erişim token'ı, saklanacak özetini ve listede gösterilecek önekini üretir.
+func NewAccessToken() (token, hash, prefix string, err error) {
+	secret, _, err := NewOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+	token = AccessTokenPrefix + secret
+	return token, HashOpaqueToken(token), token[:len(AccessTokenPrefix)+4], nil
+}
+
+// BearerToken Authorization başlığındaki "Bearer" token'ını döner.
+func BearerToken(c *gin.Context) (string, bool) {
+	header := c.GetHeader("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return "", false
+	}
+	token := strings.TrimSpace(header[7:])
+	return token, token != ""
+}
+
+// AuthenticateAccessToken Authorization başlığındaki kişisel erişim token'ını doğrular.
+func AuthenticateAccessToken(c *gin.Context) (*AccessTokenPrincipal, error) {
+	token, ok := BearerToken(c)
+	if !ok || !strings.HasPrefix(token, AccessTokenPrefix) {
+		return nil, ErrAccessTokenInvalid
+	}
+	if accessTokenStore == nil {
+		return nil, errors.New("access token store is not configured")
+	}
+	return accessTokenStore.AuthenticateAccessToken(token, c.ClientIP())
+}
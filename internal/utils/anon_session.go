@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AnonSessionCookie, giriş yapmamış kullanıcıların ürettiği müzikleri tarayıcıya bağlayan çerezin adıdır.
+const AnonSessionCookie = "anon_session"
+
+// GetAnonSessionID çerezdeki anonim oturum ID'sini döner.
+func GetAnonSessionID(c *gin.Context) (uuid.UUID, bool) {
+	value, err := c.Cookie(AnonSessionCookie)
+	if err != nil || value == "" {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(value)
+	if err != nil || id == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// GetOrCreateAnonSessionID mevcut anonim oturum ID'sini döner, yoksa yenisini oluşturup çereze yazar.
+// Her çağrıda çerezin süresi ttl kadar uzatılır.
+func GetOrCreateAnonSessionID(c *gin.Context, ttl time.Duration) uuid.UUID {
+	id, ok := GetAnonSessionID(c)
+	if !ok {
+		id = uuid.New()
+	}
+	c.SetCookie(AnonSessionCookie, id.String(), int(ttl.Seconds()), "/", "", false, true)
+	return id
+}
+
+// ClearAnonSession anonim oturum çerezini siler.
+func ClearAnonSession(c *gin.Context) {
+	c.SetCookie(AnonSessionCookie, "", -1, "/", "", false, true)
+}
@@ -59,10 +59,30 @@ func MigrateDB(db *gorm.DB) error {
 		return fmt.Errorf("failed to create uuid extension: %w", err)
 	}
 
-	err := db.AutoMigrate(&models.User{}, &models.MusicType{}, &models.ModelType{}, &models.Music{}, &models.UserLikesMusic{})
+	// E-posta doğrulaması sonradan eklendi; sütun ilk kez oluşturulurken mevcut hesaplar doğrulanmış sayılır.
+	backfillEmailVerified := db.Migrator().HasTable(&models.User{}) && !db.Migrator().HasColumn(&models.User{}, "EmailVerifiedAt")
+	// Yayınlanma zamanı da sonradan eklendi; zaten yayında olan parçalar için oluşturulma zamanı kullanılır.
+	backfillPublishedAt := db.Migrator().HasTable(&models.Music{}) && !db.Migrator().HasColumn(&models.Music{}, "PublishedAt")
+
+	err := db.AutoMigrate(&models.User{}, &models.MusicType{}, &models.ModelType{}, &models.Music{}, &models.UserLikesMusic{}, &models.MusicAsset{}, &models.Tag{}, &models.Session{}, &models.Generation{}, &models.AuditLog{}, &models.PasswordResetToken{}, &models.EmailVerificationToken{}, &models.RecoveryCode{}, &models.Identity{}, &models.PersonalAccessToken{}, &models.DataExport{}, &models.UserFollow{}, &models.Playlist{}, &models.PlaylistEntry{}, &models.Comment{})
 	if err != nil {
 		return fmt.Errorf("migration failed: %w", err)
 	}
+
+	if backfillEmailVerified {
+		result := db.Model(&models.User{}).Where("email_verified_at IS NULL").Update("email_verified_at", gorm.Expr("created_at"))
+		if result.Error != nil {
+			return fmt.Errorf("backfilling email_verified_at failed: %w", result.Error)
+		}
+		log.Printf("Marked %d existing user(s) as email-verified", result.RowsAffected)
+	}
+	if backfillPublishedAt {
+		result := db.Unscoped().Model(&models.Music{}).Where("is_public = ? AND published_at IS NULL", true).Update("published_at", gorm.Expr("created_at"))
+		if result.Error != nil {
+			return fmt.Errorf("backfilling published_at failed: %w", result.Error)
+		}
+		log.Printf("Set published_at for %d existing public track(s)", result.RowsAffected)
+	}
 	log.Println("Database migrations completed successfully")
 	return nil
 }
@@ -2,48 +2,193 @@ package utils
 
 import (
 	"errors"
-	"sync"
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 type Claims struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
 	jwt.RegisteredClaims
 }
 
-var revokedTokens = make(map[string]bool)
-var mu sync.Mutex
+var (
+	ErrSessionInactive     = errors.New("session has been revoked or expired")
+	ErrRefreshTokenInvalid = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused eski (daha önce döndürülmüş) bir refresh token kullanıldığında döner;
+	// bu durumda token ailesi, yani oturumun tamamı iptal edilmiş olur.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+// RefreshCookieName httpOnly refresh token çerezinin adıdır.
+const RefreshCookieName = "refresh_token"
+
+// RefreshResult başarılı bir refresh token döndürme işleminin sonucudur.
+// Rotated false ise token eşzamanlı bir istekte zaten döndürülmüştür; yeni refresh token yazılmaz.
+type RefreshResult struct {
+	UserID   uuid.UUID
+	Username string
+	Role     string
+	Rotated  bool
+}
+
+// SessionStore sunucu tarafındaki oturumları yönetir. Token'ın jti alanı oturum ID'sidir;
+// bir oturumun refresh token'ları aynı aileyi oluşturur.
+type SessionStore interface {
+	CreateSession(userID uuid.UUID, userAgent, ip, refreshHash string, expiresAt time.Time) (uuid.UUID, error)
+	// ValidateSession oturum aktif değilse ErrSessionInactive döner.
+	ValidateSession(sessionID, userID uuid.UUID, ip string) error
+	// RotateRefreshToken sunulan refresh token'ı yenisiyle değiştirir ve oturum süresini expiresAt'e uzatır.
+	RotateRefreshToken(sessionID uuid.UUID, presentedHash, newHash string, expiresAt time.Time) (*RefreshResult, error)
+	RevokeSession(sessionID uuid.UUID) error
+}
+
+var sessionStore SessionStore
+
+// Access token kısa ömürlüdür; oturum refresh token ile uzatılır.
+var (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// SetSessionStore uygulama açılışında bir kez çağrılır.
+func SetSessionStore(store SessionStore) {
+	sessionStore = store
+}
+
+// SetTokenLifetimes access ve refresh token sürelerini ayarlar; uygulama açılışında bir kez çağrılır.
+func SetTokenLifetimes(access, refresh time.Duration) {
+	if access > 0 {
+		accessTokenTTL = access
+	}
+	if refresh > 0 {
+		refreshTokenTTL = refresh
+	}
+}
+
+// GenerateToken yeni bir oturum açar; kısa ömürlü access token ile refresh token'ı çerezlere yazar.
+// Rol token'da taşınır ve her yenilemede veritabanından tekrar okunur.
+func GenerateToken(c *gin.Context, userID, username, role, secret string) error {
+	if sessionStore == nil {
+		return errors.New("session store is not configured")
+	}
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+	refreshSecret, refreshHash, err := newRefreshSecret()
+	if err != nil {
+		return err
+	}
+	sessionExpiresAt := time.Now().Add(refreshTokenTTL)
+	sessionID, err := sessionStore.CreateSession(uid, c.Request.UserAgent(), c.ClientIP(), refreshHash, sessionExpiresAt)
+	if err != nil {
+		return fmt.Errorf("creating session: %w", err)
+	}
+
+	if _, err := setAccessToken(c, sessionID.String(), userID, username, role, secret); err != nil {
+		return err
+	}
+	setRefreshCookie(c, sessionID, refreshSecret)
+	return nil
+}
 
-func GenerateToken(c *gin.Context, userID, username, secret string, expiry time.Duration) error {
-	claims := Claims{
+// setAccessToken verilen oturum için imzalı access token üretip çereze yazar.
+func setAccessToken(c *gin.Context, sessionID, userID, username, role, secret string) (*Claims, error) {
+	now := time.Now()
+	claims := &Claims{
 		UserID:   userID,
 		Username: username,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        sessionID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(accessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(secret))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	c.SetCookie("token", tokenString, int(expiry.Seconds()), "/", "", false, true)
-	return nil
+	c.SetCookie("token", tokenString, int(accessTokenTTL.Seconds()), "/", "", false, true)
+	// Aynı istekte sonraki okumalar (örn. logout) yeni token'ı görsün.
+	setRequestCookie(c, "token", tokenString)
+	return claims, nil
 }
 
-func VerifyToken(c *gin.Context, secret string) (*Claims, error) {
-	tokenString, err := c.Cookie("token")
+// Refresh token "<oturum ID>.<rastgele değer>" biçimindedir; veritabanında yalnızca SHA-256 özeti tutulur.
+func newRefreshSecret() (secret, hash string, err error) {
+	return NewOpaqueToken()
+}
+
+func hashRefreshSecret(secret string) string {
+	return HashOpaqueToken(secret)
+}
+
+func setRefreshCookie(c *gin.Context, sessionID uuid.UUID, secret string) {
+	value := sessionID.String() + "." + secret
+	c.SetCookie(RefreshCookieName, value, int(refreshTokenTTL.Seconds()), "/", "", false, true)
+	setRequestCookie(c, RefreshCookieName, value)
+}
+
+// parseRefreshCookie çerezdeki refresh token'ı oturum ID'si ve gizli değer olarak ayırır.
+func parseRefreshCookie(c *gin.Context) (uuid.UUID, string, error) {
+	value, err := c.Cookie(RefreshCookieName)
+	if err != nil || value == "" {
+		return uuid.Nil, "", ErrRefreshTokenInvalid
+	}
+	idPart, secret, ok := strings.Cut(value, ".")
+	if !ok || secret == "" {
+		return uuid.Nil, "", ErrRefreshTokenInvalid
+	}
+	sessionID, err := uuid.Parse(idPart)
 	if err != nil {
-		return nil, errors.New("missing token cookie")
+		return uuid.Nil, "", ErrRefreshTokenInvalid
+	}
+	return sessionID, secret, nil
+}
+
+// setRequestCookie isteğin Cookie başlığındaki değeri değiştirir.
+func setRequestCookie(c *gin.Context, name, value string) {
+	cookies := c.Request.Cookies()
+	c.Request.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		if cookie.Name != name {
+			c.Request.AddCookie(cookie)
+		}
+	}
+	if value != "" {
+		c.Request.AddCookie(&http.Cookie{Name: name, Value: value})
 	}
+}
+
+// ClearAuthCookies access ve refresh token çerezlerini siler.
+func ClearAuthCookies(c *gin.Context) {
+	c.SetCookie("token", "", -1, "/", "", false, true)
+	c.SetCookie(RefreshCookieName, "", -1, "/", "", false, true)
+	setRequestCookie(c, "token", "")
+	setRequestCookie(c, RefreshCookieName, "")
+}
+
+// parseToken imzayı ve süreyi doğrular, oturum kontrolü yapmaz.
+func parseToken(tokenString, secret string) (*Claims, error) {
+	return parseTokenWith(&jwt.Parser{}, tokenString, secret)
+}
 
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+func parseTokenWith(parser *jwt.Parser, tokenString, secret string) (*Claims, error) {
+	token, err := parser.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
 		return []byte(secret), nil
 	})
 	if err != nil {
@@ -53,57 +198,120 @@ func VerifyToken(c *gin.Context, secret string) (*Claims, error) {
 	if !ok || !token.Valid {
 		return nil, errors.New("invalid token")
 	}
+	return claims, nil
+}
 
-	mu.Lock()
-	defer mu.Unlock()
-	if revokedTokens[tokenString] {
-		return nil, errors.New("token has been revoked")
+// sessionIDs token içindeki oturum ve kullanıcı ID'lerini ayrıştırır.
+// jti içermeyen (oturum tablosundan önce verilmiş) token'lar geçersiz sayılır.
+func sessionIDs(claims *Claims) (sessionID, userID uuid.UUID, err error) {
+	sessionID, err = uuid.Parse(claims.ID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("token has no session id")
 	}
-	return claims, nil
+	userID, err = uuid.Parse(claims.UserID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("token has an invalid user id")
+	}
+	return sessionID, userID, nil
 }
 
-func RefreshToken(c *gin.Context, secret string, newExpiry time.Duration) error {
-	_, err := c.Cookie("token")
+func VerifyToken(c *gin.Context, secret string) (*Claims, error) {
+	tokenString, err := c.Cookie("token")
 	if err != nil {
-		return errors.New("missing token cookie")
+		return nil, errors.New("missing token cookie")
 	}
 
-	claims, err := VerifyToken(c, secret)
+	claims, err := parseToken(tokenString, secret)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	sessionID, userID, err := sessionIDs(claims)
+	if err != nil {
+		return nil, err
+	}
+	if sessionStore == nil {
+		return nil, errors.New("session store is not configured")
+	}
+	if err := sessionStore.ValidateSession(sessionID, userID, c.ClientIP()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
 
-	newClaims := Claims{
-		UserID:   claims.UserID,
-		Username: claims.Username,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(newExpiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+// Authenticate access token'ı doğrular; token yoksa veya süresi dolmuşsa refresh token ile
+// sessizce yeniler. Yenileme de başarısız olursa auth çerezleri silinir.
+func Authenticate(c *gin.Context, secret string) (*Claims, error) {
+	claims, err := VerifyToken(c, secret)
+	if err == nil {
+		return claims, nil
+	}
+	if _, cookieErr := c.Cookie(RefreshCookieName); cookieErr != nil {
+		return nil, err
+	}
+	claims, refreshErr := RefreshToken(c, secret)
+	if refreshErr != nil {
+		return nil, refreshErr
 	}
+	return claims, nil
+}
 
-	newToken := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims)
-	tokenString, err := newToken.SignedString([]byte(secret))
+// RefreshToken çerezdeki refresh token'ı döndürür (rotation) ve aynı oturum için yeni bir access token yazar.
+// Daha önce kullanılmış bir refresh token sunulursa oturum iptal edilir ve ErrRefreshTokenReused döner.
+func RefreshToken(c *gin.Context, secret string) (*Claims, error) {
+	if sessionStore == nil {
+		return nil, errors.New("session store is not configured")
+	}
+	sessionID, presented, err := parseRefreshCookie(c)
 	if err != nil {
-		return err
+		ClearAuthCookies(c)
+		return nil, err
+	}
+	newSecret, newHash, err := newRefreshSecret()
+	if err != nil {
+		return nil, err
 	}
 
-	c.SetCookie("token", tokenString, int(newExpiry.Seconds()), "/", "", false, true)
-	return nil
-}
+	result, err := sessionStore.RotateRefreshToken(sessionID, hashRefreshSecret(presented), newHash, time.Now().Add(refreshTokenTTL))
+	if err != nil {
+		ClearAuthCookies(c)
+		return nil, err
+	}
 
-func RevokeToken(c *gin.Context) error {
-	tokenString, err := c.Cookie("token")
+	claims, err := setAccessToken(c, sessionID.String(), result.UserID.String(), result.Username, result.Role, secret)
 	if err != nil {
-		return errors.New("missing token cookie")
+		return nil, err
+	}
+	if result.Rotated {
+		setRefreshCookie(c, sessionID, newSecret)
 	}
+	return claims, nil
+}
 
-	mu.Lock()
-	defer mu.Unlock()
-	revokedTokens[tokenString] = true
+// RevokeToken çerezlerdeki oturumu iptal eder ve auth çerezlerini siler.
+// Oturum ID'si süresi dolmuş access token'dan ya da refresh token'dan okunabilir; imza yine doğrulanır.
+func RevokeToken(c *gin.Context, secret string) error {
+	tokenString, tokenErr := c.Cookie("token")
+	refreshSessionID, _, refreshErr := parseRefreshCookie(c)
+	if tokenErr != nil && refreshErr != nil {
+		return errors.New("missing token cookie")
+	}
+	ClearAuthCookies(c)
+	if sessionStore == nil {
+		return nil
+	}
 
-	c.SetCookie("token", "", -1, "/", "", false, true)
-	return nil
+	sessionID := refreshSessionID
+	if tokenErr == nil {
+		if claims, err := parseTokenWith(&jwt.Parser{SkipClaimsValidation: true}, tokenString, secret); err == nil {
+			if id, _, err := sessionIDs(claims); err == nil {
+				sessionID = id
+			}
+		}
+	}
+	if sessionID == uuid.Nil {
+		return nil
+	}
+	return sessionStore.RevokeSession(sessionID)
 }
 
 func ExtractToken(c *gin.Context) string {
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"bytes"
+	"html/template"
+	"log"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var (
+	markdownRenderer = goldmark.New(goldmark.WithExtensions(extension.Linkify, extension.Strikethrough))
+	// Kullanıcı içeriği için izin verilen HTML; linklere rel="nofollow" eklenir.
+	ugcPolicy = bluemonday.UGCPolicy()
+)
+
+// RenderMarkdown kullanıcı tarafından yazılmış markdown'ı sanitize edilmiş HTML'e çevirir.
+// goldmark ham HTML'i zaten geçirmez; bluemonday ikinci bir savunma katmanıdır.
+func RenderMarkdown(src string) template.HTML {
+	if src == "" {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(src), &buf); err != nil {
+		log.Printf("Markdown render failed: %v", err)
+		return template.HTML(template.HTMLEscapeString(src))
+	}
+	return template.HTML(ugcPolicy.SanitizeBytes(buf.Bytes()))
+}
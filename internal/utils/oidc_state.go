@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// OIDCStateCookieName kimlik sağlayıcısına yönlendirilen tarayıcının dönüşte doğrulanacak değerlerini tutar.
+const OIDCStateCookieName = "oidc_state"
+
+const (
+	oidcStateAudience = "oidc-state"
+	oidcStateTTL      = 10 * time.Minute
+)
+
+var ErrOIDCStateInvalid = errors.New("oidc sign-in state is invalid or has expired")
+
+// OIDCLoginState bir OIDC girişinin başlangıcında üretilen tek kullanımlık değerlerdir:
+// State CSRF'e karşı, Nonce ID token'ın bu girişe ait olduğunu kanıtlamak için,
+// CodeVerifier ise PKCE için kullanılır.
+type OIDCLoginState struct {
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+type oidcStateClaims struct {
+	OIDCLoginState
+	jwt.RegisteredClaims
+}
+
+// NewOIDCLoginState rastgele state, nonce ve PKCE verifier üretir.
+func NewOIDCLoginState() (*OIDCLoginState, error) {
+	values := make([]string, 3)
+	for i := range values {
+		secret, _, err := NewOpaqueToken()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = secret
+	}
+	return &OIDCLoginState{State: values[0], Nonce: values[1], CodeVerifier: values[2]}, nil
+}
+
+// SetOIDCLoginState değerleri imzalı, kısa ömürlü bir çereze yazar. Çerez ayrı bir anahtarla imzalanır;
+// access token ya da 2FA ara token'ı yerine kullanılamaz.
+func SetOIDCLoginState(c *gin.Context, state *OIDCLoginState, secret string) error {
+	now := time.Now()
+	claims := &oidcStateClaims{
+		OIDCLoginState: *state,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{oidcStateAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(oidcStateTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(oidcStateKey(secret))
+	if err != nil {
+		return err
+	}
+	c.SetCookie(OIDCStateCookieName, token, int(oidcStateTTL.Seconds()), "/", "", false, true)
+	return nil
+}
+
+// ConsumeOIDCLoginState çerezdeki değerleri okuyup çerezi siler ve dönüşteki state parametresiyle karşılaştırır.
+// Çerez her durumda silinir; aynı state ikinci kez kullanılamaz.
+func ConsumeOIDCLoginState(c *gin.Context, secret, returnedState string) (*OIDCLoginState, error) {
+	cookie, err := c.Cookie(OIDCStateCookieName)
+	c.SetCookie(OIDCStateCookieName, "", -1, "/", "", false, true)
+	if err != nil || cookie == "" || returnedState == "" {
+		return nil, ErrOIDCStateInvalid
+	}
+	claims := &oidcStateClaims{}
+	parsed, err := jwt.ParseWithClaims(cookie, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrOIDCStateInvalid
+		}
+		return oidcStateKey(secret), nil
+	})
+	if err != nil || !parsed.Valid || !claims.VerifyAudience(oidcStateAudience, true) {
+		return nil, ErrOIDCStateInvalid
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.State), []byte(returnedState)) != 1 {
+		return nil, ErrOIDCStateInvalid
+	}
+	return &claims.OIDCLoginState, nil
+}
+
+func oidcStateKey(secret string) []byte {
+	return []byte(secret + ":" + oidcStateAudience)
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testStateSecret = "test-jwt-secret"
+
+// stateCookie SetOIDCLoginState'in yazdığı çerezi döner.
+func stateCookie(t *testing.T, state *OIDCLoginState, secret string) *http.Cookie {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/auth/oidc/login", nil)
+	if err := SetOIDCLoginState(c, state, secret); err != nil {
+		t.Fatalf("SetOIDCLoginState: %v", err)
+	}
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == OIDCStateCookieName {
+			return cookie
+		}
+	}
+	t.Fatal("state cookie was not set")
+	return nil
+}
+
+func consumeState(cookie *http.Cookie, secret, returnedState string) (*OIDCLoginState, *httptest.ResponseRecorder, error) {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/auth/oidc/callback", nil)
+	if cookie != nil {
+		c.Request.AddCookie(cookie)
+	}
+	state, err := ConsumeOIDCLoginState(c, secret, returnedState)
+	return state, rec, err
+}
+
+func TestOIDCLoginStateRoundTrip(t *testing.T) {
+	state, err := NewOIDCLoginState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.State == state.Nonce || state.Nonce == state.CodeVerifier {
+		t.Fatalf("state values are not independent: %+v", state)
+	}
+
+	got, rec, err := consumeState(stateCookie(t, state, testStateSecret), testStateSecret, state.State)
+	if err != nil {
+		t.Fatalf("ConsumeOIDCLoginState: %v", err)
+	}
+	if *got != *state {
+		t.Errorf("state = %+v, want %+v", got, state)
+	}
+	// Çerez her durumda silinir; aynı state tekrar kullanılamaz
+	cleared := false
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == OIDCStateCookieName && cookie.MaxAge < 0 {
+			cleared = true
+		}
+	}
+	if !cleared {
+		t.Error("state cookie was not cleared")
+	}
+}
+
+func TestOIDCLoginStateRejects(t *testing.T) {
+	state, err := NewOIDCLoginState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookie := stateCookie(t, state, testStateSecret)
+	tampered := *cookie
+	tampered.Value = cookie.Value[:len(cookie.Value)-2] + "xx"
+
+	cases := []struct {
+		name     string
+		cookie   *http.Cookie
+		secret   string
+		returned string
+	}{
+		{"state mismatch", cookie, testStateSecret, "attacker-state"},
+		{"empty returned state", cookie, testStateSecret, ""},
+		{"missing cookie", nil, testStateSecret, state.State},
+		{"cookie signed with another secret", cookie, "another-secret", state.State},
+		{"tampered cookie", &tampered, testStateSecret, state.State},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := consumeState(tc.cookie, tc.secret, tc.returned); !errors.Is(err, ErrOIDCStateInvalid) {
+				t.Fatalf("ConsumeOIDCLoginState error = %v, want ErrOIDCStateInvalid", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// NewOpaqueToken 256 bitlik rastgele bir gizli değer ve veritabanında saklanacak SHA-256 özetini üretir.
+// Gizli değer sadece kullanıcıya (çerez, e-posta linki) verilir.
+func NewOpaqueToken() (secret, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("generating token: %w", err)
+	}
+	secret = base64.RawURLEncoding.EncodeToString(buf)
+	return secret, HashOpaqueToken(secret), nil
+}
+
+// HashOpaqueToken gizli değerin hex kodlu SHA-256 özetini döner.
+func HashOpaqueToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
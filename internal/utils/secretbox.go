@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// SecretBox hassas değerleri (örn. TOTP secret'ı) veritabanında AES-256-GCM ile şifreli saklar.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox anahtarı SHA-256 ile 32 byte'a indirger; böylece herhangi uzunlukta bir config değeri kullanılabilir.
+func NewSecretBox(key string) (*SecretBox, error) {
+	if key == "" {
+		return nil, errors.New("secret box key is empty")
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal düz metni şifreleyip nonce ile birlikte base64 olarak döner.
+func (b *SecretBox) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generating nonce: %w", err)
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open Seal ile şifrelenmiş değeri çözer.
+func (b *SecretBox) Open(ciphertext string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("decoding sealed value: %w", err)
+	}
+	if len(data) < b.aead.NonceSize() {
+		return "", errors.New("sealed value is too short")
+	}
+	nonce, sealed := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("opening sealed value: %w", err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// TwoFactorCookieName şifre doğrulandıktan sonra ikinci adım beklenirken tutulan ara token'ın çerezidir.
+const TwoFactorCookieName = "twofa_challenge"
+
+const twoFactorAudience = "2fa-challenge"
+
+var ErrTwoFactorChallengeInvalid = errors.New("two-factor challenge is invalid or has expired")
+
+type twoFactorClaims struct {
+	UserID string `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+// IssueTwoFactorChallenge şifresi doğrulanmış ama henüz TOTP kodunu girmemiş kullanıcı için kısa ömürlü
+// bir ara token üretir ve çereze yazar. Token ayrı bir anahtarla imzalanır; access token yerine kullanılamaz.
+// API istemcileri için token'ın kendisi de döner.
+func IssueTwoFactorChallenge(c *gin.Context, userID uuid.UUID, secret string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &twoFactorClaims{
+		UserID: userID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Audience:  jwt.ClaimStrings{twoFactorAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(twoFactorKey(secret))
+	if err != nil {
+		return "", err
+	}
+	c.SetCookie(TwoFactorCookieName, token, int(ttl.Seconds()), "/", "", false, true)
+	return token, nil
+}
+
+// ParseTwoFactorChallenge verilen ara token'ı (boşsa çerezdekini) doğrular ve kullanıcı ID'sini döner.
+func ParseTwoFactorChallenge(c *gin.Context, secret, token string) (uuid.UUID, error) {
+	if token == "" {
+		cookie, err := c.Cookie(TwoFactorCookieName)
+		if err != nil || cookie == "" {
+			return uuid.Nil, ErrTwoFactorChallengeInvalid
+		}
+		token = cookie
+	}
+	claims := &twoFactorClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrTwoFactorChallengeInvalid
+		}
+		return twoFactorKey(secret), nil
+	})
+	if err != nil || !parsed.Valid || !claims.VerifyAudience(twoFactorAudience, true) {
+		return uuid.Nil, ErrTwoFactorChallengeInvalid
+	}
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return uuid.Nil, ErrTwoFactorChallengeInvalid
+	}
+	return userID, nil
+}
+
+// ClearTwoFactorChallenge ara token çerezini siler.
+func ClearTwoFactorChallenge(c *gin.Context) {
+	c.SetCookie(TwoFactorCookieName, "", -1, "/", "", false, true)
+}
+
+func twoFactorKey(secret string) []byte {
+	return []byte(secret + ":" + twoFactorAudience)
+}
@@ -0,0 +1,90 @@
+// Parçaların gerçek waveform'unu /api/v1/music/:id/waveform'dan gelen peak verisiyle çizer.
+// Kullanım: <canvas data-waveform-src="/api/v1/music/ID/waveform?resolution=256"></canvas>
+// Peak verisi yoksa (404) canvas gizlenir, çevresindeki arayüz olduğu gibi kalır.
+(function () {
+    const PLAYED_COLOR = '#F55B47';
+    const REMAINING_COLOR = '#d6d3d1';
+    const peakCache = new Map();
+
+    function fetchPeaks(src) {
+        if (!peakCache.has(src)) {
+            const request = fetch(src, { credentials: 'same-origin' })
+                .then(response => {
+                    if (!response.ok) {
+                        throw new Error(`waveform request failed with status ${response.status}`);
+                    }
+                    return response.json();
+                })
+                .then(data => data.peaks || []);
+            request.catch(() => peakCache.delete(src)); // Hatalı istekler bir sonraki denemede tekrar yapılabilsin
+            peakCache.set(src, request);
+        }
+        return peakCache.get(src);
+    }
+
+    function draw(canvas) {
+        const peaks = canvas._waveformPeaks;
+        if (!peaks || peaks.length === 0) return;
+
+        const ratio = window.devicePixelRatio || 1;
+        const width = canvas.clientWidth;
+        const height = canvas.clientHeight;
+        if (width === 0 || height === 0) return;
+        canvas.width = width * ratio;
+        canvas.height = height * ratio;
+
+        const ctx = canvas.getContext('2d');
+        ctx.setTransform(ratio, 0, 0, ratio, 0, 0);
+        ctx.clearRect(0, 0, width, height);
+
+        const progress = canvas._waveformProgress || 0;
+        const barWidth = width / peaks.length;
+        const gap = barWidth > 3 ? 1 : 0;
+        const mid = height / 2;
+        for (let i = 0; i < peaks.length; i++) {
+            const barHeight = Math.max(1, peaks[i] * height);
+            const x = i * barWidth;
+            ctx.fillStyle = (i + 0.5) / peaks.length <= progress ? PLAYED_COLOR : REMAINING_COLOR;
+            ctx.fillRect(x, mid - barHeight / 2, Math.max(1, barWidth - gap), barHeight);
+        }
+    }
+
+    function load(canvas) {
+        const src = canvas.dataset.waveformSrc;
+        if (!src || canvas._waveformLoading) return;
+        canvas._waveformLoading = true;
+        fetchPeaks(src)
+            .then(peaks => {
+                canvas._waveformPeaks = peaks;
+                canvas.classList.remove('hidden');
+                draw(canvas);
+            })
+            .catch(() => {
+                canvas.classList.add('hidden');
+                canvas._waveformLoading = false;
+            });
+    }
+
+    function setProgress(canvas, progress) {
+        if (!canvas) return;
+        canvas._waveformProgress = Math.max(0, Math.min(1, progress));
+        draw(canvas);
+    }
+
+    function init(root) {
+        (root || document).querySelectorAll('canvas[data-waveform-src]').forEach(load);
+    }
+
+    let resizeTimer;
+    window.addEventListener('resize', function () {
+        clearTimeout(resizeTimer);
+        resizeTimer = setTimeout(function () {
+            document.querySelectorAll('canvas[data-waveform-src]').forEach(draw);
+        }, 150);
+    });
+
+    document.addEventListener('DOMContentLoaded', function () { init(document); });
+    document.addEventListener('htmx:afterSwap', function (event) { init(event.detail.target); });
+
+    window.Waveform = { init, load, setProgress };
+})();